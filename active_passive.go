@@ -0,0 +1,147 @@
+package dcpkafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/couchbase/gocbcore/v10"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/ha"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// activePassive holds Start until the replica leads. Losing the leadership closes the connector and Start returns,
+// the process should exit to rejoin as a standby since go-dcp cannot reopen a closed stream.
+type activePassive struct {
+	ctx       context.Context
+	elector   ha.Elector
+	agent     *gocbcore.Agent
+	cancel    context.CancelFunc
+	leading   chan struct{}
+	done      chan struct{}
+	lock      sync.Mutex
+	started   bool
+	led       bool
+	streaming bool
+}
+
+func newActivePassive(cc *config.Connector) (*activePassive, error) {
+	activePassiveConfig := &cc.Kafka.ActivePassive
+	options := ha.Options{
+		Identity:      activePassiveConfig.Identity,
+		LeaseDuration: activePassiveConfig.LeaseDuration,
+		RetryPeriod:   activePassiveConfig.RetryPeriod,
+	}
+	if options.Identity == "" {
+		options.Identity = ha.DefaultIdentity()
+	}
+
+	a := &activePassive{
+		leading: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+
+	switch activePassiveConfig.Type {
+	case ha.ElectorTypeKubernetes:
+		elector, err := ha.NewKubernetesElector(
+			activePassiveConfig.Config["leaseLockName"], activePassiveConfig.Config["leaseLockNamespace"], options,
+		)
+		if err != nil {
+			return nil, err
+		}
+		a.elector = elector
+	case ha.ElectorTypeCouchbase:
+		metadata := cc.Dcp.GetCouchbaseMetadata()
+		agent, err := dcpCouchbase.CreateAgent(
+			cc.Dcp.Hosts, metadata.Bucket, cc.Dcp.Username, cc.Dcp.Password, cc.Dcp.SecureConnection, cc.Dcp.RootCAPath,
+			metadata.ConnectionBufferSize, metadata.ConnectionTimeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+		a.agent = agent
+		a.elector = ha.NewCouchbaseElector(
+			agent, metadata.Scope, metadata.Collection, helpers.Prefix+cc.Dcp.Dcp.Group.Name+":leader", options,
+		)
+	}
+	return a, nil
+}
+
+// start runs the election, onLost is called when the leadership is lost while streaming, not on close.
+func (a *activePassive) start(onLost func()) {
+	a.lock.Lock()
+	a.started = true
+	a.lock.Unlock()
+
+	go func() {
+		defer close(a.done)
+		a.elector.Run(a.ctx, ha.Callbacks{
+			OnStartedLeading: func() {
+				a.lock.Lock()
+				a.led = true
+				a.lock.Unlock()
+				close(a.leading)
+			},
+			OnStoppedLeading: func() {
+				a.lock.Lock()
+				led := a.led
+				a.lock.Unlock()
+				if led && a.ctx.Err() == nil {
+					logger.Log.Error("lost the leadership, closing the connector")
+					onLost()
+				}
+			},
+		})
+	}()
+}
+
+// waitForLeadership returns false when the connector is closed before leading.
+func (a *activePassive) waitForLeadership() bool {
+	logger.Log.Info("waiting for the leadership as standby")
+
+	select {
+	case <-a.leading:
+	case <-a.ctx.Done():
+		return false
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.ctx.Err() != nil {
+		return false
+	}
+	a.streaming = true
+	return true
+}
+
+// standby stops the election and returns true when the connector has not started streaming, the leader keeps the
+// lease until it is drained.
+func (a *activePassive) standby() bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.streaming {
+		return false
+	}
+	a.cancel()
+	return true
+}
+
+func (a *activePassive) close() {
+	a.lock.Lock()
+	started := a.started
+	a.lock.Unlock()
+
+	a.cancel()
+	if started {
+		<-a.done
+	}
+	if a.agent != nil {
+		_ = a.agent.Close()
+	}
+}
@@ -0,0 +1,211 @@
+package dcpkafka
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Trendyol/go-dcp-kafka/api"
+	"github.com/Trendyol/go-dcp-kafka/pause"
+)
+
+func (c *connector) registerAdminRoutes() {
+	c.api.Handle("/migration", c.migrationHandler)
+	c.api.Handle("/rate-limit", c.rateLimitHandler)
+	c.api.Handle("/pause", c.pauseHandler)
+	c.api.Handle("/resume", c.resumeHandler)
+	c.api.Handle("/startup-report", c.startupReportHandler)
+	c.api.Handle("/membership", c.membershipHandler)
+	c.api.Handle("/collections", c.collectionsHandler)
+	c.api.Handle("/replay", c.replayHandler)
+}
+
+func (c *connector) migrationHandler(w http.ResponseWriter, r *http.Request) {
+	migration := c.producer.GetMigration()
+	if migration == nil {
+		api.WriteError(w, http.StatusNotFound, errors.New("migration mode is not enabled"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		percentage, err := strconv.Atoi(r.URL.Query().Get("percentage"))
+		if err != nil {
+			api.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := migration.SetPercentage(percentage); err != nil {
+			api.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]int{"percentage": migration.GetPercentage()})
+}
+
+func (c *connector) rateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	rateLimiter := c.producer.GetRateLimiter()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		messagesPerSecond, bytesPerSecond := rateLimiter.GetLimits()
+		query := r.URL.Query()
+
+		var err error
+		if value := query.Get("messagesPerSecond"); value != "" {
+			if messagesPerSecond, err = strconv.Atoi(value); err != nil {
+				api.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		if value := query.Get("bytesPerSecond"); value != "" {
+			if bytesPerSecond, err = strconv.Atoi(value); err != nil {
+				api.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		if err = rateLimiter.SetLimits(messagesPerSecond, bytesPerSecond); err != nil {
+			api.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	messagesPerSecond, bytesPerSecond := rateLimiter.GetLimits()
+	api.WriteJSON(w, http.StatusOK, map[string]int{
+		"messagesPerSecond": messagesPerSecond,
+		"bytesPerSecond":    bytesPerSecond,
+	})
+}
+
+func (c *connector) pauseHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		s, err := parsePauseState(r)
+		if err != nil {
+			api.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err = c.pauser.Pause(s); err != nil {
+			api.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	c.writePauseState(w)
+}
+
+func (c *connector) resumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s, err := parsePauseState(r)
+	if err != nil {
+		api.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = c.pauser.Resume(s); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.writePauseState(w)
+}
+
+func (c *connector) membershipHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, c.Membership())
+}
+
+func (c *connector) collectionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		query := r.URL.Query()
+		if err := c.collections.Disable(splitQuery(query.Get("disable"))); err != nil {
+			api.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := c.collections.Enable(splitQuery(query.Get("enable"))); err != nil {
+			api.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, c.collections.State())
+}
+
+func (c *connector) replayHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		ranges, err := ParseReplayRanges(r.URL.Query().Get("ranges"))
+		if err != nil {
+			api.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := c.replays.start(c.replayBuilder, ranges); errors.Is(err, errReplayRunning) {
+			api.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		api.WriteJSON(w, http.StatusAccepted, c.replays.state())
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, c.replays.state())
+}
+
+func (c *connector) writePauseState(w http.ResponseWriter) {
+	api.WriteJSON(w, http.StatusOK, map[string]any{
+		"state": c.pauser.State(),
+		"held":  c.pauser.HeldCounts(),
+	})
+}
+
+func parsePauseState(r *http.Request) (pause.State, error) {
+	var s pause.State
+	query := r.URL.Query()
+
+	for _, value := range splitQuery(query.Get("vbIds")) {
+		vbID, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return s, err
+		}
+		s.VbIDs = append(s.VbIDs, uint16(vbID))
+	}
+	s.KeyPrefixes = splitQuery(query.Get("keyPrefixes"))
+
+	return s, nil
+}
+
+func splitQuery(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
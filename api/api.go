@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type API interface {
+	Listen()
+	Shutdown()
+	Handle(pattern string, handler http.HandlerFunc)
+}
+
+type api struct {
+	mux    *http.ServeMux
+	server *http.Server
+	port   int
+}
+
+func (s *api) Listen() {
+	logger.Log.Info("admin api starting on port %d", s.port)
+
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Log.Error("admin api cannot start on port %d, err: %v", s.port, err)
+	} else {
+		logger.Log.Info("admin api stopped")
+	}
+}
+
+func (s *api) Shutdown() {
+	err := s.server.Shutdown(context.Background())
+	if err != nil {
+		logger.Log.Error("admin api cannot be shutdown, err: %v", err)
+	}
+}
+
+func (s *api) Handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+func NewAPI(port int) API {
+	mux := http.NewServeMux()
+
+	return &api{
+		mux:  mux,
+		port: port,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
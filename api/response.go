@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func WriteJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := jsoniter.NewEncoder(w).Encode(body); err != nil {
+		logger.Log.Error("admin api cannot write response, err: %v", err)
+	}
+}
+
+func WriteError(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
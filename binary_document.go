@@ -0,0 +1,49 @@
+package dcpkafka
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp/models"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderDatatype = "cb.datatype"
+	HeaderFlags    = "cb.flags"
+)
+
+// applyBinaryDocument returns the datatype headers of the document and whether it is a JSON document.
+// Non JSON documents are wrapped into a JSON envelope when configured.
+func (c *connector) applyBinaryDocument(e *couchbase.Event) ([]sKafka.Header, bool) {
+	kind := document.Kind(e.Value, e.Datatype, e.Flags)
+
+	if kind != document.KindJSON && c.config.Kafka.BinaryDocuments.Encoding == config.BinaryEncodingBase64Envelope {
+		wrapped, err := document.Envelope(kind, e.Value)
+		if err != nil {
+			logging.Error(append(eventFields(e), logging.Err(err)), "cannot wrap %s document", kind)
+		} else {
+			e.Value = wrapped
+		}
+	}
+
+	return []sKafka.Header{
+		{Key: HeaderDatatype, Value: []byte(kind)},
+		{Key: HeaderFlags, Value: []byte(strconv.FormatUint(uint64(e.Flags), 10))},
+	}, kind == document.KindJSON
+}
+
+// skipBinaryDocument acks the non JSON documents when kafka.binaryDocuments.encoding is skip and returns true for them.
+func (c *connector) skipBinaryDocument(ctx *models.ListenerContext, isJSON bool) bool {
+	if isJSON || c.config.Kafka.BinaryDocuments.Encoding != config.BinaryEncodingSkip {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().SkippedBinaryDocuments, 1)
+	ctx.Ack()
+	return true
+}
@@ -0,0 +1,161 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+type VBucket struct {
+	Checkpoint *models.CheckpointDocumentCheckpoint `json:"checkpoint"`
+	VbID       uint16                               `json:"vbId"`
+}
+
+// Snapshot is the exported checkpoint of every vBucket of the bucket, not only the ones of one member.
+type Snapshot struct {
+	ExportedAt time.Time `json:"exportedAt"`
+	GroupName  string    `json:"groupName"`
+	BucketUUID string    `json:"bucketUuid"`
+	VBuckets   []VBucket `json:"vBuckets"`
+}
+
+// Export reads the checkpoints of the metadata. A nil checkpointMetadata means the Couchbase or file metadata
+// of the dcp config is used.
+func Export(dcpConfig *config.Dcp, checkpointMetadata metadata.Metadata) (*Snapshot, error) {
+	store, err := open(dcpConfig, checkpointMetadata)
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+
+	checkpoints, _, err := store.metadata.Load(store.vbIDs, store.bucketUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		ExportedAt: time.Now(),
+		GroupName:  dcpConfig.Dcp.Group.Name,
+		BucketUUID: store.bucketUUID,
+		VBuckets:   make([]VBucket, 0, len(store.vbIDs)),
+	}
+
+	for _, vbID := range store.vbIDs {
+		vBucket := VBucket{VbID: vbID}
+		if doc, ok := checkpoints.Load(vbID); ok && doc != nil {
+			vBucket.Checkpoint = doc.Checkpoint
+		}
+		if vBucket.Checkpoint == nil {
+			vBucket.Checkpoint = models.NewEmptyCheckpointDocument(store.bucketUUID).Checkpoint
+		}
+		snapshot.VBuckets = append(snapshot.VBuckets, vBucket)
+	}
+
+	return snapshot, nil
+}
+
+// Import saves the checkpoints of the snapshot, vBuckets missing from it keep their checkpoint. The snapshot may come
+// from another bucket, the checkpoints are saved with the bucket uuid of the dcp config. The connector must be stopped,
+// a running member overrides the imported checkpoints with its own.
+func Import(dcpConfig *config.Dcp, checkpointMetadata metadata.Metadata, snapshot *Snapshot) error {
+	if dcpConfig.Metadata.ReadOnly {
+		return errors.New("metadata is read only")
+	}
+
+	store, err := open(dcpConfig, checkpointMetadata)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	return store.save(snapshot.VBuckets)
+}
+
+type store struct {
+	client     dcpCouchbase.Client
+	metadata   metadata.Metadata
+	bucketUUID string
+	vbIDs      []uint16
+}
+
+// save keeps the checkpoints of the vBuckets missing from vBuckets, the file metadata writes every vBucket.
+func (s *store) save(vBuckets []VBucket) error {
+	checkpoints, _, err := s.metadata.Load(s.vbIDs, s.bucketUUID)
+	if err != nil {
+		return err
+	}
+
+	state := make(map[uint16]*models.CheckpointDocument, len(s.vbIDs))
+	for _, vbID := range s.vbIDs {
+		if doc, ok := checkpoints.Load(vbID); ok && doc != nil {
+			state[vbID] = doc
+		} else {
+			state[vbID] = models.NewEmptyCheckpointDocument(s.bucketUUID)
+		}
+	}
+
+	dirty := make(map[uint16]bool, len(vBuckets))
+	for _, vBucket := range vBuckets {
+		if int(vBucket.VbID) >= len(s.vbIDs) {
+			return fmt.Errorf("vbID %d is out of range, the bucket has %d vBuckets", vBucket.VbID, len(s.vbIDs))
+		}
+		if vBucket.Checkpoint == nil {
+			return fmt.Errorf("checkpoint of vbID %d is missing", vBucket.VbID)
+		}
+
+		state[vBucket.VbID] = &models.CheckpointDocument{Checkpoint: vBucket.Checkpoint, BucketUUID: s.bucketUUID}
+		dirty[vBucket.VbID] = true
+	}
+
+	return s.metadata.Save(state, dirty, s.bucketUUID)
+}
+
+// open connects to the bucket for its vBucket count and uuid, no stream is opened.
+func open(dcpConfig *config.Dcp, checkpointMetadata metadata.Metadata) (*store, error) {
+	client := dcpCouchbase.NewClient(dcpConfig)
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := client.DcpConnect(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	snapshot, err := client.GetConfigSnapshot()
+	if err != nil {
+		client.DcpClose()
+		client.Close()
+		return nil, err
+	}
+
+	vbIDs := make([]uint16, 0, client.GetNumVBuckets())
+	for vbID := 0; vbID < client.GetNumVBuckets(); vbID++ {
+		vbIDs = append(vbIDs, uint16(vbID))
+	}
+
+	if checkpointMetadata == nil {
+		if dcpConfig.IsCouchbaseMetadata() {
+			checkpointMetadata = dcpCouchbase.NewCBMetadata(client, dcpConfig)
+		} else {
+			checkpointMetadata = metadata.NewFSMetadata(dcpConfig)
+		}
+	}
+
+	return &store{
+		client:     client,
+		metadata:   checkpointMetadata,
+		bucketUUID: snapshot.BucketUUID(),
+		vbIDs:      vbIDs,
+	}, nil
+}
+
+func (s *store) close() {
+	s.client.DcpClose()
+	s.client.Close()
+}
@@ -0,0 +1,66 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// At returns the checkpoint to resume a vBucket after seqNo. It carries the vbuuid of the failover log entry the
+// seqno belongs to, a checkpoint without it would make the server roll the stream back to 0.
+func At(client dcpCouchbase.Client, vbID uint16, seqNo uint64) (*models.CheckpointDocumentCheckpoint, error) {
+	checkpoint := &models.CheckpointDocumentCheckpoint{
+		Snapshot: &models.CheckpointDocumentSnapshot{StartSeqNo: seqNo, EndSeqNo: seqNo},
+		SeqNo:    seqNo,
+	}
+	if seqNo == 0 {
+		return checkpoint, nil
+	}
+
+	entries, err := client.GetFailoverLogs(vbID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the newest entry comes first
+	for _, entry := range entries {
+		if uint64(entry.SeqNo) <= seqNo {
+			checkpoint.VbUUID = uint64(entry.VbUUID)
+			return checkpoint, nil
+		}
+	}
+	return nil, fmt.Errorf("seqno %d of vbID %d is older than the failover log", seqNo, vbID)
+}
+
+// Seek saves checkpoints resuming the vBuckets of seqNos after the given seqnos, other vBuckets keep their checkpoint.
+// The connector must be stopped, as for Import.
+func Seek(dcpConfig *config.Dcp, checkpointMetadata metadata.Metadata, seqNos map[uint16]uint64) error {
+	if dcpConfig.Metadata.ReadOnly {
+		return errors.New("metadata is read only")
+	}
+
+	store, err := open(dcpConfig, checkpointMetadata)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	vBuckets := make([]VBucket, 0, len(seqNos))
+	for vbID, seqNo := range seqNos {
+		if int(vbID) >= len(store.vbIDs) {
+			return fmt.Errorf("vbID %d is out of range, the bucket has %d vBuckets", vbID, len(store.vbIDs))
+		}
+
+		checkpoint, err := At(store.client, vbID, seqNo)
+		if err != nil {
+			return err
+		}
+		vBuckets = append(vBuckets, VBucket{VbID: vbID, Checkpoint: checkpoint})
+	}
+
+	return store.save(vBuckets)
+}
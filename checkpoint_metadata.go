@@ -0,0 +1,80 @@
+package dcpkafka
+
+import (
+	"github.com/Trendyol/go-dcp"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	dcpMetadata "github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/kafka/metadata"
+)
+
+// setCheckpointMetadata wraps the checkpoint metadata for kafka.startFrom and kafka.vBuckets. The wrappers get their
+// own Couchbase client for the seqnos and failover logs, go-dcp does not expose its client.
+func (c *connector) setCheckpointMetadata(
+	cc *config.Connector,
+	conf *dcpConfig.Dcp,
+	kafkaClient kafka.Client,
+	dcp dcp.Dcp,
+) error {
+	vBuckets := cc.Kafka.GetVBuckets()
+	if !cc.Kafka.StartFrom.IsSet() && vBuckets == nil {
+		if conf.Metadata.Type == MetadataTypeKafka {
+			setKafkaMetadata(kafkaClient, conf, dcp)
+		}
+		return nil
+	}
+
+	client := dcpCouchbase.NewClient(conf)
+	if err := client.Connect(); err != nil {
+		return err
+	}
+	if err := client.DcpConnect(); err != nil {
+		client.Close()
+		return err
+	}
+	c.metadataClient = client
+
+	var checkpointMetadata dcpMetadata.Metadata
+	switch {
+	case conf.Metadata.Type == MetadataTypeKafka:
+		checkpointMetadata = metadata.NewKafkaMetadata(kafkaClient, conf.Metadata.Config)
+	case conf.IsCouchbaseMetadata():
+		checkpointMetadata = dcpCouchbase.NewCBMetadata(client, conf)
+	default:
+		checkpointMetadata = dcpMetadata.NewFSMetadata(conf)
+	}
+
+	if cc.Kafka.StartFrom.IsSet() {
+		c.startFrom = &startFromMetadata{
+			Metadata:  checkpointMetadata,
+			client:    client,
+			timestamp: cc.Kafka.StartFrom.Timestamp,
+			seqNos:    cc.Kafka.StartFrom.SeqNos,
+		}
+		checkpointMetadata = c.startFrom
+	}
+
+	if vBuckets != nil {
+		c.vBuckets = vBuckets
+		checkpointMetadata = &vBucketSubsetMetadata{
+			Metadata:  checkpointMetadata,
+			client:    client,
+			vBuckets:  vBuckets,
+			originals: map[uint16]*models.CheckpointDocument{},
+		}
+	}
+
+	dcp.SetMetadata(checkpointMetadata)
+	return nil
+}
+
+func (c *connector) closeMetadataClient() {
+	if c.metadataClient != nil {
+		c.metadataClient.DcpClose()
+		c.metadataClient.Close()
+	}
+}
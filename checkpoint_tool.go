@@ -0,0 +1,60 @@
+package dcpkafka
+
+import (
+	"github.com/Trendyol/go-dcp-kafka/checkpoint"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/kafka/metadata"
+	dcpMetadata "github.com/Trendyol/go-dcp/metadata"
+)
+
+// ExportCheckpoints returns the checkpoint of every vBucket from the metadata of the config.
+func ExportCheckpoints(c *config.Connector) (*checkpoint.Snapshot, error) {
+	c = withDcpDefaults(c)
+	checkpointMetadata, err := newCheckpointMetadata(c)
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint.Export(&c.Dcp, checkpointMetadata)
+}
+
+// ImportCheckpoints saves the checkpoints of the snapshot to the metadata of the config, e.g. to replay from an
+// earlier export or to move a connector between environments. The connector must be stopped.
+func ImportCheckpoints(c *config.Connector, snapshot *checkpoint.Snapshot) error {
+	c = withDcpDefaults(c)
+	checkpointMetadata, err := newCheckpointMetadata(c)
+	if err != nil {
+		return err
+	}
+	return checkpoint.Import(&c.Dcp, checkpointMetadata, snapshot)
+}
+
+// SeekCheckpoints saves checkpoints resuming the vBuckets of seqNos after the given seqnos. The connector must be stopped.
+func SeekCheckpoints(c *config.Connector, seqNos map[uint16]uint64) error {
+	c = withDcpDefaults(c)
+	checkpointMetadata, err := newCheckpointMetadata(c)
+	if err != nil {
+		return err
+	}
+	return checkpoint.Seek(&c.Dcp, checkpointMetadata, seqNos)
+}
+
+// newCheckpointMetadata returns nil for the Couchbase and file metadata, they are created from the dcp config.
+func newCheckpointMetadata(c *config.Connector) (dcpMetadata.Metadata, error) {
+	if c.Dcp.Metadata.Type != MetadataTypeKafka {
+		return nil, nil
+	}
+
+	secretProvider, err := newSecretProvider(&c.Kafka.Secrets, nil)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewKafkaMetadata(kafka.NewClient(c, secretProvider), c.Dcp.Metadata.Config), nil
+}
+
+// withDcpDefaults applies the dcp defaults to a copy, the config may belong to a running connector.
+func withDcpDefaults(c *config.Connector) *config.Connector {
+	cc := *c
+	cc.Dcp.ApplyDefaults()
+	return &cc
+}
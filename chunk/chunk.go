@@ -0,0 +1,210 @@
+package chunk
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	IDHeader    = "dcp-kafka-chunk-id"
+	IndexHeader = "dcp-kafka-chunk-index"
+	CountHeader = "dcp-kafka-chunk-count"
+)
+
+var ErrInvalidChunk = errors.New("invalid chunk headers")
+
+// Split splits the value into messages of at most maxSize bytes. Every chunk keeps the key, so all chunks land
+// on the same partition in order, and the headers of the original message next to the chunk headers.
+// Values that fit are returned as is.
+func Split(message kafka.Message, maxSize int) ([]kafka.Message, error) {
+	if len(message.Value) <= maxSize {
+		return []kafka.Message{message}, nil
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	count := (len(message.Value) + maxSize - 1) / maxSize
+	chunks := make([]kafka.Message, 0, count)
+
+	for index := 0; index < count; index++ {
+		end := (index + 1) * maxSize
+		if end > len(message.Value) {
+			end = len(message.Value)
+		}
+
+		headers := make([]kafka.Header, 0, len(message.Headers)+3)
+		headers = append(headers, message.Headers...)
+		headers = append(headers,
+			kafka.Header{Key: IDHeader, Value: id},
+			kafka.Header{Key: IndexHeader, Value: []byte(strconv.Itoa(index))},
+			kafka.Header{Key: CountHeader, Value: []byte(strconv.Itoa(count))},
+		)
+
+		chunks = append(chunks, kafka.Message{
+			Topic:   message.Topic,
+			Key:     message.Key,
+			Value:   message.Value[index*maxSize : end],
+			Headers: headers,
+			Time:    message.Time,
+		})
+	}
+
+	return chunks, nil
+}
+
+func newID() ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(id)), nil
+}
+
+type pending struct {
+	added    time.Time
+	element  *list.Element
+	chunks   [][]byte
+	received int
+}
+
+// Assembler reassembles chunked messages on the consumer side. It is safe for concurrent use.
+// Incomplete chunk groups are kept in memory until their last chunk arrives, at most maxPending groups for at most
+// ttl. The oldest groups over the limits are dropped when a new group is added, zero disables a limit.
+type Assembler struct {
+	pending    map[string]*pending
+	order      *list.List
+	ttl        time.Duration
+	maxPending int
+	dropped    int
+	lock       sync.Mutex
+}
+
+func NewAssembler(maxPending int, ttl time.Duration) *Assembler {
+	return &Assembler{
+		pending:    map[string]*pending{},
+		order:      list.New(),
+		ttl:        ttl,
+		maxPending: maxPending,
+	}
+}
+
+// Add returns the reassembled value and true once all chunks of a group are added.
+// Messages without chunk headers are returned as is.
+func (a *Assembler) Add(message kafka.Message) ([]byte, bool, error) {
+	id, index, count, ok, err := chunkHeaders(message.Headers)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return message.Value, true, nil
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	p, exists := a.pending[id]
+	if !exists {
+		p = &pending{added: time.Now(), chunks: make([][]byte, count)}
+		p.element = a.order.PushBack(id)
+		a.pending[id] = p
+		a.evict(p.added)
+	}
+	if len(p.chunks) != count || index >= count {
+		return nil, false, ErrInvalidChunk
+	}
+
+	if p.chunks[index] == nil {
+		p.chunks[index] = message.Value
+		p.received++
+	}
+
+	if p.received < count {
+		return nil, false, nil
+	}
+
+	a.remove(id, p)
+
+	var size int
+	for _, c := range p.chunks {
+		size += len(c)
+	}
+	value := make([]byte, 0, size)
+	for _, c := range p.chunks {
+		value = append(value, c...)
+	}
+
+	return value, true, nil
+}
+
+// Pending returns the number of incomplete chunk groups.
+func (a *Assembler) Pending() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return len(a.pending)
+}
+
+// Dropped returns the number of incomplete chunk groups dropped over the limits.
+func (a *Assembler) Dropped() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.dropped
+}
+
+func (a *Assembler) evict(now time.Time) {
+	for front := a.order.Front(); front != nil; front = a.order.Front() {
+		id := front.Value.(string)
+		p := a.pending[id]
+		expired := a.ttl > 0 && now.Sub(p.added) > a.ttl
+		if !expired && (a.maxPending == 0 || len(a.pending) <= a.maxPending) {
+			return
+		}
+		a.remove(id, p)
+		a.dropped++
+	}
+}
+
+func (a *Assembler) remove(id string, p *pending) {
+	delete(a.pending, id)
+	a.order.Remove(p.element)
+}
+
+func chunkHeaders(headers []kafka.Header) (id string, index int, count int, ok bool, err error) {
+	var found int
+	for _, header := range headers {
+		switch header.Key {
+		case IDHeader:
+			id = string(header.Value)
+			found++
+		case IndexHeader:
+			if index, err = strconv.Atoi(string(header.Value)); err != nil {
+				return "", 0, 0, false, ErrInvalidChunk
+			}
+			found++
+		case CountHeader:
+			if count, err = strconv.Atoi(string(header.Value)); err != nil {
+				return "", 0, 0, false, ErrInvalidChunk
+			}
+			found++
+		}
+	}
+
+	if found == 0 {
+		return "", 0, 0, false, nil
+	}
+	if found != 3 || count <= 0 || index < 0 {
+		return "", 0, 0, false, ErrInvalidChunk
+	}
+	return id, index, count, true, nil
+}
@@ -0,0 +1,28 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/chunk"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+// splitChunks splits values bigger than the chunk size, so a single oversized document does not fail the whole batch.
+func (c *connector) splitChunks(messages []sKafka.Message) ([]sKafka.Message, error) {
+	maxSize := c.config.Kafka.Chunking.MaxSize
+	chunked := make([]sKafka.Message, 0, len(messages))
+
+	for _, message := range messages {
+		chunks, err := chunk.Split(message, maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("chunking error: %w", err)
+		}
+		if len(chunks) > 1 {
+			atomic.AddInt64(&c.producer.GetMetric().ChunkedMessages, 1)
+		}
+		chunked = append(chunked, chunks...)
+	}
+
+	return chunked, nil
+}
@@ -0,0 +1,35 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/claimcheck"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+func newClaimCheck(claimCheckConfig *config.ClaimCheck, store claimcheck.Store) (*claimcheck.ClaimCheck, error) {
+	if store == nil {
+		switch claimCheckConfig.Type {
+		case claimcheck.StoreTypeFile:
+			store = claimcheck.NewFileStore(claimCheckConfig.Directory)
+		default:
+			return nil, fmt.Errorf("invalid claim check store type: %s", claimCheckConfig.Type)
+		}
+	}
+
+	return claimcheck.NewClaimCheck(store, claimCheckConfig.Threshold, claimCheckConfig.Timeout), nil
+}
+
+func (c *connector) applyClaimCheck(messages []sKafka.Message) {
+	for i := range messages {
+		checked, err := c.claimCheck.Apply(&messages[i])
+		if err != nil {
+			panic(fmt.Errorf("claim check error, key: %s, err: %w", messages[i].Key, err))
+		}
+		if checked {
+			atomic.AddInt64(&c.producer.GetMetric().ClaimCheckedMessages, 1)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package claimcheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+)
+
+const LocationHeader = "dcp-kafka-claim-check-location"
+
+// Reference is the value of the message produced instead of the payload.
+type Reference struct {
+	Location string `json:"location"`
+	SHA256   string `json:"sha256"`
+	Size     int    `json:"size"`
+}
+
+type ClaimCheck struct {
+	store     Store
+	threshold int
+	timeout   time.Duration
+}
+
+func NewClaimCheck(store Store, threshold int, timeout time.Duration) *ClaimCheck {
+	return &ClaimCheck{store: store, threshold: threshold, timeout: timeout}
+}
+
+// Apply uploads values bigger than the threshold and replaces them with a Reference and a location header.
+// It returns false for values that are kept as is.
+func (c *ClaimCheck) Apply(message *kafka.Message) (bool, error) {
+	if len(message.Value) <= c.threshold {
+		return false, nil
+	}
+
+	sum := sha256.Sum256(message.Value)
+	digest := hex.EncodeToString(sum[:])
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	location, err := c.store.Put(ctx, url.PathEscape(message.Topic)+"/"+url.PathEscape(string(message.Key))+"/"+digest, message.Value)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := jsoniter.Marshal(Reference{Location: location, SHA256: digest, Size: len(message.Value)})
+	if err != nil {
+		return false, err
+	}
+
+	headers := make([]kafka.Header, 0, len(message.Headers)+1)
+	message.Headers = append(append(headers, message.Headers...), kafka.Header{Key: LocationHeader, Value: []byte(location)})
+	message.Value = value
+	return true, nil
+}
@@ -0,0 +1,41 @@
+package claimcheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+const StoreTypeFile = "file"
+
+// Store uploads oversized payloads, e.g. to S3 or GCS, and returns the location consumers fetch them from.
+// Keys are content addressed, so uploading the same payload again on replay is safe.
+type Store interface {
+	Put(ctx context.Context, key string, value []byte) (location string, err error)
+}
+
+type fileStore struct {
+	directory string
+}
+
+// NewFileStore writes payloads under directory, which is typically a shared or mounted volume.
+func NewFileStore(directory string) Store {
+	return &fileStore{directory: directory}
+}
+
+func (s *fileStore) Put(_ context.Context, key string, value []byte) (string, error) {
+	path := filepath.Join(s.directory, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, value, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+
+	return "file://" + filepath.ToSlash(path), nil
+}
@@ -0,0 +1,175 @@
+// Command checkpoint dumps the per-vBucket checkpoints of the connector to JSON and restores them, e.g. for
+// controlled replays or to move a connector between environments.
+//
+//	go run ./cmd/checkpoint export -config config.yml -output checkpoints.json
+//	go run ./cmd/checkpoint import -config config.yml -input checkpoints.json
+//	go run ./cmd/checkpoint seek -config config.yml -seqnos 0=1200,1=980
+//
+// The connector must be stopped before an import, running members override the imported checkpoints.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	dcpkafka "github.com/Trendyol/go-dcp-kafka"
+	"github.com/Trendyol/go-dcp-kafka/checkpoint"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "export":
+		err = runExport(args)
+	case "import":
+		err = runImport(args)
+	case "seek":
+		err = runSeek(args)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		logger.Log.Error("%s error: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: checkpoint export|import|seek -config config.yml [-output file|-input file|-seqnos vbId=seqNo,...]")
+	os.Exit(2)
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	output := flags.String("output", "-", "output file, - writes to stdout")
+	_ = flags.Parse(args)
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := dcpkafka.ExportCheckpoints(c)
+	if err != nil {
+		return err
+	}
+
+	data, err := jsoniter.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+	logger.Log.Info("exported %d vBucket checkpoints to %s", len(snapshot.VBuckets), *output)
+	return nil
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	input := flags.String("input", "-", "input file, - reads from stdin")
+	_ = flags.Parse(args)
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if *input == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot checkpoint.Snapshot
+	if err := jsoniter.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if snapshot.GroupName != "" && snapshot.GroupName != c.Dcp.Dcp.Group.Name {
+		logger.Log.Warn("importing checkpoints of group %s into group %s", snapshot.GroupName, c.Dcp.Dcp.Group.Name)
+	}
+
+	if err := dcpkafka.ImportCheckpoints(c, &snapshot); err != nil {
+		return err
+	}
+	logger.Log.Info("imported %d vBucket checkpoints", len(snapshot.VBuckets))
+	return nil
+}
+
+func runSeek(args []string) error {
+	flags := flag.NewFlagSet("seek", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	seqNosFlag := flags.String("seqnos", "", "comma separated vbId=seqNo pairs")
+	_ = flags.Parse(args)
+
+	seqNos, err := parseSeqNos(*seqNosFlag)
+	if err != nil {
+		return err
+	}
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	if err := dcpkafka.SeekCheckpoints(c, seqNos); err != nil {
+		return err
+	}
+	logger.Log.Info("moved the checkpoints of %d vBuckets", len(seqNos))
+	return nil
+}
+
+func parseSeqNos(value string) (map[uint16]uint64, error) {
+	seqNos := map[uint16]uint64{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		vbID, seqNo, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid seqno pair: %s", pair)
+		}
+		parsedVbID, err := strconv.ParseUint(vbID, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vbId: %s", vbID)
+		}
+		parsedSeqNo, err := strconv.ParseUint(seqNo, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seqno: %s", seqNo)
+		}
+		seqNos[uint16(parsedVbID)] = parsedSeqNo
+	}
+
+	if len(seqNos) == 0 {
+		return nil, errors.New("-seqnos must be set")
+	}
+	return seqNos, nil
+}
@@ -0,0 +1,141 @@
+// Command connector sanity checks a deployment of the connector, e.g. in CI.
+//
+//	go run ./cmd/connector validate -config config.yml
+//	go run ./cmd/connector dry-run -config config.yml -limit 10
+//	go run ./cmd/connector replay -config config.yml -ranges 5=100-200,6=0-50
+//
+// validate checks the config, the bucket and broker connectivity and the configured topics. dry-run streams events
+// through the default mapper and prints the messages as JSON lines, nothing is produced and no checkpoint is saved.
+// replay produces the events of the seqno windows again with a replay=true header.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	dcpkafka "github.com/Trendyol/go-dcp-kafka"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "validate":
+		err = runValidate(args)
+	case "dry-run":
+		err = runDryRun(args)
+	case "replay":
+		err = runReplay(args)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		logger.Log.Error("%s error: %v", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: connector validate|dry-run|replay -config config.yml")
+	fmt.Fprintln(os.Stderr, "  dry-run [-limit n -output file -timeout duration]")
+	fmt.Fprintln(os.Stderr, "  replay -ranges vbId=from-to,... [-timeout duration]")
+	os.Exit(2)
+}
+
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	_ = flags.Parse(args)
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	if err := dcpkafka.Preflight(c); err != nil {
+		return err
+	}
+	logger.Log.Info("%s is valid", *configPath)
+	return nil
+}
+
+func runDryRun(args []string) error {
+	flags := flag.NewFlagSet("dry-run", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	limit := flags.Int("limit", 10, "number of messages to print, 0 means unlimited")
+	timeout := flags.Duration("timeout", time.Minute, "maximum streaming duration, 0 means unlimited")
+	output := flags.String("output", "-", "output file, - writes to stdout")
+	_ = flags.Parse(args)
+
+	out := os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext(*timeout)
+	defer cancel()
+
+	return dcpkafka.DryRun(ctx, dcpkafka.NewConnectorBuilder(c), *limit, out)
+}
+
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "connector config file")
+	rangesFlag := flags.String("ranges", "", "comma separated vbId=from-to inclusive seqno windows")
+	timeout := flags.Duration("timeout", 10*time.Minute, "maximum replay duration, 0 means unlimited")
+	_ = flags.Parse(args)
+
+	ranges, err := dcpkafka.ParseReplayRanges(*rangesFlag)
+	if err != nil {
+		return err
+	}
+
+	c, err := config.Load(config.Sources{Files: []string{*configPath}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext(*timeout)
+	defer cancel()
+
+	if err := dcpkafka.Replay(ctx, dcpkafka.NewConnectorBuilder(c), ranges); err != nil {
+		return err
+	}
+	logger.Log.Info("replayed %d seqno ranges", len(ranges))
+	return nil
+}
+
+// signalContext is done on SIGINT, SIGTERM or after the timeout, 0 means no timeout.
+func signalContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, cancel
+	}
+
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancelTimeout()
+		cancel()
+	}
+}
@@ -0,0 +1,128 @@
+// Command schema-export samples the DCP stream of the configured bucket, infers a schema per collection
+// and writes it as JSON Schema or Avro, as a starting contract before enabling registry-backed serialization.
+//
+//	go run ./cmd/schema-export -config config.yml -duration 5m -format avro -output schemas
+//
+// Checkpoints are neither read nor written, the stream starts from the beginning or from the latest seqnos.
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/Trendyol/go-dcp"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/schema"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yml", "connector config file")
+	duration := flag.Duration("duration", 5*time.Minute, "sampling duration")
+	maxSamples := flag.Int("max-samples", 10000, "maximum sampled documents per collection, 0 means unlimited")
+	format := flag.String("format", schema.FormatJSONSchema, "export format, jsonschema or avro")
+	output := flag.String("output", "schemas", "output directory")
+	from := flag.String("from", "earliest", "stream start, earliest samples the bucket, latest only new changes")
+	flag.Parse()
+
+	if *format != schema.FormatJSONSchema && *format != schema.FormatAvro {
+		logger.Log.Error("invalid format: %s", *format)
+		os.Exit(1)
+	}
+
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		logger.Log.Error("config error: %v", err)
+		os.Exit(1)
+	}
+
+	metadataDir, err := os.MkdirTemp("", "schema-export")
+	if err != nil {
+		logger.Log.Error("metadata error: %v", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(metadataDir)
+
+	applySamplingConfig(&c.Dcp, metadataDir, *from)
+
+	inferrer := schema.NewInferrer(*maxSamples)
+
+	dcpClient, err := dcp.NewDcp(&c.Dcp, func(ctx *models.ListenerContext) {
+		if event, ok := ctx.Event.(models.DcpMutation); ok {
+			inferrer.Observe(event.CollectionName, event.Value)
+		}
+		ctx.Ack()
+	})
+	if err != nil {
+		logger.Log.Error("dcp error: %v", err)
+		os.Exit(1)
+	}
+
+	go func() {
+		<-dcpClient.WaitUntilReady()
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+		select {
+		case <-time.After(*duration):
+		case <-signals:
+		}
+		dcpClient.Close()
+	}()
+
+	dcpClient.Start()
+
+	if err := export(inferrer, *format, *output); err != nil {
+		logger.Log.Error("export error: %v", err)
+		os.Exit(1)
+	}
+}
+
+func loadConfig(path string) (*config.Connector, error) {
+	return config.Load(config.Sources{Files: []string{path}})
+}
+
+// applySamplingConfig runs a single standalone member on throwaway read only metadata,
+// so the checkpoints and the group of the connector are not touched.
+func applySamplingConfig(c *dcpConfig.Dcp, metadataDir string, from string) {
+	c.Metadata = dcpConfig.Metadata{
+		Type:     dcpConfig.MetadataTypeFile,
+		Config:   map[string]any{dcpConfig.FileMetadataFileNameConfig: filepath.Join(metadataDir, "checkpoints.json")},
+		ReadOnly: true,
+	}
+	c.Checkpoint.AutoReset = from
+	c.Dcp.Group.Name += "-schema-export"
+	c.Dcp.Group.Membership = dcpConfig.DCPGroupMembership{Type: membership.StaticMembershipType, MemberNumber: 1, TotalMembers: 1}
+	c.LeaderElection.Enabled = false
+	c.API.Disabled = true
+}
+
+func export(inferrer *schema.Inferrer, format string, output string) error {
+	if err := os.MkdirAll(output, 0o755); err != nil {
+		return err
+	}
+
+	for collection, samples := range inferrer.Collections() {
+		data, err := inferrer.Export(collection, format)
+		if err != nil {
+			logger.Log.Error("cannot export collection: %s, err: %v", collection, err)
+			continue
+		}
+
+		path := filepath.Join(output, schema.FileName(collection, format))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		logger.Log.Info("exported %s from %d documents", path, samples)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package dcpkafka
+
+import (
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func (c *connector) DisableCollections(names ...string) error {
+	return c.collections.Disable(names)
+}
+
+func (c *connector) EnableCollections(names ...string) error {
+	return c.collections.Enable(names)
+}
+
+// skipDisabledCollection acks events of disabled collections and returns true for them.
+func (c *connector) skipDisabledCollection(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if !c.collections.IsDisabled(e.CollectionName) {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().DisabledCollectionEvents, 1)
+	ctx.Ack()
+	return true
+}
+
+type collectionFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+func newCollectionFilter(filterConfig config.CollectionFilter) *collectionFilter {
+	if len(filterConfig.Include) == 0 && len(filterConfig.Exclude) == 0 {
+		return nil
+	}
+
+	f := &collectionFilter{exclude: toSet(filterConfig.Exclude)}
+	if len(filterConfig.Include) > 0 {
+		f.include = toSet(filterConfig.Include)
+	}
+	return f
+}
+
+func (f *collectionFilter) allows(collectionName string) bool {
+	if _, ok := f.exclude[collectionName]; ok {
+		return false
+	}
+	if f.include == nil {
+		return true
+	}
+	_, ok := f.include[collectionName]
+	return ok
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+// skipFilteredCollection acks events of collections excluded by kafka.collectionFilter and returns true for them.
+func (c *connector) skipFilteredCollection(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.collectionFilter == nil || c.collectionFilter.allows(e.CollectionName) {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+	ctx.Ack()
+	return true
+}
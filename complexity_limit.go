@@ -0,0 +1,60 @@
+package dcpkafka
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const deadLetterReasonHeader = "dcp-kafka-dead-letter-reason"
+
+// applyComplexityLimit returns false when the event must not reach the mapper. Documents that do not parse are not
+// over a limit and pass, the mapper decides what to do with them.
+func (c *connector) applyComplexityLimit(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	limit := c.config.Kafka.JSONComplexityLimit
+
+	err := document.CheckComplexity(e.Value, limit.MaxDepth, limit.MaxFields)
+	if !errors.Is(err, document.ErrMaxDepthExceeded) && !errors.Is(err, document.ErrMaxFieldsExceeded) {
+		return true
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().JSONComplexityExceeded, 1)
+
+	switch c.config.Kafka.GetJSONComplexityPolicy() {
+	case config.ComplexityPolicyTruncate:
+		truncated, truncateErr := document.TruncateComplexity(e.Value, limit.MaxDepth, limit.MaxFields)
+		if truncateErr == nil {
+			e.Value = truncated
+			return true
+		}
+		logging.Error(append(eventFields(e), logging.Err(truncateErr)), "cannot truncate document")
+	case config.ComplexityPolicyDeadLetter:
+		c.produceDeadLetter(ctx, e, err)
+		return false
+	default:
+		logger.Log.Debug("skipping document, key: %s, err: %v", e.Key, err)
+	}
+
+	ctx.Ack()
+	return false
+}
+
+func (c *connector) produceDeadLetter(ctx *models.ListenerContext, e *couchbase.Event, reason error) {
+	c.sink.Produce(ctx, e.EventTime, []sKafka.Message{
+		{
+			Topic: c.config.Kafka.DeadLetterTopic,
+			Key:   e.Key,
+			Value: e.Value,
+			Headers: []sKafka.Header{
+				{Key: deadLetterReasonHeader, Value: []byte(reason.Error())},
+			},
+		},
+	})
+}
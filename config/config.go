@@ -1,33 +1,592 @@
 package config
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Trendyol/go-dcp/config"
 )
 
 type Kafka struct {
-	CollectionTopicMapping      map[string]string `yaml:"collectionTopicMapping"`
-	InterCAPath                 string            `yaml:"interCAPath"`
-	ScramUsername               string            `yaml:"scramUsername"`
-	ScramPassword               string            `yaml:"scramPassword"`
-	RootCAPath                  string            `yaml:"rootCAPath"`
-	ClientID                    string            `yaml:"clientID"`
-	Brokers                     []string          `yaml:"brokers"`
-	MetadataTopics              []string          `yaml:"metadataTopics"`
-	ProducerBatchBytes          int64             `yaml:"producerBatchBytes"`
-	ProducerBatchTimeout        time.Duration     `yaml:"producerBatchTimeout"`
-	ProducerMaxAttempts         int               `yaml:"producerMaxAttempts"`
-	ReadTimeout                 time.Duration     `yaml:"readTimeout"`
-	WriteTimeout                time.Duration     `yaml:"writeTimeout"`
-	RequiredAcks                int               `yaml:"requiredAcks"`
-	ProducerBatchSize           int               `yaml:"producerBatchSize"`
-	MetadataTTL                 time.Duration     `yaml:"metadataTTL"`
-	ProducerBatchTickerDuration time.Duration     `yaml:"producerBatchTickerDuration"`
-	Compression                 int8              `yaml:"compression"`
-	SecureConnection            bool              `yaml:"secureConnection"`
-	AllowAutoTopicCreation      bool              `yaml:"allowAutoTopicCreation"`
+	CollectionTopicMapping       map[string]string        `yaml:"collectionTopicMapping"`
+	InterCAPath                  string                   `yaml:"interCAPath"`
+	ClientCertPath               string                   `yaml:"clientCertPath"`
+	ClientKeyPath                string                   `yaml:"clientKeyPath"`
+	ClientKeyPassphrase          string                   `yaml:"clientKeyPassphrase"`
+	ScramUsername                string                   `yaml:"scramUsername"`
+	ScramPassword                string                   `yaml:"scramPassword"`
+	RootCAPath                   string                   `yaml:"rootCAPath"`
+	ClientID                     string                   `yaml:"clientID"`
+	Brokers                      []string                 `yaml:"brokers"`
+	Dialer                       Dialer                   `yaml:"dialer"`
+	MetadataTopics               []string                 `yaml:"metadataTopics"`
+	ProducerBatchBytes           int64                    `yaml:"producerBatchBytes"`
+	ProducerRequestBytes         int64                    `yaml:"producerRequestBytes"`
+	ProducerBatchTimeout         time.Duration            `yaml:"producerBatchTimeout"`
+	ProducerMaxAttempts          int                      `yaml:"producerMaxAttempts"`
+	ReadTimeout                  time.Duration            `yaml:"readTimeout"`
+	WriteTimeout                 time.Duration            `yaml:"writeTimeout"`
+	RequiredAcks                 int                      `yaml:"requiredAcks"`
+	ProducerBatchSize            int                      `yaml:"producerBatchSize"`
+	ProducerFlushParallelism     int                      `yaml:"producerFlushParallelism"`
+	ProducerMaxInFlightFlushes   int                      `yaml:"producerMaxInFlightFlushes"`
+	ProducerFlushTimeout         time.Duration            `yaml:"producerFlushTimeout"`
+	ProducerFailureTimeout       time.Duration            `yaml:"producerFailureTimeout"`
+	MetadataTTL                  time.Duration            `yaml:"metadataTTL"`
+	ProducerBatchTickerDuration  time.Duration            `yaml:"producerBatchTickerDuration"`
+	Compression                  int8                     `yaml:"compression"`
+	SecureConnection             bool                     `yaml:"secureConnection"`
+	AllowAutoTopicCreation       bool                     `yaml:"allowAutoTopicCreation"`
+	TopicCreation                TopicCreation            `yaml:"topicCreation"`
+	DcpMetadataHeaders           bool                     `yaml:"dcpMetadataHeaders"`
+	ShadowMode                   bool                     `yaml:"shadowMode"`
+	Headers                      map[string]string        `yaml:"headers"`
+	ValueTemplate                string                   `yaml:"valueTemplate"`
+	Tombstone                    bool                     `yaml:"tombstone"`
+	CompactedTopics              bool                     `yaml:"compactedTopics"`
+	TombstoneDelay               time.Duration            `yaml:"tombstoneDelay"`
+	MessageTimestamp             string                   `yaml:"messageTimestamp"`
+	ExpirationPolicy             string                   `yaml:"expirationPolicy"`
+	DeadLetterTopic              string                   `yaml:"deadLetterTopic"`
+	ProcessingErrorPolicy        string                   `yaml:"processingErrorPolicy"`
+	Filter                       string                   `yaml:"filter"`
+	EventTypes                   []string                 `yaml:"eventTypes"`
+	JSONComplexityLimit          JSONComplexityLimit      `yaml:"jsonComplexityLimit"`
+	DocumentSizeLimit            DocumentSizeLimit        `yaml:"documentSizeLimit"`
+	Migration                    Migration                `yaml:"migration"`
+	AdminAPI                     AdminAPI                 `yaml:"adminAPI"`
+	GRPCAPI                      GRPCAPI                  `yaml:"grpcAPI"`
+	Debug                        Debug                    `yaml:"debug"`
+	RateLimit                    RateLimit                `yaml:"rateLimit"`
+	StateStore                   StateStore               `yaml:"stateStore"`
+	Pause                        Pause                    `yaml:"pause"`
+	Enrichment                   Enrichment               `yaml:"enrichment"`
+	Xattrs                       Xattrs                   `yaml:"xattrs"`
+	LatencyBudget                LatencyBudget            `yaml:"latencyBudget"`
+	AdaptiveBatch                AdaptiveBatch            `yaml:"adaptiveBatch"`
+	BufferLimit                  BufferLimit              `yaml:"bufferLimit"`
+	OutageQueue                  OutageQueue              `yaml:"outageQueue"`
+	KeyStrategy                  KeyStrategy              `yaml:"keyStrategy"`
+	BinaryDocuments              BinaryDocuments          `yaml:"binaryDocuments"`
+	SchemaRegistry               SchemaRegistry           `yaml:"schemaRegistry"`
+	Transforms                   []Transform              `yaml:"transforms"`
+	Mapper                       Mapper                   `yaml:"mapper"`
+	Dedup                        Dedup                    `yaml:"dedup"`
+	SeqNoDedup                   SeqNoDedup               `yaml:"seqNoDedup"`
+	Chunking                     Chunking                 `yaml:"chunking"`
+	ValueCompression             ValueCompression         `yaml:"valueCompression"`
+	Encryption                   Encryption               `yaml:"encryption"`
+	ClaimCheck                   ClaimCheck               `yaml:"claimCheck"`
+	CompressionStats             CompressionStats         `yaml:"compressionStats"`
+	WriterStats                  WriterStats              `yaml:"writerStats"`
+	Mirror                       Mirror                   `yaml:"mirror"`
+	Chaos                        Chaos                    `yaml:"chaos"`
+	Failover                     Failover                 `yaml:"failover"`
+	StartupReport                bool                     `yaml:"startupReport"`
+	ShutdownReportPath           string                   `yaml:"shutdownReportPath"`
+	AckMode                      string                   `yaml:"ackMode"`
+	DeliverySemantics            string                   `yaml:"deliverySemantics"`
+	SyncProduce                  SyncProduce              `yaml:"syncProduce"`
+	LagMetric                    LagMetric                `yaml:"lagMetric"`
+	EndToEndLatencyBuckets       []float64                `yaml:"endToEndLatencyBuckets"`
+	Metrics                      Metrics                  `yaml:"metrics"`
+	ErrorLogSampling             ErrorLogSampling         `yaml:"errorLogSampling"`
+	HotReload                    HotReload                `yaml:"hotReload"`
+	Secrets                      Secrets                  `yaml:"secrets"`
+	CredentialRotation           CredentialRotation       `yaml:"credentialRotation"`
+	TopicOverrides               map[string]TopicOverride `yaml:"topicOverrides"`
+	CollectionFilter             CollectionFilter         `yaml:"collectionFilter"`
+	KeyFilter                    KeyFilter                `yaml:"keyFilter"`
+	RollbackMarker               RollbackMarker           `yaml:"rollbackMarker"`
+	RebalanceBuffer              string                   `yaml:"rebalanceBuffer"`
+	RebalanceFlushTimeout        time.Duration            `yaml:"rebalanceFlushTimeout"`
+	CheckpointCommitInterval     time.Duration            `yaml:"checkpointCommitInterval"`
+	CheckpointCommitEveryFlushes int                      `yaml:"checkpointCommitEveryFlushes"`
+	StartFrom                    StartFrom                `yaml:"startFrom"`
+	VBuckets                     []string                 `yaml:"vBuckets"`
+	Sampling                     Sampling                 `yaml:"sampling"`
+	ActivePassive                ActivePassive            `yaml:"activePassive"`
+}
+
+// ActivePassive streams only on the replica holding the lease. Config has leaseLockName and leaseLockNamespace for
+// the kubernetes type, the couchbase type keeps the lease next to the checkpoints.
+type ActivePassive struct {
+	Config        map[string]string `yaml:"config"`
+	Type          string            `yaml:"type"`
+	Identity      string            `yaml:"identity"`
+	LeaseDuration time.Duration     `yaml:"leaseDuration"`
+	RetryPeriod   time.Duration     `yaml:"retryPeriod"`
+	Enabled       bool              `yaml:"enabled"`
+}
+
+// Sampling produces Percentage percent of the mutations picked at random, or every mutation of one in KeyOneIn keys.
+// Deletions and expirations are always produced.
+type Sampling struct {
+	Percentage float64 `yaml:"percentage"`
+	KeyOneIn   int     `yaml:"keyOneIn"`
+	Enabled    bool    `yaml:"enabled"`
+}
+
+// StartFrom overrides the loaded checkpoints when the connector starts. Timestamp streams every vBucket from the
+// beginning and skips the events older than it, SeqNos resumes the listed vBuckets after the given seqnos.
+type StartFrom struct {
+	Timestamp time.Time         `yaml:"timestamp"`
+	SeqNos    map[uint16]uint64 `yaml:"seqNos"`
+}
+
+func (s *StartFrom) IsSet() bool {
+	return !s.Timestamp.IsZero() || len(s.SeqNos) > 0
+}
+
+// RollbackMarker produces a control message to Topic for every detected rollback, when Topic is set.
+type RollbackMarker struct {
+	Topic   string `yaml:"topic"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+// CollectionFilter skips the events of collections not in Include, when it is set, or in Exclude.
+type CollectionFilter struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// KeyFilter skips the events of document keys not matching Include or IncludePrefixes, when either is set, or
+// matching Exclude or ExcludePrefixes. Include and Exclude are regular expressions.
+type KeyFilter struct {
+	Include         []string `yaml:"include"`
+	Exclude         []string `yaml:"exclude"`
+	IncludePrefixes []string `yaml:"includePrefixes"`
+	ExcludePrefixes []string `yaml:"excludePrefixes"`
+}
+
+// TopicOverride replaces the writer settings for a single topic, unset values keep the global ones.
+// RequiredAcks and Compression are pointers since their zero values are valid settings.
+type TopicOverride struct {
+	RequiredAcks         *int          `yaml:"requiredAcks"`
+	Compression          *int8         `yaml:"compression"`
+	ProducerBatchSize    int           `yaml:"producerBatchSize"`
+	ProducerBatchTimeout time.Duration `yaml:"producerBatchTimeout"`
+	ReadTimeout          time.Duration `yaml:"readTimeout"`
+	WriteTimeout         time.Duration `yaml:"writeTimeout"`
+}
+
+// CredentialRotation checks the secrets and the certificate files every Interval and rebuilds the transport on change.
+type CredentialRotation struct {
+	Interval time.Duration `yaml:"interval"`
+	Enabled  bool          `yaml:"enabled"`
+}
+
+// Secrets resolves the credentials from a secret provider instead of the config file, the credential fields hold
+// the names of the secrets. Credentials are resolved again every RefreshInterval for new connections.
+type Secrets struct {
+	Type                string        `yaml:"type"`
+	Directory           string        `yaml:"directory"`
+	VaultAddress        string        `yaml:"vaultAddress"`
+	VaultToken          string        `yaml:"vaultToken"`
+	VaultPath           string        `yaml:"vaultPath"`
+	ScramUsername       string        `yaml:"scramUsername"`
+	ScramPassword       string        `yaml:"scramPassword"`
+	ClientKeyPassphrase string        `yaml:"clientKeyPassphrase"`
+	Timeout             time.Duration `yaml:"timeout"`
+	RefreshInterval     time.Duration `yaml:"refreshInterval"`
+}
+
+type HotReload struct {
+	Interval time.Duration `yaml:"interval"`
+	Enabled  bool          `yaml:"enabled"`
+}
+
+const (
+	MetricsSinkPrometheus = "prometheus"
+	MetricsSinkStatsD     = "statsd"
+	MetricsSinkDatadog    = "datadog"
+)
+
+// Metrics pushes the connector metrics to the Address of a StatsD or Datadog agent every Interval, next to the
+// Prometheus endpoint. Tags are added to every metric of the datadog sink.
+type Metrics struct {
+	Tags     map[string]string `yaml:"tags"`
+	Sink     string            `yaml:"sink"`
+	Address  string            `yaml:"address"`
+	Prefix   string            `yaml:"prefix"`
+	Interval time.Duration     `yaml:"interval"`
+}
+
+// ErrorLogSampling logs Burst identical producer errors per Interval and a summary of the suppressed ones.
+type ErrorLogSampling struct {
+	Interval time.Duration `yaml:"interval"`
+	Burst    int           `yaml:"burst"`
+	Enabled  bool          `yaml:"enabled"`
+}
+
+type LagMetric struct {
+	Interval time.Duration `yaml:"interval"`
+	Enabled  bool          `yaml:"enabled"`
+}
+
+// SyncProduce writes every event on its own instead of batching, MaxRetries 0 retries until the write succeeds.
+type SyncProduce struct {
+	RetryInterval time.Duration `yaml:"retryInterval"`
+	MaxRetries    int           `yaml:"maxRetries"`
+	Enabled       bool          `yaml:"enabled"`
+}
+
+type Failover struct {
+	Brokers   []string      `yaml:"brokers"`
+	Threshold time.Duration `yaml:"threshold"`
+	Enabled   bool          `yaml:"enabled"`
+}
+
+// Chaos is meant for staging environments only, percentages are between 0 and 100.
+type Chaos struct {
+	Delay           time.Duration `yaml:"delay"`
+	DelayPercentage float64       `yaml:"delayPercentage"`
+	ErrorPercentage float64       `yaml:"errorPercentage"`
+	Enabled         bool          `yaml:"enabled"`
+}
+
+// Mirror produces every primary batch to a second cluster, e.g. for an active/active DR setup.
+type Mirror struct {
+	DeadLetterTopic string   `yaml:"deadLetterTopic"`
+	Brokers         []string `yaml:"brokers"`
+	MaxAttempts     int      `yaml:"maxAttempts"`
+	Enabled         bool     `yaml:"enabled"`
+}
+
+type CompressionStats struct {
+	SampleRate int  `yaml:"sampleRate"`
+	Enabled    bool `yaml:"enabled"`
+}
+
+// WriterStats samples the kafka.Writer stats of every cluster every Interval.
+type WriterStats struct {
+	Interval time.Duration `yaml:"interval"`
+	Enabled  bool          `yaml:"enabled"`
+}
+
+type ClaimCheck struct {
+	Type      string        `yaml:"type"`
+	Directory string        `yaml:"directory"`
+	Threshold int           `yaml:"threshold"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Enabled   bool          `yaml:"enabled"`
+}
+
+type Chunking struct {
+	MaxSize int  `yaml:"maxSize"`
+	Enabled bool `yaml:"enabled"`
+}
+
+const (
+	ValueCompressionGzip = "gzip"
+	ValueCompressionZstd = "zstd"
+)
+
+// ValueCompression compresses the values of at least MinSize bytes with Codec, independent of the broker compression.
+type ValueCompression struct {
+	Codec   string `yaml:"codec"`
+	MinSize int    `yaml:"minSize"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+// Encryption encrypts the values of Topics, every topic when it is empty, with data keys wrapped by the KMS key KeyID.
+type Encryption struct {
+	Type       string        `yaml:"type"`
+	KeyID      string        `yaml:"keyID"`
+	Region     string        `yaml:"region"`
+	Topics     []string      `yaml:"topics"`
+	DataKeyTTL time.Duration `yaml:"dataKeyTTL"`
+	Timeout    time.Duration `yaml:"timeout"`
+	Enabled    bool          `yaml:"enabled"`
+}
+
+type Dedup struct {
+	Window  time.Duration `yaml:"window"`
+	MaxSize int64         `yaml:"maxSize"`
+	Enabled bool          `yaml:"enabled"`
+}
+
+// SeqNoDedup skips the events produced before a crash-restart from the last produced seqno of every vbucket, saved in
+// the state store every SaveInterval.
+type SeqNoDedup struct {
+	SaveInterval time.Duration `yaml:"saveInterval"`
+	Enabled      bool          `yaml:"enabled"`
+}
+
+const (
+	MapperTypeWASM       = "wasm"
+	MapperTypeJavaScript = "javascript"
+	MapperTypeJSONPath   = "jsonpath"
+)
+
+// Mapper replaces the mapper of the builder with one loaded from Path when Type is set. The jsonpath type selects
+// the key, topic, value fields and headers of the document with JSONPath expressions instead. Timeout interrupts a
+// javascript map call.
+type Mapper struct {
+	Value   map[string]string `yaml:"value"`
+	Headers map[string]string `yaml:"headers"`
+	Type    string            `yaml:"type"`
+	Path    string            `yaml:"path"`
+	Key     string            `yaml:"key"`
+	Topic   string            `yaml:"topic"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+// Transform configures a built-in single message transform, only the fields of its type are used.
+type Transform struct {
+	Type        string   `yaml:"type"`
+	Field       string   `yaml:"field"`
+	To          string   `yaml:"to"`
+	Header      string   `yaml:"header"`
+	Value       string   `yaml:"value"`
+	Regex       string   `yaml:"regex"`
+	Replacement string   `yaml:"replacement"`
+	Mode        string   `yaml:"mode"`
+	Salt        string   `yaml:"salt"`
+	Key         string   `yaml:"key"`
+	Fields      []string `yaml:"fields"`
+	Collections []string `yaml:"collections"`
+}
+
+const (
+	SchemaRegistryFallbackFail    = "fail"
+	SchemaRegistryFallbackCache   = "cache"
+	SchemaRegistryFallbackRawJSON = "rawJSON"
+	SchemaRegistryFallbackPause   = "pause"
+)
+
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeJSON     = "JSON"
+	SchemaTypeProtobuf = "PROTOBUF"
+)
+
+// SchemaRegistry serializes values with the latest schema of the `<topic>-value` subject.
+// Fallback decides what happens while the registry is unavailable.
+type SchemaRegistry struct {
+	URL           string        `yaml:"url"`
+	Username      string        `yaml:"username"`
+	Password      string        `yaml:"password"`
+	BearerToken   string        `yaml:"bearerToken"`
+	CAPath        string        `yaml:"caPath"`
+	Fallback      string        `yaml:"fallback"`
+	CacheTTL      time.Duration `yaml:"cacheTTL"`
+	Timeout       time.Duration `yaml:"timeout"`
+	RetryInterval time.Duration `yaml:"retryInterval"`
+	// Schemas maps topics to local schema files checked for compatibility with their subjects.
+	Schemas    map[string]string `yaml:"schemas"`
+	SchemaType string            `yaml:"schemaType"`
+	Enabled    bool              `yaml:"enabled"`
+}
+
+const (
+	BinaryEncodingRaw            = "raw"
+	BinaryEncodingBase64Envelope = "base64Envelope"
+	BinaryEncodingSkip           = "skip"
+)
+
+type BinaryDocuments struct {
+	Encoding string `yaml:"encoding"`
+	Enabled  bool   `yaml:"enabled"`
+}
+
+const (
+	KeyStrategyID           = "id"
+	KeyStrategyField        = "field"
+	KeyStrategyCollectionID = "collectionId"
+	KeyStrategyNone         = "none"
+)
+
+// KeyStrategy sets the message keys, MinPartitions is the partition count the keyed strategies need on the
+// destination topics, checked on startup.
+type KeyStrategy struct {
+	Type          string `yaml:"type"`
+	Field         string `yaml:"field"`
+	Separator     string `yaml:"separator"`
+	MinPartitions int    `yaml:"minPartitions"`
+}
+
+type LatencyBudget struct {
+	LateTopic    string        `yaml:"lateTopic"`
+	Budget       time.Duration `yaml:"budget"`
+	MaxStaleness time.Duration `yaml:"maxStaleness"`
+}
+
+// AdaptiveBatch tunes producerBatchSize and producerBatchTickerDuration within the bounds from the flush latency and
+// the throughput, the configured values are the starting point.
+type AdaptiveBatch struct {
+	TargetLatency     time.Duration `yaml:"targetLatency"`
+	MinTickerDuration time.Duration `yaml:"minTickerDuration"`
+	MaxTickerDuration time.Duration `yaml:"maxTickerDuration"`
+	MinSize           int           `yaml:"minSize"`
+	MaxSize           int           `yaml:"maxSize"`
+	Enabled           bool          `yaml:"enabled"`
+}
+
+type Enrichment struct {
+	Cache      EnrichmentCache    `yaml:"cache"`
+	BucketName string             `yaml:"bucketName"`
+	Lookups    []EnrichmentLookup `yaml:"lookups"`
+	Timeout    time.Duration      `yaml:"timeout"`
+	Enabled    bool               `yaml:"enabled"`
+}
+
+// Xattrs reads the extended attributes Names of mutations into couchbase.Event.Xattrs for the Mapper, and copies the
+// ones in Headers into message headers.
+type Xattrs struct {
+	Names   []string      `yaml:"names"`
+	Headers []string      `yaml:"headers"`
+	Timeout time.Duration `yaml:"timeout"`
+	Enabled bool          `yaml:"enabled"`
+}
+
+// EnrichmentLookup embeds the document whose key is rendered from KeyTemplate(text/template over the document) into Field.
+type EnrichmentLookup struct {
+	Field          string `yaml:"field"`
+	KeyTemplate    string `yaml:"keyTemplate"`
+	ScopeName      string `yaml:"scopeName"`
+	CollectionName string `yaml:"collectionName"`
+}
+
+type EnrichmentCache struct {
+	Redis       RedisCache    `yaml:"redis"`
+	Type        string        `yaml:"type"`
+	TTL         time.Duration `yaml:"ttl"`
+	NegativeTTL time.Duration `yaml:"negativeTTL"`
+	MaxSize     int           `yaml:"maxSize"`
+}
+
+type RedisCache struct {
+	Username  string   `yaml:"username"`
+	Password  string   `yaml:"password"`
+	KeyPrefix string   `yaml:"keyPrefix"`
+	Addresses []string `yaml:"addresses"`
+	DB        int      `yaml:"db"`
+}
+
+type StateStore struct {
+	Type      string `yaml:"type"`
+	Directory string `yaml:"directory"`
+}
+
+// Pause caps the events held by paused vBuckets and key prefixes, the listener blocks at MaxHeldEvents.
+type Pause struct {
+	MaxHeldEvents int `yaml:"maxHeldEvents"`
+}
+
+type RateLimit struct {
+	MessagesPerSecond int `yaml:"messagesPerSecond"`
+	BytesPerSecond    int `yaml:"bytesPerSecond"`
+}
+
+type AdminAPI struct {
+	Port    int  `yaml:"port"`
+	Enabled bool `yaml:"enabled"`
+}
+
+// GRPCAPI serves the admin service of grpcapi/admin.proto.
+type GRPCAPI struct {
+	Port    int  `yaml:"port"`
+	Enabled bool `yaml:"enabled"`
+}
+
+// Dialer tunes the broker connections, e.g. a keepalive below the idle timeout of a NAT gateway or the local address
+// of a dual-homed host. Resolver is the address of a DNS server for the broker host names. Proxy is a socks5:// or
+// http:// URL the connections are tunneled through.
+type Dialer struct {
+	Proxy        string        `yaml:"proxy"`
+	LocalAddress string        `yaml:"localAddress"`
+	Resolver     string        `yaml:"resolver"`
+	Timeout      time.Duration `yaml:"timeout"`
+	KeepAlive    time.Duration `yaml:"keepAlive"`
+}
+
+func (d *Dialer) IsSet() bool {
+	return d.Proxy != "" || d.LocalAddress != "" || d.Resolver != "" || d.Timeout != 0 || d.KeepAlive != 0
+}
+
+// TopicCreation creates the missing destination topics on startup, 0 partitions or replication factor means the
+// broker default and 0 retention keeps the broker retention.
+type TopicCreation struct {
+	Partitions        int           `yaml:"partitions"`
+	ReplicationFactor int           `yaml:"replicationFactor"`
+	Retention         time.Duration `yaml:"retention"`
+	Enabled           bool          `yaml:"enabled"`
+}
+
+// Debug serves the pprof profiles and the expvar variables, for profiling in production.
+type Debug struct {
+	Port    int  `yaml:"port"`
+	Enabled bool `yaml:"enabled"`
+}
+
+// Migration shifts a percentage of keys to a new topic and/or cluster.
+// Topics missing in TopicMapping keep their name, Brokers may be omitted to stay on the same cluster.
+type Migration struct {
+	TopicMapping map[string]string `yaml:"topicMapping"`
+	Brokers      []string          `yaml:"brokers"`
+	Percentage   int               `yaml:"percentage"`
+	Enabled      bool              `yaml:"enabled"`
+	DualWrite    bool              `yaml:"dualWrite"`
+}
+
+const (
+	ComplexityPolicySkip       = "skip"
+	ComplexityPolicyDeadLetter = "deadLetter"
+	ComplexityPolicyTruncate   = "truncate"
+)
+
+type JSONComplexityLimit struct {
+	Policy    string `yaml:"policy"`
+	MaxDepth  int    `yaml:"maxDepth"`
+	MaxFields int    `yaml:"maxFields"`
+}
+
+func (l *JSONComplexityLimit) IsEnabled() bool {
+	return l.MaxDepth > 0 || l.MaxFields > 0
+}
+
+// ProcessingErrorPolicy is what happens to an event failing a transform, the kafka.mapper one or the value template,
+// instead of stopping the connector which would stream the same event again on the restart.
+const (
+	ProcessingErrorPolicySkip       = "skip"
+	ProcessingErrorPolicyDeadLetter = "deadLetter"
+)
+
+const (
+	DocumentSizePolicySkip       = "skip"
+	DocumentSizePolicyDeadLetter = "deadLetter"
+	DocumentSizePolicyClaimCheck = "claimCheck"
+)
+
+// DocumentSizeLimit guards against mutated documents bigger than MaxSize bytes, 0 means unlimited.
+type DocumentSizeLimit struct {
+	Policy  string `yaml:"policy"`
+	MaxSize int    `yaml:"maxSize"`
+}
+
+const (
+	BufferOverflowBlock      = "block"
+	BufferOverflowSpill      = "spill"
+	BufferOverflowDropOldest = "dropOldest"
+)
+
+// BufferLimit caps the bytes buffered across the batch and its in-flight flushes, MaxBytes 0 means unlimited.
+type BufferLimit struct {
+	Policy         string `yaml:"policy"`
+	SpillDirectory string `yaml:"spillDirectory"`
+	MaxBytes       int64  `yaml:"maxBytes"`
+}
+
+// OutageQueue persists the batches Kafka cannot take in a bbolt file at Path and replays them in order every
+// ReplayInterval, so a broker outage does not hold the DCP stream.
+type OutageQueue struct {
+	Path           string        `yaml:"path"`
+	ReplayInterval time.Duration `yaml:"replayInterval"`
+	Enabled        bool          `yaml:"enabled"`
 }
 
 func (k *Kafka) GetCompression() int8 {
@@ -37,6 +596,119 @@ func (k *Kafka) GetCompression() int8 {
 	return k.Compression
 }
 
+const (
+	RebalanceBufferDrop  = "drop"
+	RebalanceBufferFlush = "flush"
+)
+
+const (
+	ExpirationPolicyMapper    = "mapper"
+	ExpirationPolicyTombstone = "tombstone"
+	ExpirationPolicyEnvelope  = "envelope"
+	ExpirationPolicyDrop      = "drop"
+)
+
+func (k *Kafka) GetExpirationPolicy() string {
+	switch k.ExpirationPolicy {
+	case "", ExpirationPolicyMapper:
+		return ExpirationPolicyMapper
+	case ExpirationPolicyTombstone, ExpirationPolicyEnvelope, ExpirationPolicyDrop:
+		return k.ExpirationPolicy
+	default:
+		panic("Invalid expiration policy")
+	}
+}
+
+const (
+	MessageTimestampProduce = "produce"
+	MessageTimestampEvent   = "event"
+)
+
+const (
+	AckModeEnqueue = "enqueue"
+	AckModeFlush   = "flush"
+)
+
+// DeliveryAtLeastOnce commits the checkpoints after the messages are written, DeliveryAtMostOnce commits them before
+// writing and drops the messages of a failed write. Without a delivery semantics the ack mode decides.
+const (
+	DeliveryAtLeastOnce = "atLeastOnce"
+	DeliveryAtMostOnce  = "atMostOnce"
+)
+
+func (k *Kafka) GetAckMode() string {
+	switch k.AckMode {
+	case "", AckModeEnqueue:
+		return AckModeEnqueue
+	case AckModeFlush:
+		return AckModeFlush
+	default:
+		panic("Invalid ack mode")
+	}
+}
+
+func (k *Kafka) GetJSONComplexityPolicy() string {
+	switch k.JSONComplexityLimit.Policy {
+	case "", ComplexityPolicySkip:
+		return ComplexityPolicySkip
+	case ComplexityPolicyDeadLetter:
+		if k.DeadLetterTopic == "" {
+			panic("deadLetterTopic must be set for deadLetter json complexity policy")
+		}
+		return ComplexityPolicyDeadLetter
+	case ComplexityPolicyTruncate:
+		return ComplexityPolicyTruncate
+	default:
+		panic("Invalid json complexity policy")
+	}
+}
+
+func (k *Kafka) GetSchemaRegistryFallback() string {
+	switch k.SchemaRegistry.Fallback {
+	case SchemaRegistryFallbackFail, SchemaRegistryFallbackCache, SchemaRegistryFallbackRawJSON, SchemaRegistryFallbackPause:
+		return k.SchemaRegistry.Fallback
+	default:
+		panic("Invalid schema registry fallback")
+	}
+}
+
+// GetVBuckets parses kafka.vBuckets, e.g. ["0-63", "512"]. Nil means every vBucket.
+func (k *Kafka) GetVBuckets() map[uint16]struct{} {
+	vBuckets, err := parseVBuckets(k.VBuckets)
+	if err != nil {
+		panic(err)
+	}
+	return vBuckets
+}
+
+func parseVBuckets(values []string) (map[uint16]struct{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	vBuckets := map[uint16]struct{}{}
+	for _, value := range values {
+		from, to, isRange := strings.Cut(strings.TrimSpace(value), "-")
+		if !isRange {
+			to = from
+		}
+
+		start, err := strconv.ParseUint(strings.TrimSpace(from), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vBucket %q", value)
+		}
+		end, err := strconv.ParseUint(strings.TrimSpace(to), 10, 16)
+		if err != nil || end < start {
+			return nil, fmt.Errorf("invalid vBucket range %q", value)
+		}
+
+		for vbID := start; vbID <= end; vbID++ {
+			vBuckets[uint16(vbID)] = struct{}{}
+		}
+	}
+	return vBuckets, nil
+}
+
 type Connector struct {
 	Kafka Kafka      `yaml:"kafka"`
 	Dcp   config.Dcp `yaml:",inline"`
@@ -63,6 +735,11 @@ func (c *Connector) ApplyDefaults() {
 		c.Kafka.ProducerBatchBytes = 10485760
 	}
 
+	// the default message.max.bytes of the brokers
+	if c.Kafka.ProducerRequestBytes == 0 {
+		c.Kafka.ProducerRequestBytes = min(c.Kafka.ProducerBatchBytes, 1048576)
+	}
+
 	if c.Kafka.RequiredAcks == 0 {
 		c.Kafka.RequiredAcks = 1
 	}
@@ -71,6 +748,17 @@ func (c *Connector) ApplyDefaults() {
 		c.Kafka.MetadataTTL = 60 * time.Second
 	}
 
+	switch c.Kafka.DeliverySemantics {
+	case DeliveryAtLeastOnce:
+		if c.Kafka.AckMode == "" {
+			c.Kafka.AckMode = AckModeFlush
+		}
+	case DeliveryAtMostOnce:
+		if c.Kafka.ProducerMaxAttempts == 0 {
+			c.Kafka.ProducerMaxAttempts = 1
+		}
+	}
+
 	if c.Kafka.ProducerMaxAttempts == 0 {
 		c.Kafka.ProducerMaxAttempts = math.MaxInt
 	}
@@ -78,4 +766,293 @@ func (c *Connector) ApplyDefaults() {
 	if c.Kafka.ProducerBatchTimeout == 0 {
 		c.Kafka.ProducerBatchTimeout = time.Nanosecond
 	}
+
+	if c.Kafka.RebalanceFlushTimeout == 0 {
+		c.Kafka.RebalanceFlushTimeout = 30 * time.Second
+	}
+
+	c.applyMessageDefaults()
+	c.applyReliabilityDefaults()
+	c.applyServiceDefaults()
+	c.applyMetricDefaults()
+	c.applyAdaptiveBatchDefaults()
+	c.applyEnrichmentDefaults()
+	c.applySchemaRegistryDefaults()
+}
+
+func (c *Connector) applyMessageDefaults() {
+	if c.Kafka.Mapper.Type == MapperTypeJavaScript && c.Kafka.Mapper.Timeout == 0 {
+		c.Kafka.Mapper.Timeout = time.Second
+	}
+
+	// compacted topics keep the last record of every key, so the keys must be stable and deletions tombstones
+	if c.Kafka.CompactedTopics {
+		if c.Kafka.KeyStrategy.Type == "" {
+			c.Kafka.KeyStrategy.Type = KeyStrategyCollectionID
+		}
+		c.Kafka.Tombstone = true
+	}
+
+	if c.Kafka.KeyStrategy.Separator == "" {
+		c.Kafka.KeyStrategy.Separator = ":"
+	}
+
+	if c.Kafka.KeyStrategy.MinPartitions == 0 {
+		c.Kafka.KeyStrategy.MinPartitions = 1
+	}
+
+	if c.Kafka.BinaryDocuments.Encoding == "" {
+		c.Kafka.BinaryDocuments.Encoding = BinaryEncodingRaw
+	}
+
+	if c.Kafka.Chunking.MaxSize == 0 {
+		c.Kafka.Chunking.MaxSize = 900 * 1024
+	}
+
+	if c.Kafka.Xattrs.Timeout == 0 {
+		c.Kafka.Xattrs.Timeout = 5 * time.Second
+	}
+
+	if c.Kafka.ValueCompression.Codec == "" {
+		c.Kafka.ValueCompression.Codec = ValueCompressionGzip
+	}
+
+	if c.Kafka.Encryption.Type == "" {
+		c.Kafka.Encryption.Type = "aws"
+	}
+
+	if c.Kafka.Encryption.DataKeyTTL == 0 {
+		c.Kafka.Encryption.DataKeyTTL = 5 * time.Minute
+	}
+
+	if c.Kafka.Encryption.Timeout == 0 {
+		c.Kafka.Encryption.Timeout = 10 * time.Second
+	}
+
+	if c.Kafka.ClaimCheck.Type == "" {
+		c.Kafka.ClaimCheck.Type = "file"
+	}
+
+	if c.Kafka.ClaimCheck.Directory == "" {
+		c.Kafka.ClaimCheck.Directory = "claim-check"
+	}
+
+	if c.Kafka.ClaimCheck.Threshold == 0 {
+		c.Kafka.ClaimCheck.Threshold = 900 * 1024
+	}
+
+	if c.Kafka.ClaimCheck.Timeout == 0 {
+		c.Kafka.ClaimCheck.Timeout = 30 * time.Second
+	}
+}
+
+func (c *Connector) applyReliabilityDefaults() {
+	if c.Kafka.Pause.MaxHeldEvents == 0 {
+		c.Kafka.Pause.MaxHeldEvents = 100000
+	}
+
+	if c.Kafka.BufferLimit.Policy == "" {
+		c.Kafka.BufferLimit.Policy = BufferOverflowBlock
+	}
+
+	if c.Kafka.OutageQueue.ReplayInterval == 0 {
+		c.Kafka.OutageQueue.ReplayInterval = 5 * time.Second
+	}
+
+	if c.Kafka.SyncProduce.RetryInterval == 0 {
+		c.Kafka.SyncProduce.RetryInterval = 100 * time.Millisecond
+	}
+
+	if c.Kafka.Failover.Threshold == 0 {
+		c.Kafka.Failover.Threshold = time.Minute
+	}
+
+	if c.Kafka.Chaos.Delay == 0 {
+		c.Kafka.Chaos.Delay = 5 * time.Second
+	}
+
+	if c.Kafka.Dedup.Window == 0 {
+		c.Kafka.Dedup.Window = time.Minute
+	}
+
+	if c.Kafka.Dedup.MaxSize == 0 {
+		c.Kafka.Dedup.MaxSize = 100000
+	}
+
+	if c.Kafka.SeqNoDedup.SaveInterval == 0 {
+		c.Kafka.SeqNoDedup.SaveInterval = time.Second
+	}
+
+	if c.Kafka.Secrets.Timeout == 0 {
+		c.Kafka.Secrets.Timeout = 5 * time.Second
+	}
+
+	if c.Kafka.Secrets.RefreshInterval == 0 {
+		c.Kafka.Secrets.RefreshInterval = 5 * time.Minute
+	}
+
+	if c.Kafka.CredentialRotation.Interval == 0 {
+		c.Kafka.CredentialRotation.Interval = time.Minute
+	}
+
+	if c.Kafka.ActivePassive.LeaseDuration == 0 {
+		c.Kafka.ActivePassive.LeaseDuration = 15 * time.Second
+	}
+
+	if c.Kafka.ActivePassive.RetryPeriod == 0 {
+		c.Kafka.ActivePassive.RetryPeriod = 2 * time.Second
+	}
+}
+
+func (c *Connector) applyServiceDefaults() {
+	if c.Kafka.AdminAPI.Port == 0 {
+		c.Kafka.AdminAPI.Port = 8082
+	}
+
+	if c.Kafka.GRPCAPI.Port == 0 {
+		c.Kafka.GRPCAPI.Port = 8083
+	}
+
+	if c.Kafka.Debug.Port == 0 {
+		c.Kafka.Debug.Port = 8084
+	}
+
+	if c.Kafka.StateStore.Type == "" {
+		c.Kafka.StateStore.Type = "file"
+	}
+
+	if c.Kafka.StateStore.Directory == "" {
+		c.Kafka.StateStore.Directory = "state"
+	}
+
+	if c.Kafka.HotReload.Interval == 0 {
+		c.Kafka.HotReload.Interval = 10 * time.Second
+	}
+
+	if c.Kafka.ErrorLogSampling.Interval == 0 {
+		c.Kafka.ErrorLogSampling.Interval = time.Minute
+	}
+
+	if c.Kafka.ErrorLogSampling.Burst == 0 {
+		c.Kafka.ErrorLogSampling.Burst = 1
+	}
+}
+
+func (c *Connector) applyMetricDefaults() {
+	if len(c.Kafka.EndToEndLatencyBuckets) == 0 {
+		c.Kafka.EndToEndLatencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+	}
+
+	if c.Kafka.LagMetric.Interval == 0 {
+		c.Kafka.LagMetric.Interval = 10 * time.Second
+	}
+
+	if c.Kafka.Metrics.Sink == "" {
+		c.Kafka.Metrics.Sink = MetricsSinkPrometheus
+	}
+
+	if c.Kafka.Metrics.Address == "" {
+		c.Kafka.Metrics.Address = "localhost:8125"
+	}
+
+	if c.Kafka.WriterStats.Interval == 0 {
+		c.Kafka.WriterStats.Interval = 10 * time.Second
+	}
+
+	if c.Kafka.Metrics.Interval == 0 {
+		c.Kafka.Metrics.Interval = 10 * time.Second
+	}
+
+	if c.Kafka.CompressionStats.SampleRate == 0 {
+		c.Kafka.CompressionStats.SampleRate = 10
+	}
+}
+
+func (c *Connector) applyAdaptiveBatchDefaults() {
+	adaptive := &c.Kafka.AdaptiveBatch
+
+	if adaptive.TargetLatency == 0 {
+		adaptive.TargetLatency = time.Second
+	}
+
+	if adaptive.MinSize == 0 {
+		adaptive.MinSize = max(c.Kafka.ProducerBatchSize/10, 1)
+	}
+
+	if adaptive.MaxSize == 0 {
+		adaptive.MaxSize = c.Kafka.ProducerBatchSize * 10
+	}
+
+	if adaptive.MinTickerDuration == 0 {
+		adaptive.MinTickerDuration = min(time.Second, c.Kafka.ProducerBatchTickerDuration)
+	}
+
+	if adaptive.MaxTickerDuration == 0 {
+		adaptive.MaxTickerDuration = c.Kafka.ProducerBatchTickerDuration
+	}
+}
+
+func (c *Connector) applyEnrichmentDefaults() {
+	enrichment := &c.Kafka.Enrichment
+
+	if enrichment.BucketName == "" {
+		enrichment.BucketName = c.Dcp.BucketName
+	}
+
+	if enrichment.Timeout == 0 {
+		enrichment.Timeout = 5 * time.Second
+	}
+
+	if enrichment.Cache.Type == "" {
+		enrichment.Cache.Type = "memory"
+	}
+
+	if enrichment.Cache.TTL == 0 {
+		enrichment.Cache.TTL = time.Minute
+	}
+
+	if enrichment.Cache.NegativeTTL == 0 {
+		enrichment.Cache.NegativeTTL = enrichment.Cache.TTL
+	}
+
+	if enrichment.Cache.Redis.KeyPrefix == "" {
+		enrichment.Cache.Redis.KeyPrefix = "go-dcp-kafka:enrichment:"
+	}
+
+	if enrichment.Cache.MaxSize == 0 {
+		enrichment.Cache.MaxSize = 10000
+	}
+
+	for i := range enrichment.Lookups {
+		if enrichment.Lookups[i].ScopeName == "" {
+			enrichment.Lookups[i].ScopeName = config.DefaultScopeName
+		}
+		if enrichment.Lookups[i].CollectionName == "" {
+			enrichment.Lookups[i].CollectionName = config.DefaultCollectionName
+		}
+	}
+}
+
+func (c *Connector) applySchemaRegistryDefaults() {
+	schemaRegistry := &c.Kafka.SchemaRegistry
+
+	if schemaRegistry.Fallback == "" {
+		schemaRegistry.Fallback = SchemaRegistryFallbackFail
+	}
+
+	if schemaRegistry.CacheTTL == 0 {
+		schemaRegistry.CacheTTL = 5 * time.Minute
+	}
+
+	if schemaRegistry.Timeout == 0 {
+		schemaRegistry.Timeout = 5 * time.Second
+	}
+
+	if schemaRegistry.RetryInterval == 0 {
+		schemaRegistry.RetryInterval = 5 * time.Second
+	}
+
+	if schemaRegistry.SchemaType == "" {
+		schemaRegistry.SchemaType = SchemaTypeJSON
+	}
 }
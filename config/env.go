@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPattern matches ${NAME} and ${NAME:-default}.
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Unmarshal expands environment variables in every scalar value of the yaml before decoding it, so secrets and
+// brokers can differ per environment. A referenced variable that is not set and has no default fails the decoding.
+func Unmarshal(data []byte, c *Connector) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+
+	missing := map[string]struct{}{}
+	expandEnv(&root, missing)
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("config references unset environment variables: %s", strings.Join(names, ", "))
+	}
+
+	return root.Decode(c)
+}
+
+func expandEnv(node *yaml.Node, missing map[string]struct{}) {
+	if node.Kind == yaml.ScalarNode {
+		value := envPattern.ReplaceAllStringFunc(node.Value, func(reference string) string {
+			groups := envPattern.FindStringSubmatch(reference)
+			if value, ok := os.LookupEnv(groups[1]); ok {
+				return value
+			}
+			if groups[2] != "" {
+				return groups[3]
+			}
+			missing[groups[1]] = struct{}{}
+			return reference
+		})
+
+		// unquoted values are resolved again, so a variable can hold a number, a bool or a duration
+		if value != node.Value && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+			node.Tag = ""
+		}
+		node.Value = value
+		return
+	}
+
+	for _, child := range node.Content {
+		expandEnv(child, missing)
+	}
+}
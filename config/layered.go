@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultEnvPrefix is the prefix of the environment variables read by the environment layer,
+	// e.g. DCP_KAFKA__KAFKA__BROKERS=[broker1:9092,broker2:9092] or DCP_KAFKA__PASSWORD=secret.
+	DefaultEnvPrefix = "DCP_KAFKA__"
+	envPathSeparator = "__"
+)
+
+// Sources are merged with the precedence defaults < files < environment < overrides.
+type Sources struct {
+	// Files are merged in order, a later file wins over an earlier one. Maps are merged, lists are replaced.
+	Files []string
+	// Env holds environment variables as KEY=value, it defaults to os.Environ when nil.
+	Env []string
+	// EnvPrefix defaults to DefaultEnvPrefix, "-" disables the environment layer.
+	EnvPrefix string
+	// Overrides are applied in order after the environment.
+	Overrides []func(c *Connector)
+}
+
+// Load merges the sources into a connector config and applies the defaults for every value no source has set.
+func Load(sources Sources) (*Connector, error) {
+	var c Connector
+
+	for _, path := range sources.Files {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = Unmarshal(file, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(&c, sources.envPrefix(), sources.env()); err != nil {
+		return nil, err
+	}
+
+	for _, override := range sources.Overrides {
+		override(&c)
+	}
+
+	c.ApplyDefaults()
+	return &c, nil
+}
+
+func (s Sources) envPrefix() string {
+	if s.EnvPrefix == "" {
+		return DefaultEnvPrefix
+	}
+	return s.EnvPrefix
+}
+
+func (s Sources) env() []string {
+	if s.Env == nil {
+		return os.Environ()
+	}
+	return s.Env
+}
+
+// applyEnv sets the value of every prefixed variable to the yaml path of its name, the path is separated by "__"
+// and case-insensitive, e.g. KAFKA__PRODUCERBATCHSIZE. Values are decoded as yaml, so lists use the flow syntax.
+func applyEnv(c *Connector, prefix string, env []string) error {
+	if prefix == "-" {
+		return nil
+	}
+
+	env = append([]string(nil), env...)
+	sort.Strings(env)
+
+	var errs []error
+	for _, variable := range env {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		path := strings.Split(strings.TrimPrefix(name, prefix), envPathSeparator)
+		if err := setPath(reflect.ValueOf(c).Elem(), path, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setPath(v reflect.Value, path []string, value string) error {
+	if len(path) == 0 {
+		return yaml.Unmarshal([]byte(value), v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setPath(v.Elem(), path, value)
+	case reflect.Struct:
+		field, ok := fieldByYamlName(v, path[0])
+		if !ok {
+			return fmt.Errorf("unknown config key %q", path[0])
+		}
+		return setPath(field, path[1:], value)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key of %q", path[0])
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setPath(elem, path[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	default:
+		return fmt.Errorf("%s has no field %q", v.Type(), path[0])
+	}
+}
+
+// fieldByYamlName matches the name case-insensitively and looks into inline structs.
+func fieldByYamlName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		tag, options, _ := strings.Cut(structField.Tag.Get("yaml"), ",")
+		if tag == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			if field, ok := fieldByYamlName(v.Field(i), name); ok {
+				return field, true
+			}
+			continue
+		}
+		if tag == "" {
+			tag = structField.Name
+		}
+		if strings.EqualFold(tag, name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAppliesDefaultsForUnsetValues(t *testing.T) {
+	c, err := Load(Sources{
+		Files: []string{writeConfigFile(t, "kafka:\n  brokers: [broker:9092]\n")},
+		Env:   []string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Kafka.ProducerBatchSize != 2000 {
+		t.Errorf("expected default producerBatchSize 2000, got %d", c.Kafka.ProducerBatchSize)
+	}
+	if len(c.Kafka.Brokers) != 1 || c.Kafka.Brokers[0] != "broker:9092" {
+		t.Errorf("unexpected brokers %v", c.Kafka.Brokers)
+	}
+}
+
+func TestLoadLaterFileWins(t *testing.T) {
+	base := writeConfigFile(t, `
+hosts: [couchbase:8091]
+kafka:
+  brokers: [broker1:9092]
+  producerBatchSize: 100
+  collectionTopicMapping:
+    users: users-topic
+`)
+	overlay := writeConfigFile(t, `
+kafka:
+  producerBatchSize: 200
+  collectionTopicMapping:
+    orders: orders-topic
+`)
+
+	c, err := Load(Sources{Files: []string{base, overlay}, Env: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Kafka.ProducerBatchSize != 200 {
+		t.Errorf("expected producerBatchSize 200, got %d", c.Kafka.ProducerBatchSize)
+	}
+	if c.Kafka.Brokers[0] != "broker1:9092" || c.Dcp.Hosts[0] != "couchbase:8091" {
+		t.Errorf("expected values of the base file to be kept, got %v %v", c.Kafka.Brokers, c.Dcp.Hosts)
+	}
+	if c.Kafka.CollectionTopicMapping["users"] != "users-topic" || c.Kafka.CollectionTopicMapping["orders"] != "orders-topic" {
+		t.Errorf("expected maps to be merged, got %v", c.Kafka.CollectionTopicMapping)
+	}
+}
+
+func TestLoadEnvironmentWinsOverFile(t *testing.T) {
+	path := writeConfigFile(t, `
+password: from-file
+kafka:
+  brokers: [broker1:9092]
+  producerBatchSize: 100
+`)
+
+	c, err := Load(Sources{
+		Files: []string{path},
+		Env: []string{
+			"DCP_KAFKA__PASSWORD=from-env",
+			"DCP_KAFKA__KAFKA__BROKERS=[broker2:9092, broker3:9092]",
+			"DCP_KAFKA__KAFKA__PRODUCERBATCHSIZE=300",
+			"DCP_KAFKA__KAFKA__PRODUCERBATCHTICKERDURATION=5s",
+			"DCP_KAFKA__KAFKA__COLLECTIONTOPICMAPPING__users=users-topic",
+			"UNRELATED=value",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Dcp.Password != "from-env" {
+		t.Errorf("expected password from env, got %s", c.Dcp.Password)
+	}
+	if len(c.Kafka.Brokers) != 2 || c.Kafka.Brokers[1] != "broker3:9092" {
+		t.Errorf("unexpected brokers %v", c.Kafka.Brokers)
+	}
+	if c.Kafka.ProducerBatchSize != 300 {
+		t.Errorf("expected producerBatchSize 300, got %d", c.Kafka.ProducerBatchSize)
+	}
+	if c.Kafka.ProducerBatchTickerDuration != 5*time.Second {
+		t.Errorf("expected producerBatchTickerDuration 5s, got %v", c.Kafka.ProducerBatchTickerDuration)
+	}
+	if c.Kafka.CollectionTopicMapping["users"] != "users-topic" {
+		t.Errorf("unexpected collectionTopicMapping %v", c.Kafka.CollectionTopicMapping)
+	}
+}
+
+func TestLoadOverridesWinOverEnvironment(t *testing.T) {
+	c, err := Load(Sources{
+		Env: []string{"DCP_KAFKA__KAFKA__PRODUCERBATCHSIZE=300"},
+		Overrides: []func(c *Connector){
+			func(c *Connector) { c.Kafka.ProducerBatchSize = 400 },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Kafka.ProducerBatchSize != 400 {
+		t.Errorf("expected producerBatchSize 400, got %d", c.Kafka.ProducerBatchSize)
+	}
+}
+
+func TestLoadCustomAndDisabledEnvPrefix(t *testing.T) {
+	env := []string{"APP_KAFKA__PRODUCERBATCHSIZE=300", "DCP_KAFKA__KAFKA__PRODUCERBATCHSIZE=500"}
+
+	c, err := Load(Sources{Env: env, EnvPrefix: "APP_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Kafka.ProducerBatchSize != 300 {
+		t.Errorf("expected producerBatchSize 300, got %d", c.Kafka.ProducerBatchSize)
+	}
+
+	c, err = Load(Sources{Env: env, EnvPrefix: "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Kafka.ProducerBatchSize != 2000 {
+		t.Errorf("expected default producerBatchSize 2000, got %d", c.Kafka.ProducerBatchSize)
+	}
+}
+
+func TestLoadRejectsUnknownEnvironmentKeys(t *testing.T) {
+	_, err := Load(Sources{Env: []string{"DCP_KAFKA__KAFKA__PRODUCERBATCHSIZ=300"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+}
+
+func TestLoadRejectsInvalidEnvironmentValues(t *testing.T) {
+	_, err := Load(Sources{Env: []string{"DCP_KAFKA__KAFKA__PRODUCERBATCHSIZE=many"}})
+	if err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+const redacted = "<redacted>"
+
+// Redacted returns a copy with the credentials replaced, e.g. to expose the effective config over an admin api.
+// The secret names of kafka.secrets are kept.
+func (c *Connector) Redacted() Connector {
+	copied := *c
+
+	for _, value := range []*string{
+		&copied.Dcp.Password,
+		&copied.Kafka.ScramPassword,
+		&copied.Kafka.ClientKeyPassphrase,
+		&copied.Kafka.Secrets.VaultToken,
+		&copied.Kafka.SchemaRegistry.Password,
+		&copied.Kafka.SchemaRegistry.BearerToken,
+		&copied.Kafka.Enrichment.Cache.Redis.Password,
+	} {
+		if *value != "" {
+			*value = redacted
+		}
+	}
+
+	copied.Kafka.Transforms = append([]Transform(nil), c.Kafka.Transforms...)
+	for i := range copied.Kafka.Transforms {
+		if copied.Kafka.Transforms[i].Key != "" {
+			copied.Kafka.Transforms[i].Key = redacted
+		}
+	}
+	return copied
+}
@@ -0,0 +1,529 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+)
+
+// Validate checks the config after ApplyDefaults and returns every problem at once, so a misconfigured connector
+// fails at startup instead of panicking while streaming.
+func (c *Connector) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	c.validateProducer(invalid)
+	c.validateTopics(invalid)
+	c.validateModes(invalid)
+	c.validateFeatures(invalid)
+
+	return errors.Join(errs...)
+}
+
+func (c *Connector) validateProducer(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if len(k.Brokers) == 0 {
+		invalid("kafka.brokers must not be empty")
+	}
+	for i, broker := range k.Brokers {
+		if broker == "" {
+			invalid("kafka.brokers[%d] must not be empty", i)
+		}
+	}
+
+	if k.ProducerBatchSize <= 0 {
+		invalid("kafka.producerBatchSize must be positive")
+	}
+	if k.ProducerBatchBytes <= 0 {
+		invalid("kafka.producerBatchBytes must be positive")
+	}
+	if k.Dialer.LocalAddress != "" && net.ParseIP(k.Dialer.LocalAddress) == nil {
+		invalid("kafka.dialer.localAddress %q must be an IP address", k.Dialer.LocalAddress)
+	}
+	if _, _, err := net.SplitHostPort(k.Dialer.Resolver); k.Dialer.Resolver != "" && err != nil {
+		invalid("kafka.dialer.resolver %q must be a host:port address", k.Dialer.Resolver)
+	}
+	if k.Dialer.Proxy != "" {
+		if u, err := url.Parse(k.Dialer.Proxy); err != nil || (u.Scheme != "socks5" && u.Scheme != "socks5h" && u.Scheme != "http") {
+			invalid("kafka.dialer.proxy must be a socks5:// or http:// URL")
+		}
+	}
+	if k.Dialer.Timeout < 0 {
+		invalid("kafka.dialer.timeout must not be negative")
+	}
+	if k.ProducerRequestBytes <= 0 {
+		invalid("kafka.producerRequestBytes must be positive")
+	}
+	if k.Chunking.Enabled && int64(k.Chunking.MaxSize) > k.ProducerRequestBytes {
+		invalid("kafka.chunking.maxSize must not exceed kafka.producerRequestBytes")
+	}
+	if k.ProducerBatchTickerDuration <= 0 {
+		invalid("kafka.producerBatchTickerDuration must be positive")
+	}
+	if k.ProducerFlushParallelism < 0 {
+		invalid("kafka.producerFlushParallelism must not be negative")
+	}
+	if k.CheckpointCommitInterval < 0 || k.CheckpointCommitEveryFlushes < 0 {
+		invalid("kafka.checkpointCommitInterval and kafka.checkpointCommitEveryFlushes must not be negative")
+	}
+	if k.ProducerMaxInFlightFlushes < 0 {
+		invalid("kafka.producerMaxInFlightFlushes must not be negative")
+	}
+}
+
+func (c *Connector) validateTopics(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	for collection, topic := range k.CollectionTopicMapping {
+		if collection == "" || topic == "" {
+			invalid("kafka.collectionTopicMapping must not have empty collections or topics, %q: %q", collection, topic)
+		}
+	}
+
+	if k.SecureConnection {
+		for name, path := range map[string]string{"kafka.rootCAPath": k.RootCAPath, "kafka.interCAPath": k.InterCAPath} {
+			if _, err := os.Stat(os.ExpandEnv(path)); err != nil {
+				invalid("%s must be a readable file for kafka.secureConnection: %v", name, err)
+			}
+		}
+	}
+
+	if k.Compression < 0 || k.Compression > 4 {
+		invalid("kafka.compression must be between 0 and 4")
+	}
+
+	for topic, override := range k.TopicOverrides {
+		if override.Compression != nil && (*override.Compression < 0 || *override.Compression > 4) {
+			invalid("kafka.topicOverrides.%s.compression must be between 0 and 4", topic)
+		}
+		if override.RequiredAcks != nil && (*override.RequiredAcks < -1 || *override.RequiredAcks > 1) {
+			invalid("kafka.topicOverrides.%s.requiredAcks must be -1, 0 or 1", topic)
+		}
+		if override.ProducerBatchSize < 0 {
+			invalid("kafka.topicOverrides.%s.producerBatchSize must not be negative", topic)
+		}
+	}
+}
+
+func (c *Connector) validateModes(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if k.AckMode != "" && k.AckMode != AckModeEnqueue && k.AckMode != AckModeFlush {
+		invalid("kafka.ackMode must be %s or %s", AckModeEnqueue, AckModeFlush)
+	}
+	switch k.DeliverySemantics {
+	case "":
+	case DeliveryAtLeastOnce:
+		if k.AckMode != AckModeFlush {
+			invalid("kafka.deliverySemantics %s requires kafka.ackMode %s", DeliveryAtLeastOnce, AckModeFlush)
+		}
+	case DeliveryAtMostOnce:
+		if k.AckMode == AckModeFlush || k.ProducerMaxAttempts != 1 {
+			invalid("kafka.deliverySemantics %s requires kafka.ackMode %s and kafka.producerMaxAttempts 1",
+				DeliveryAtMostOnce, AckModeEnqueue)
+		}
+	default:
+		invalid("kafka.deliverySemantics must be %s or %s", DeliveryAtLeastOnce, DeliveryAtMostOnce)
+	}
+	if k.ProducerMaxInFlightFlushes > 1 && k.AckMode != AckModeFlush {
+		invalid("kafka.producerMaxInFlightFlushes above 1 requires kafka.ackMode %s", AckModeFlush)
+	}
+	if k.SeqNoDedup.Enabled && k.AckMode != AckModeFlush {
+		invalid("kafka.seqNoDedup requires kafka.ackMode %s, the seqnos are recorded once written", AckModeFlush)
+	}
+	if k.SeqNoDedup.Enabled && k.SeqNoDedup.SaveInterval <= 0 {
+		invalid("kafka.seqNoDedup.saveInterval must be positive")
+	}
+	if k.SyncProduce.Enabled && k.ProducerMaxInFlightFlushes > 1 {
+		invalid("kafka.syncProduce and kafka.producerMaxInFlightFlushes above 1 are mutually exclusive")
+	}
+	if k.ShadowMode && k.Mirror.Enabled {
+		invalid("kafka.shadowMode and kafka.mirror are mutually exclusive")
+	}
+
+	if k.RebalanceBuffer != "" && k.RebalanceBuffer != RebalanceBufferDrop && k.RebalanceBuffer != RebalanceBufferFlush {
+		invalid("kafka.rebalanceBuffer must be %s or %s", RebalanceBufferDrop, RebalanceBufferFlush)
+	}
+
+	for _, eventType := range k.EventTypes {
+		if eventType != "mutation" && eventType != "deletion" && eventType != "expiration" {
+			invalid("kafka.eventTypes %q is invalid", eventType)
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, k.KeyFilter.Include...), k.KeyFilter.Exclude...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			invalid("kafka.keyFilter pattern %q is invalid: %v", pattern, err)
+		}
+	}
+
+	switch k.MessageTimestamp {
+	case "", MessageTimestampProduce, MessageTimestampEvent:
+	default:
+		invalid("kafka.messageTimestamp %q is invalid", k.MessageTimestamp)
+	}
+
+	c.validatePolicies(invalid)
+	c.validateMapping(invalid)
+	c.validateBuffering(invalid)
+}
+
+func (c *Connector) validatePolicies(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	switch k.ExpirationPolicy {
+	case "", ExpirationPolicyMapper, ExpirationPolicyTombstone, ExpirationPolicyEnvelope, ExpirationPolicyDrop:
+	default:
+		invalid("kafka.expirationPolicy %q is invalid", k.ExpirationPolicy)
+	}
+
+	switch k.JSONComplexityLimit.Policy {
+	case "", ComplexityPolicySkip, ComplexityPolicyTruncate:
+	case ComplexityPolicyDeadLetter:
+		if k.DeadLetterTopic == "" {
+			invalid("kafka.deadLetterTopic must be set for the %s json complexity policy", ComplexityPolicyDeadLetter)
+		}
+	default:
+		invalid("kafka.jsonComplexityLimit.policy %q is invalid", k.JSONComplexityLimit.Policy)
+	}
+
+	if k.DocumentSizeLimit.MaxSize < 0 {
+		invalid("kafka.documentSizeLimit.maxSize must not be negative")
+	}
+	switch k.DocumentSizeLimit.Policy {
+	case "", DocumentSizePolicySkip:
+	case DocumentSizePolicyDeadLetter:
+		if k.DeadLetterTopic == "" {
+			invalid("kafka.deadLetterTopic must be set for the %s document size policy", DocumentSizePolicyDeadLetter)
+		}
+	case DocumentSizePolicyClaimCheck:
+		if !k.ClaimCheck.Enabled || k.ClaimCheck.Threshold >= k.DocumentSizeLimit.MaxSize {
+			invalid("kafka.claimCheck must be enabled with a threshold below kafka.documentSizeLimit.maxSize for the %s document size policy",
+				DocumentSizePolicyClaimCheck)
+		}
+	default:
+		invalid("kafka.documentSizeLimit.policy %q is invalid", k.DocumentSizeLimit.Policy)
+	}
+
+	switch k.ProcessingErrorPolicy {
+	case "", ProcessingErrorPolicySkip:
+	case ProcessingErrorPolicyDeadLetter:
+		if k.DeadLetterTopic == "" {
+			invalid("kafka.deadLetterTopic must be set for the %s processing error policy", ProcessingErrorPolicyDeadLetter)
+		}
+	default:
+		invalid("kafka.processingErrorPolicy %q is invalid", k.ProcessingErrorPolicy)
+	}
+
+	switch k.Metrics.Sink {
+	case MetricsSinkPrometheus, MetricsSinkStatsD, MetricsSinkDatadog:
+	default:
+		invalid("kafka.metrics.sink %q is invalid", k.Metrics.Sink)
+	}
+	if k.Metrics.Interval <= 0 {
+		invalid("kafka.metrics.interval must be positive")
+	}
+}
+
+func (c *Connector) validateMapping(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	switch k.Mapper.Type {
+	case "", MapperTypeJSONPath:
+	case MapperTypeWASM, MapperTypeJavaScript:
+		if _, err := os.Stat(k.Mapper.Path); err != nil {
+			invalid("kafka.mapper.path must be a readable file for the %s mapper: %v", k.Mapper.Type, err)
+		}
+	default:
+		invalid("kafka.mapper.type %q is invalid", k.Mapper.Type)
+	}
+	if k.Mapper.Timeout < 0 {
+		invalid("kafka.mapper.timeout must not be negative")
+	}
+
+	switch k.KeyStrategy.Type {
+	case "", KeyStrategyID, KeyStrategyNone, KeyStrategyCollectionID:
+	case KeyStrategyField:
+		if k.KeyStrategy.Field == "" {
+			invalid("kafka.keyStrategy.field must be set for the %s key strategy", KeyStrategyField)
+		}
+		// a deletion has no document to read the field from, its tombstone would not replace the keyed records
+		if k.Tombstone || k.ExpirationPolicy == ExpirationPolicyTombstone {
+			invalid("kafka.tombstone and the %s expiration policy cannot be used with the %s key strategy",
+				ExpirationPolicyTombstone, KeyStrategyField)
+		}
+	default:
+		invalid("kafka.keyStrategy.type %q is invalid", k.KeyStrategy.Type)
+	}
+	if k.TombstoneDelay < 0 {
+		invalid("kafka.tombstoneDelay must not be negative")
+	}
+	if k.TombstoneDelay > 0 && !k.Tombstone {
+		invalid("kafka.tombstone must be enabled for kafka.tombstoneDelay")
+	}
+	if k.CompactedTopics {
+		if k.KeyStrategy.Type != KeyStrategyCollectionID {
+			invalid("kafka.keyStrategy.type must be %s for kafka.compactedTopics", KeyStrategyCollectionID)
+		}
+		if policy := k.ExpirationPolicy; policy != "" && policy != ExpirationPolicyMapper && policy != ExpirationPolicyTombstone {
+			invalid("kafka.expirationPolicy must be %s or %s for kafka.compactedTopics", ExpirationPolicyMapper, ExpirationPolicyTombstone)
+		}
+	}
+	if k.KeyStrategy.MinPartitions < 0 {
+		invalid("kafka.keyStrategy.minPartitions must not be negative")
+	}
+
+	if k.BinaryDocuments.Enabled {
+		switch k.BinaryDocuments.Encoding {
+		case BinaryEncodingRaw, BinaryEncodingBase64Envelope, BinaryEncodingSkip:
+		default:
+			invalid("kafka.binaryDocuments.encoding %q is invalid", k.BinaryDocuments.Encoding)
+		}
+	}
+}
+
+func (c *Connector) validateBuffering(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if adaptive := k.AdaptiveBatch; adaptive.Enabled {
+		if adaptive.TargetLatency <= 0 {
+			invalid("kafka.adaptiveBatch.targetLatency must be positive")
+		}
+		if adaptive.MinSize <= 0 || adaptive.MinSize > adaptive.MaxSize {
+			invalid("kafka.adaptiveBatch.minSize must be positive and not above kafka.adaptiveBatch.maxSize")
+		}
+		if adaptive.MinTickerDuration <= 0 || adaptive.MinTickerDuration > adaptive.MaxTickerDuration {
+			invalid("kafka.adaptiveBatch.minTickerDuration must be positive and not above kafka.adaptiveBatch.maxTickerDuration")
+		}
+	}
+	if k.BufferLimit.MaxBytes < 0 {
+		invalid("kafka.bufferLimit.maxBytes must not be negative")
+	}
+	switch k.BufferLimit.Policy {
+	case "", BufferOverflowBlock, BufferOverflowDropOldest:
+	case BufferOverflowSpill:
+		if k.BufferLimit.SpillDirectory == "" {
+			invalid("kafka.bufferLimit.spillDirectory must be set for the %s buffer overflow policy", BufferOverflowSpill)
+		}
+	default:
+		invalid("kafka.bufferLimit.policy %q is invalid", k.BufferLimit.Policy)
+	}
+	if k.OutageQueue.Enabled {
+		if k.OutageQueue.Path == "" {
+			invalid("kafka.outageQueue.path must be set")
+		}
+		if k.OutageQueue.ReplayInterval <= 0 {
+			invalid("kafka.outageQueue.replayInterval must be positive")
+		}
+		if k.DeliverySemantics == DeliveryAtMostOnce {
+			invalid("kafka.outageQueue cannot be enabled with %s delivery", DeliveryAtMostOnce)
+		}
+	}
+	if k.LatencyBudget.MaxStaleness < 0 {
+		invalid("kafka.latencyBudget.maxStaleness must not be negative")
+	}
+
+	switch k.StateStore.Type {
+	case "file", "memory", "couchbase":
+	default:
+		invalid("kafka.stateStore.type %q is invalid", k.StateStore.Type)
+	}
+	if k.Pause.MaxHeldEvents < 0 {
+		invalid("kafka.pause.maxHeldEvents must not be negative")
+	}
+}
+
+func (c *Connector) validateFeatures(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if k.Migration.Enabled && (k.Migration.Percentage < 0 || k.Migration.Percentage > 100) {
+		invalid("kafka.migration.percentage must be between 0 and 100")
+	}
+	if k.Mirror.Enabled && len(k.Mirror.Brokers) == 0 {
+		invalid("kafka.mirror.brokers must be set when mirroring is enabled")
+	}
+	if k.Failover.Enabled && len(k.Failover.Brokers) == 0 {
+		invalid("kafka.failover.brokers must be set when failover is enabled")
+	}
+	if k.Chaos.Enabled {
+		if k.Chaos.DelayPercentage < 0 || k.Chaos.DelayPercentage > 100 ||
+			k.Chaos.ErrorPercentage < 0 || k.Chaos.ErrorPercentage > 100 {
+			invalid("kafka.chaos percentages must be between 0 and 100")
+		}
+	}
+
+	if k.Enrichment.Enabled {
+		if cacheType := k.Enrichment.Cache.Type; cacheType != "memory" && cacheType != "redis" {
+			invalid("kafka.enrichment.cache.type %q is invalid", cacheType)
+		}
+	}
+
+	if k.Xattrs.Enabled {
+		if len(k.Xattrs.Names) == 0 {
+			invalid("kafka.xattrs.names must be set")
+		}
+		for _, header := range k.Xattrs.Headers {
+			if !slices.Contains(k.Xattrs.Names, header) {
+				invalid("kafka.xattrs.headers %q must be in kafka.xattrs.names", header)
+			}
+		}
+	}
+
+	c.validateSchemaRegistry(invalid)
+	c.validateValueStages(invalid)
+	c.validateStreaming(invalid)
+	c.validateEndpoints(invalid)
+}
+
+func (c *Connector) validateSchemaRegistry(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if k.SchemaRegistry.Enabled {
+		if k.SchemaRegistry.URL == "" {
+			invalid("kafka.schemaRegistry.url must be set when the schema registry is enabled")
+		}
+		if k.SchemaRegistry.BearerToken != "" && k.SchemaRegistry.Username != "" {
+			invalid("kafka.schemaRegistry.bearerToken and kafka.schemaRegistry.username are mutually exclusive")
+		}
+		if k.SchemaRegistry.CAPath != "" {
+			if _, err := os.Stat(os.ExpandEnv(k.SchemaRegistry.CAPath)); err != nil {
+				invalid("kafka.schemaRegistry.caPath must be a readable file: %v", err)
+			}
+		}
+		switch k.SchemaRegistry.Fallback {
+		case SchemaRegistryFallbackFail, SchemaRegistryFallbackCache, SchemaRegistryFallbackRawJSON, SchemaRegistryFallbackPause:
+		default:
+			invalid("kafka.schemaRegistry.fallback %q is invalid", k.SchemaRegistry.Fallback)
+		}
+		switch k.SchemaRegistry.SchemaType {
+		case SchemaTypeJSON:
+		case SchemaTypeAvro, SchemaTypeProtobuf:
+			invalid("kafka.schemaRegistry.schemaType %s is not supported, the values are framed as they are, without encoding them",
+				k.SchemaRegistry.SchemaType)
+		default:
+			invalid("kafka.schemaRegistry.schemaType %q is invalid", k.SchemaRegistry.SchemaType)
+		}
+		for topic, path := range k.SchemaRegistry.Schemas {
+			if _, err := os.Stat(os.ExpandEnv(path)); err != nil {
+				invalid("kafka.schemaRegistry.schemas of topic %s must be a readable file: %v", topic, err)
+			}
+		}
+	}
+}
+
+func (c *Connector) validateValueStages(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if k.Chunking.Enabled && k.Chunking.MaxSize <= 0 {
+		invalid("kafka.chunking.maxSize must be positive")
+	}
+	if k.ValueCompression.Enabled {
+		switch k.ValueCompression.Codec {
+		case ValueCompressionGzip, ValueCompressionZstd:
+		default:
+			invalid("kafka.valueCompression.codec %q is invalid", k.ValueCompression.Codec)
+		}
+		if k.ValueCompression.MinSize < 0 {
+			invalid("kafka.valueCompression.minSize must not be negative")
+		}
+	}
+	if k.Sampling.Enabled {
+		if (k.Sampling.Percentage > 0) == (k.Sampling.KeyOneIn > 0) {
+			invalid("exactly one of kafka.sampling.percentage and kafka.sampling.keyOneIn must be set")
+		}
+		if k.Sampling.Percentage < 0 || k.Sampling.Percentage > 100 || k.Sampling.KeyOneIn < 0 {
+			invalid("kafka.sampling.percentage must be between 0 and 100 and kafka.sampling.keyOneIn must be positive")
+		}
+	}
+	if k.Encryption.Enabled {
+		if k.Encryption.Type != "aws" {
+			invalid("kafka.encryption.type %q is invalid", k.Encryption.Type)
+		}
+		if k.Encryption.DataKeyTTL < 0 || k.Encryption.Timeout < 0 {
+			invalid("kafka.encryption.dataKeyTTL and kafka.encryption.timeout must not be negative")
+		}
+	}
+	if k.ClaimCheck.Enabled && k.ClaimCheck.Threshold <= 0 {
+		invalid("kafka.claimCheck.threshold must be positive")
+	}
+	if k.Chunking.Enabled && k.ClaimCheck.Enabled && k.ClaimCheck.Threshold <= k.Chunking.MaxSize {
+		invalid("kafka.claimCheck.threshold must be above kafka.chunking.maxSize, otherwise no message is chunked")
+	}
+}
+
+func (c *Connector) validateStreaming(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if !k.StartFrom.Timestamp.IsZero() && len(k.StartFrom.SeqNos) > 0 {
+		invalid("kafka.startFrom.timestamp and kafka.startFrom.seqNos are mutually exclusive")
+	}
+
+	if _, err := parseVBuckets(k.VBuckets); err != nil {
+		invalid("kafka.vBuckets: %v", err)
+	}
+
+	if k.ActivePassive.Enabled {
+		switch k.ActivePassive.Type {
+		case "couchbase":
+		case "kubernetes":
+			if k.ActivePassive.Config["leaseLockName"] == "" || k.ActivePassive.Config["leaseLockNamespace"] == "" {
+				invalid("kafka.activePassive.config must have leaseLockName and leaseLockNamespace for the kubernetes type")
+			}
+		default:
+			invalid("kafka.activePassive.type %q is invalid", k.ActivePassive.Type)
+		}
+		if k.ActivePassive.RetryPeriod <= 0 || k.ActivePassive.RetryPeriod*2 >= k.ActivePassive.LeaseDuration {
+			invalid("kafka.activePassive.retryPeriod must be positive and below half of kafka.activePassive.leaseDuration")
+		}
+	}
+}
+
+func (c *Connector) validateEndpoints(invalid func(format string, args ...any)) {
+	k := &c.Kafka
+
+	if k.AdminAPI.Enabled && (k.AdminAPI.Port <= 0 || k.AdminAPI.Port > 65535) {
+		invalid("kafka.adminAPI.port must be a valid port")
+	}
+	if k.GRPCAPI.Enabled && (k.GRPCAPI.Port <= 0 || k.GRPCAPI.Port > 65535) {
+		invalid("kafka.grpcAPI.port must be a valid port")
+	}
+	if k.TopicCreation.Partitions < 0 || k.TopicCreation.ReplicationFactor < 0 || k.TopicCreation.Retention < 0 {
+		invalid("kafka.topicCreation partitions, replicationFactor and retention must not be negative")
+	}
+	if k.TopicCreation.Partitions > 0 && k.KeyStrategy.Type != KeyStrategyNone && k.TopicCreation.Partitions < k.KeyStrategy.MinPartitions {
+		invalid("kafka.topicCreation.partitions must not be less than kafka.keyStrategy.minPartitions")
+	}
+	if k.Debug.Enabled && (k.Debug.Port <= 0 || k.Debug.Port > 65535) {
+		invalid("kafka.debug.port must be a valid port")
+	}
+
+	switch k.Secrets.Type {
+	case "":
+	case "file":
+		if k.Secrets.Directory == "" {
+			invalid("kafka.secrets.directory must be set for the file secret provider")
+		}
+	case "vault":
+		if k.Secrets.VaultAddress == "" || k.Secrets.VaultPath == "" {
+			invalid("kafka.secrets.vaultAddress and kafka.secrets.vaultPath must be set for the vault secret provider")
+		}
+	default:
+		invalid("kafka.secrets.type %q is invalid", k.Secrets.Type)
+	}
+	if k.Secrets.Type != "" && !k.SecureConnection {
+		invalid("kafka.secrets requires kafka.secureConnection, credentials are only used for secure connections")
+	}
+	if k.CredentialRotation.Enabled && !k.SecureConnection {
+		invalid("kafka.credentialRotation requires kafka.secureConnection")
+	}
+	if (k.ClientCertPath == "") != (k.ClientKeyPath == "") {
+		invalid("kafka.clientCertPath and kafka.clientKeyPath must be set together")
+	}
+}
@@ -3,23 +3,45 @@ package dcpkafka
 import (
 	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 
 	"github.com/Trendyol/go-dcp"
 
+	"github.com/Trendyol/go-dcp-kafka/api"
+	"github.com/Trendyol/go-dcp-kafka/claimcheck"
 	"github.com/Trendyol/go-dcp-kafka/config"
 	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/debugapi"
+	"github.com/Trendyol/go-dcp-kafka/dedup"
+	"github.com/Trendyol/go-dcp-kafka/encryption"
+	"github.com/Trendyol/go-dcp-kafka/enrichment"
+	"github.com/Trendyol/go-dcp-kafka/filter"
+	"github.com/Trendyol/go-dcp-kafka/grpcapi"
 	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/kafka/message"
 	"github.com/Trendyol/go-dcp-kafka/kafka/metadata"
 	"github.com/Trendyol/go-dcp-kafka/kafka/producer"
+	"github.com/Trendyol/go-dcp-kafka/lag"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp-kafka/mapping"
 	"github.com/Trendyol/go-dcp-kafka/metric"
+	"github.com/Trendyol/go-dcp-kafka/pause"
+	"github.com/Trendyol/go-dcp-kafka/report"
+	"github.com/Trendyol/go-dcp-kafka/schemaregistry"
+	"github.com/Trendyol/go-dcp-kafka/secret"
+	"github.com/Trendyol/go-dcp-kafka/state"
+	"github.com/Trendyol/go-dcp-kafka/toggle"
+	"github.com/Trendyol/go-dcp-kafka/transform"
 	dcpConfig "github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
 	"github.com/Trendyol/go-dcp/logger"
 	"github.com/Trendyol/go-dcp/models"
 	sKafka "github.com/segmentio/kafka-go"
-	"gopkg.in/yaml.v3"
 )
 
 var MetadataTypeKafka = "kafka"
@@ -27,16 +49,97 @@ var MetadataTypeKafka = "kafka"
 type Connector interface {
 	Start()
 	Close()
+	// Pause holds events of the given vBuckets and key prefixes without acknowledging them, like the /pause endpoint.
+	Pause(s pause.State) error
+	// Resume replays the held events of the given vBuckets and key prefixes in order.
+	Resume(s pause.State) error
+	PauseState() pause.State
+	Membership() Membership
+	// DisableCollections acknowledges the events of the given collections without producing them, like the /collections endpoint.
+	DisableCollections(names ...string) error
+	EnableCollections(names ...string) error
+	// ShutdownReport returns the final drain report after Close, its ExitCode is meant for os.Exit.
+	ShutdownReport() *report.Shutdown
 }
 
 type connector struct {
-	dcp      dcp.Dcp
-	mapper   Mapper
-	producer producer.Producer
-	config   *config.Connector
+	dcp              dcp.Dcp
+	api              api.API
+	grpcAPI          *grpcapi.Server
+	debug            *debugapi.Server
+	mapper           Mapper
+	configMapper     mapping.Mapper
+	producer         producer.Producer
+	sink             producer.Sink
+	pauser           *pause.Pauser
+	collections      *toggle.Collections
+	lag              *lag.Tracker
+	metricPusher     *metric.Pusher
+	hotReload        *hotReload
+	rotation         *credentialRotation
+	enricher         *enrichment.Enricher
+	xattrs           *xattrReader
+	tombstones       *tombstoneDelayer
+	keyOf            keyStrategy
+	timestampOf      TimestampExtractor
+	collectionFilter *collectionFilter
+	keyFilter        *keyFilter
+	rollback         *rollbackDetector
+	activePassive    *activePassive
+	startFrom        *startFromMetadata
+	replay           *replayWindow
+	replays          *replayRunner
+	replayBuilder    ConnectorBuilder
+	vBuckets         map[uint16]struct{}
+	metadataClient   dcpCouchbase.Client
+	headers          headerTemplates
+	valueTemplate    *valueTemplate
+	filter           *filter.Expression
+	transforms       transform.Chain
+	dedup            dedup.Cache
+	seqNoDedup       *seqNoDedup
+	claimCheck       *claimcheck.ClaimCheck
+	eventHandler     *DcpEventHandler
+	serializer       *schemaregistry.Serializer
+	valueCodec       sKafka.CompressionCodec
+	encryptor        *encryptor
+	startupReport    *report.Startup
+	shutdownReport   *report.Shutdown
+	config           *config.Connector
+	closing          chan struct{}
 }
 
 func (c *connector) Start() {
+	if c.api != nil {
+		go c.api.Listen()
+	}
+	if c.grpcAPI != nil {
+		go c.grpcAPI.Listen()
+	}
+	if c.debug != nil {
+		go c.debug.Listen()
+	}
+	if c.activePassive != nil {
+		c.activePassive.start(func() { go c.Close() })
+		if !c.activePassive.waitForLeadership() {
+			return
+		}
+	}
+	if c.lag != nil {
+		c.lag.Start()
+	}
+	if c.seqNoDedup != nil {
+		c.seqNoDedup.start()
+	}
+	if c.metricPusher != nil {
+		c.metricPusher.Start()
+	}
+	if c.hotReload != nil {
+		c.hotReload.Start(c.config.Kafka.HotReload.Interval)
+	}
+	if c.rotation != nil {
+		c.rotation.Start(c.config.Kafka.CredentialRotation.Interval)
+	}
 	go func() {
 		<-c.dcp.WaitUntilReady()
 		c.producer.StartBatch()
@@ -45,43 +148,292 @@ func (c *connector) Start() {
 }
 
 func (c *connector) Close() {
+	if c.activePassive != nil && c.activePassive.standby() {
+		c.closeStandby()
+		return
+	}
+
+	started := time.Now()
+	close(c.closing)
+	if c.pauser != nil {
+		c.pauser.Close()
+	}
 	c.dcp.Close()
-	err := c.producer.Close()
+	if c.tombstones != nil {
+		c.tombstones.Close()
+	}
+	err := c.drain()
 	if err != nil {
 		logger.Log.Error("error | %v", err)
 	}
+	c.writeShutdownReport(started, err)
+	if c.metricPusher != nil {
+		c.metricPusher.Close()
+	}
+	if c.enricher != nil {
+		c.enricher.Close()
+	}
+	if c.xattrs != nil {
+		c.xattrs.Close()
+	}
+	if c.dedup != nil {
+		c.dedup.Close()
+	}
+	if c.seqNoDedup != nil {
+		c.seqNoDedup.close()
+	}
+	if c.configMapper != nil {
+		c.configMapper.Close()
+	}
+	if c.lag != nil {
+		c.lag.Close()
+	}
+	if c.hotReload != nil {
+		c.hotReload.Close()
+	}
+	if c.rotation != nil {
+		c.rotation.Close()
+	}
+	c.closeMetadataClient()
+	c.closeAPIs()
+	if c.activePassive != nil {
+		c.activePassive.close()
+	}
+}
+
+// closeStandby closes a connector which has not started streaming, there is nothing to drain.
+func (c *connector) closeStandby() {
+	c.activePassive.close()
+	if c.metricPusher != nil {
+		c.metricPusher.Close()
+	}
+	if c.configMapper != nil {
+		c.configMapper.Close()
+	}
+	c.closeMetadataClient()
+	c.closeAPIs()
+}
+
+func (c *connector) closeAPIs() {
+	if c.api != nil {
+		c.api.Shutdown()
+		c.replays.close()
+	}
+	if c.grpcAPI != nil {
+		c.grpcAPI.Shutdown(c.config.Kafka.WriteTimeout)
+	}
+	if c.debug != nil {
+		c.debug.Shutdown()
+	}
 }
 
 func (c *connector) produce(ctx *models.ListenerContext) {
+	if c.lag != nil {
+		c.trackAck(ctx)
+	}
+	if c.seqNoDedup != nil {
+		c.trackWritten(ctx)
+	}
+	if c.tombstones != nil {
+		c.holdAck(ctx)
+	}
+	ctx.Ack = c.producer.ProducerBatch.DeferAck(ctx.Ack)
+	if c.pauser != nil && c.pauser.Hold(ctx) {
+		return
+	}
+	c.process(ctx)
+}
+
+func (c *connector) process(ctx *models.ListenerContext) {
 	var e couchbase.Event
 	switch event := ctx.Event.(type) {
 	case models.DcpMutation:
 		e = couchbase.NewMutateEvent(event.Key, event.Value, event.CollectionName, event.EventTime)
+		e.Cas, e.SeqNo, e.RevNo, e.VbID, e.Expiry = event.Cas, event.SeqNo, event.RevNo, event.VbID, event.Expiry
+		e.Flags, e.Datatype, e.CollectionID, e.VbUUID = event.Flags, event.Datatype, event.CollectionID, offsetVbUUID(event.Offset)
 	case models.DcpExpiration:
 		e = couchbase.NewExpireEvent(event.Key, nil, event.CollectionName, event.EventTime)
+		e.Cas, e.SeqNo, e.RevNo, e.VbID, e.CollectionID = event.Cas, event.SeqNo, event.RevNo, event.VbID, event.CollectionID
+		e.VbUUID = offsetVbUUID(event.Offset)
 	case models.DcpDeletion:
 		e = couchbase.NewDeleteEvent(event.Key, nil, event.CollectionName, event.EventTime)
+		e.Cas, e.SeqNo, e.RevNo, e.VbID, e.CollectionID = event.Cas, event.SeqNo, event.RevNo, event.VbID, event.CollectionID
+		e.VbUUID = offsetVbUUID(event.Offset)
 	default:
 		return
 	}
+	// go-dcp streams the collections of a single scope
+	e.ScopeName = c.config.Dcp.ScopeName
+	c.processEvent(ctx, &e)
+}
+
+func (c *connector) processEvent(ctx *models.ListenerContext, e *couchbase.Event) {
+	if c.rollback != nil {
+		c.detectRollback(e)
+	}
+
+	if c.skipDisabledCollection(ctx, e) || c.skipFilteredCollection(ctx, e) || c.skipEventType(ctx, e) || c.skipFilteredKey(ctx, e) ||
+		c.skipOutsideVBuckets(ctx, e) || c.skipBeforeStartFrom(ctx, e) || c.skipAfterReplay(ctx, e) ||
+		c.skipProducedSeqNo(ctx, e) || c.skipUnsampled(ctx, e) {
+		return
+	}
+
+	if c.filter != nil && !c.applyFilter(ctx, e) {
+		return
+	}
+
+	if e.IsExpired && c.handleExpiration(ctx, e) {
+		return
+	}
+
+	if c.config.Kafka.Tombstone && (e.IsDeleted || e.IsExpired) {
+		if c.tombstones == nil {
+			c.produceTombstone(ctx, e)
+			return
+		}
+		// the event is mapped to its delete marker, the tombstone follows after the delay
+		c.tombstones.schedule(e, c.tombstone(e))
+	}
+
+	if e.IsMutated && c.config.Kafka.DocumentSizeLimit.MaxSize > 0 && !c.applyDocumentSizeLimit(ctx, e) {
+		return
+	}
+
+	if e.IsMutated && c.xattrs != nil {
+		c.readXattrs(e)
+	}
 
-	kafkaMessages := c.mapper(e)
+	var metadataHeaders []sKafka.Header
+	isJSON := e.IsMutated
+	if e.IsMutated && c.config.Kafka.BinaryDocuments.Enabled {
+		metadataHeaders, isJSON = c.applyBinaryDocument(e)
+		if c.skipBinaryDocument(ctx, isJSON) {
+			return
+		}
+	}
+
+	if isJSON && c.config.Kafka.JSONComplexityLimit.IsEnabled() && !c.applyComplexityLimit(ctx, e) {
+		return
+	}
+
+	if isJSON && c.enricher != nil {
+		c.enrich(e)
+	}
+
+	kafkaMessages, ok := c.mapEvent(ctx, e)
+	if !ok {
+		return
+	}
 
 	if len(kafkaMessages) == 0 {
 		ctx.Ack()
 		return
 	}
 
+	messages, err := c.newMessages(e, kafkaMessages, c.eventHeaders(e, metadataHeaders, isJSON), isJSON)
+	if err != nil {
+		c.rejectEvent(ctx, e, err)
+		return
+	}
+
+	c.produceMessages(ctx, e, messages)
+}
+
+// eventHeaders appends the dcp metadata, configured and xattr headers to the headers of a binary document.
+func (c *connector) eventHeaders(e *couchbase.Event, metadataHeaders []sKafka.Header, isJSON bool) []sKafka.Header {
+	if c.config.Kafka.DcpMetadataHeaders {
+		metadataHeaders = append(metadataHeaders, newDcpMetadataHeaders(e)...)
+	}
+
+	if len(c.headers) > 0 {
+		metadataHeaders = append(metadataHeaders, c.headers.headers(e, isJSON)...)
+	}
+
+	if c.xattrs != nil && len(c.xattrs.headers) > 0 {
+		metadataHeaders = append(metadataHeaders, c.xattrHeaders(e)...)
+	}
+	return metadataHeaders
+}
+
+func (c *connector) newMessages(
+	e *couchbase.Event, kafkaMessages []message.KafkaMessage, metadataHeaders []sKafka.Header, isJSON bool,
+) ([]sKafka.Message, error) {
+	var templateValues map[string]any
+	if c.valueTemplate != nil {
+		templateValues = templateData(e, isJSON)
+	}
+
 	messages := make([]sKafka.Message, 0, len(kafkaMessages))
 	for _, message := range kafkaMessages {
-		messages = append(messages, sKafka.Message{
+		headers := message.Headers
+		if metadataHeaders != nil {
+			headers = make([]sKafka.Header, 0, len(message.Headers)+len(metadataHeaders))
+			headers = append(append(headers, message.Headers...), metadataHeaders...)
+		}
+
+		key := message.Key
+		if c.keyOf != nil {
+			key = c.keyOf(e)
+		}
+
+		value := message.Value
+		if c.valueTemplate != nil {
+			var err error
+			if value, err = c.valueTemplate.render(templateValues); err != nil {
+				return nil, err
+			}
+		}
+
+		kafkaMessage := sKafka.Message{
 			Topic:   c.getTopicName(e.CollectionName, message.Topic),
-			Key:     message.Key,
-			Value:   message.Value,
-			Headers: message.Headers,
-		})
+			Key:     key,
+			Value:   value,
+			Headers: headers,
+			Time:    c.messageTime(e),
+		}
+
+		if err := c.transforms.ApplyCollection(e.CollectionName, &kafkaMessage); err != nil {
+			return nil, fmt.Errorf("transform error: %w", err)
+		}
+
+		messages = append(messages, kafkaMessage)
 	}
-	c.producer.Produce(ctx, e.EventTime, messages)
+	return messages, nil
+}
+
+func (c *connector) produceMessages(ctx *models.ListenerContext, e *couchbase.Event, messages []sKafka.Message) {
+	if c.dedup != nil {
+		if messages = c.suppressDuplicates(messages); len(messages) == 0 {
+			ctx.Ack()
+			return
+		}
+	}
+
+	if c.serializer != nil && !c.serialize(messages) {
+		return
+	}
+
+	if c.valueCodec != nil {
+		c.compressValues(messages)
+	}
+
+	if c.encryptor != nil {
+		c.encryptValues(messages)
+	}
+
+	if c.claimCheck != nil {
+		c.applyClaimCheck(messages)
+	}
+
+	if c.config.Kafka.Chunking.Enabled {
+		var err error
+		if messages, err = c.splitChunks(messages); err != nil {
+			c.rejectEvent(ctx, e, err)
+			return
+		}
+	}
+
+	c.sink.Produce(ctx, e.EventTime, messages)
 }
 
 func (c *connector) getTopicName(collectionName string, messageTopic string) string {
@@ -96,16 +448,24 @@ func (c *connector) getTopicName(collectionName string, messageTopic string) str
 	return topic
 }
 
-func newConnector(cfg any, mapper Mapper) (Connector, error) {
-	c, err := newConfig(cfg)
+func newConnector(builder ConnectorBuilder) (Connector, error) {
+	c, err := newConfig(builder.config)
 	if err != nil {
 		return nil, err
 	}
 	c.ApplyDefaults()
 
-	connector := &connector{
-		mapper: mapper,
-		config: c,
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+
+	connector, err := newPipeline(builder, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = connector.loadState(c); err != nil {
+		return nil, err
 	}
 
 	dcpClient, err := dcp.NewDcp(&c.Dcp, connector.produce)
@@ -117,28 +477,290 @@ func newConnector(cfg any, mapper Mapper) (Connector, error) {
 	conf := dcpClient.GetConfig()
 	conf.Checkpoint.Type = "manual"
 
-	kafkaClient, err := createKafkaClient(c)
+	secretProvider, err := newSecretProvider(&c.Kafka.Secrets, builder.secretProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	kafkaClient, err := createKafkaClient(c, secretProvider)
 	if err != nil {
 		return nil, err
 	}
 
-	if conf.Metadata.Type == MetadataTypeKafka {
-		setKafkaMetadata(kafkaClient, conf, dcpClient)
+	if err := connector.setCheckpointMetadata(c, conf, kafkaClient, dcpClient); err != nil {
+		logger.Log.Error("checkpoint metadata error: %v", err)
+		return nil, err
+	}
+
+	if c.Kafka.StartupReport {
+		connector.startupReport = newStartupReport(c, conf, kafkaClient)
 	}
 
 	connector.dcp = dcpClient
 
-	connector.producer, err = producer.NewProducer(kafkaClient, c, dcpClient.Commit)
+	if err = connector.setStages(builder, c); err != nil {
+		return nil, err
+	}
+
+	if err = connector.setProducer(builder, c, kafkaClient, conf); err != nil {
+		return nil, err
+	}
+
+	if err = connector.setEventHandler(builder, c); err != nil {
+		return nil, err
+	}
+
+	if err = connector.setMetrics(builder, c); err != nil {
+		return nil, err
+	}
+
+	if err = connector.setAPIs(builder, c, kafkaClient); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+// loadState restores the pause, collection toggle and seqno dedup states of the state store.
+func (c *connector) loadState(conf *config.Connector) error {
+	stateStore, err := newStateStore(conf)
+	if err != nil {
+		return err
+	}
+
+	c.pauser, err = pause.NewPauser(stateStore, c.process, conf.Kafka.Pause.MaxHeldEvents)
+	if err != nil {
+		logger.Log.Error("pause state error: %v", err)
+		return err
+	}
+
+	c.collections, err = toggle.NewCollections(stateStore)
+	if err != nil {
+		logger.Log.Error("collection toggle state error: %v", err)
+		return err
+	}
+
+	if conf.Kafka.SeqNoDedup.Enabled {
+		c.seqNoDedup = newSeqNoDedup(stateStore, conf.Kafka.SeqNoDedup.SaveInterval)
+	}
+	return nil
+}
+
+// setStages builds the stages applied to the mapped messages before they are produced.
+func (c *connector) setStages(builder ConnectorBuilder, conf *config.Connector) error {
+	var err error
+	if conf.Kafka.SchemaRegistry.Enabled {
+		if c.serializer, err = newSchemaSerializer(&conf.Kafka.SchemaRegistry); err != nil {
+			return err
+		}
+	}
+
+	if conf.Kafka.ValueCompression.Enabled {
+		c.valueCodec = newValueCodec(&conf.Kafka.ValueCompression)
+	}
+
+	if conf.Kafka.Encryption.Enabled {
+		if c.encryptor, err = newEncryptor(&conf.Kafka.Encryption, builder.kms); err != nil {
+			return err
+		}
+	}
+
+	if conf.Kafka.ClaimCheck.Enabled {
+		if c.claimCheck, err = newClaimCheck(&conf.Kafka.ClaimCheck, builder.claimCheckStore); err != nil {
+			return err
+		}
+	}
+
+	if conf.Kafka.Enrichment.Enabled {
+		if c.enricher, err = newEnricher(conf); err != nil {
+			logger.Log.Error("enrichment error: %v", err)
+			return err
+		}
+	}
+
+	if conf.Kafka.TombstoneDelay > 0 {
+		c.tombstones = newTombstoneDelayer(conf.Kafka.TombstoneDelay, func(eventTime time.Time, message sKafka.Message, written func()) {
+			ctx := &models.ListenerContext{Ack: c.producer.ProducerBatch.DeferAck(written)}
+			c.sink.Produce(ctx, eventTime, []sKafka.Message{message})
+		})
+	}
+
+	if conf.Kafka.Xattrs.Enabled {
+		if c.xattrs, err = newXattrReader(conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setProducer commits the checkpoints through the lag tracker when the lag metric is enabled.
+func (c *connector) setProducer(
+	builder ConnectorBuilder, conf *config.Connector, kafkaClient kafka.Client, dcpConfig *dcpConfig.Dcp,
+) error {
+	var err error
+	checkpointCommit := c.dcp.Commit
+	if conf.Kafka.LagMetric.Enabled {
+		c.lag, err = newLagTracker(c, dcpConfig)
+		if err != nil {
+			logger.Log.Error("lag metric error: %v", err)
+			return err
+		}
+		checkpointCommit = func() {
+			c.lag.Commit()
+			c.dcp.Commit()
+		}
+	}
+
+	c.producer, err = producer.NewProducer(kafkaClient, conf, checkpointCommit, builder.wrapWriter)
 	if err != nil {
 		logger.Log.Error("kafka error: %v", err)
+		return err
+	}
+	c.sink = &c.producer
+
+	if builder.onDelivery != nil {
+		c.producer.SetDeliveryCallback(builder.onDelivery)
+	}
+	c.producer.SetFlushHooks(builder.flushHooks)
+
+	if failover := c.producer.GetFailover(); failover != nil && builder.onFailover != nil {
+		failover.SetCallback(builder.onFailover)
+	}
+	return nil
+}
+
+// setEventHandler hands the rebalance and rollback events of dcp to the producer batch.
+func (c *connector) setEventHandler(builder ConnectorBuilder, conf *config.Connector) error {
+	if conf.Kafka.RollbackMarker.Enabled {
+		c.rollback = &rollbackDetector{topic: conf.Kafka.RollbackMarker.Topic, callback: builder.onRollback}
+	}
+
+	if conf.Kafka.ActivePassive.Enabled {
+		var err error
+		if c.activePassive, err = newActivePassive(conf); err != nil {
+			logger.Log.Error("active passive error: %v", err)
+			return err
+		}
+	}
+
+	c.eventHandler = &DcpEventHandler{
+		producerBatch: c.producer.ProducerBatch,
+		metric:        c.producer.GetMetric(),
+		rollback:      c.rollback,
+		seqNoDedup:    c.seqNoDedup,
+		pauser:        c.pauser,
+		tombstones:    c.tombstones,
+		onRebalance:   builder.onRebalance,
+	}
+	c.dcp.SetEventHandler(c.eventHandler)
+	return nil
+}
+
+func (c *connector) setMetrics(builder ConnectorBuilder, conf *config.Connector) error {
+	metricCollector := initializeMetricCollector(c, c.dcp)
+
+	metricSink, err := newMetricSink(builder, conf)
+	if err != nil {
+		logger.Log.Error("metric sink error: %v", err)
+		return err
+	}
+	if metricSink != nil {
+		c.metricPusher = metric.NewPusher(metricCollector, metricSink, conf.Kafka.Metrics.Interval)
+	}
+	return nil
+}
+
+func (c *connector) setAPIs(builder ConnectorBuilder, conf *config.Connector, kafkaClient kafka.Client) error {
+	if conf.Kafka.CredentialRotation.Enabled {
+		c.rotation = newCredentialRotation(kafkaClient)
+	}
+
+	if conf.Kafka.HotReload.Enabled {
+		var err error
+		if c.hotReload, err = newHotReload(c, builder.config); err != nil {
+			logger.Log.Error("hot reload error: %v", err)
+			return err
+		}
+	}
+
+	if conf.Kafka.AdminAPI.Enabled {
+		c.replays = &replayRunner{}
+		c.replayBuilder = builder.WithConfig(conf)
+		c.api = api.NewAPI(conf.Kafka.AdminAPI.Port)
+		c.registerAdminRoutes()
+	}
+
+	if conf.Kafka.GRPCAPI.Enabled {
+		c.grpcAPI = grpcapi.NewServer(conf.Kafka.GRPCAPI.Port, &grpcAdmin{connector: c})
+	}
+
+	if conf.Kafka.Debug.Enabled {
+		c.debug = debugapi.NewServer(conf.Kafka.Debug.Port, c.debugVars)
+	}
+	return nil
+}
+
+// newPipeline builds the event processing of the connector from the mapping options, without any connection.
+func newPipeline(builder ConnectorBuilder, c *config.Connector) (*connector, error) {
+	connector := &connector{
+		mapper:      builder.mapper,
+		timestampOf: builder.timestampOf,
+		config:      c,
+		closing:     make(chan struct{}),
+	}
+
+	var err error
+	if c.Kafka.Mapper.Type != "" {
+		if err = connector.loadConfigMapper(c.Kafka.Mapper); err != nil {
+			return nil, err
+		}
+	}
+
+	connector.keyOf, err = newKeyStrategy(c.Kafka.KeyStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	connector.collectionFilter = newCollectionFilter(c.Kafka.CollectionFilter)
+	connector.keyFilter, err = newKeyFilter(c.Kafka.KeyFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	connector.headers, err = newHeaderTemplates(c.Kafka.Headers)
+	if err != nil {
 		return nil, err
 	}
 
-	connector.dcp.SetEventHandler(&DcpEventHandler{
-		producerBatch: connector.producer.ProducerBatch,
-	})
+	if c.Kafka.ValueTemplate != "" {
+		connector.valueTemplate, err = newValueTemplate(c.Kafka.ValueTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Kafka.Filter != "" {
+		connector.filter, err = filter.NewExpression(c.Kafka.Filter)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	initializeMetricCollector(connector, dcpClient)
+	connector.transforms, err = transform.NewChain(c.Kafka.Transforms)
+	if err != nil {
+		return nil, err
+	}
+	connector.transforms = append(connector.transforms, builder.transforms...)
+
+	if c.Kafka.Dedup.Enabled {
+		connector.dedup = builder.dedupCache
+		if connector.dedup == nil {
+			connector.dedup, err = dedup.NewRistrettoCache(c.Kafka.Dedup.Window, c.Kafka.Dedup.MaxSize)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	return connector, nil
 }
@@ -151,25 +773,53 @@ func newConfig(cf any) (*config.Connector, error) {
 		return &v, nil
 	case string:
 		return newConnectorConfigFromPath(v)
+	case nil:
+		return nil, errors.New("config must be set")
 	default:
 		return nil, errors.New("invalid config")
 	}
 }
 
-func createKafkaClient(cc *config.Connector) (kafka.Client, error) {
-	kafkaClient := kafka.NewClient(cc)
-
-	var topics []string
-
-	for _, topic := range cc.Kafka.CollectionTopicMapping {
-		topics = append(topics, topic)
+func newStateStore(cc *config.Connector) (state.Store, error) {
+	switch cc.Kafka.StateStore.Type {
+	case state.StoreTypeFile:
+		return state.NewFileStore(cc.Kafka.StateStore.Directory), nil
+	case state.StoreTypeMemory:
+		return state.NewMemoryStore(), nil
+	case state.StoreTypeCouchbase:
+		metadata := cc.Dcp.GetCouchbaseMetadata()
+		agent, err := dcpCouchbase.CreateAgent(
+			cc.Dcp.Hosts, metadata.Bucket, cc.Dcp.Username, cc.Dcp.Password, cc.Dcp.SecureConnection, cc.Dcp.RootCAPath,
+			metadata.ConnectionBufferSize, metadata.ConnectionTimeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return state.NewCouchbaseStore(
+			agent, metadata.Scope, metadata.Collection, helpers.Prefix+cc.Dcp.Dcp.Group.Name+":state:", metadata.ConnectionTimeout,
+		), nil
+	default:
+		return nil, fmt.Errorf("invalid state store type: %s", cc.Kafka.StateStore.Type)
 	}
+}
 
-	if !cc.Kafka.AllowAutoTopicCreation {
-		if err := kafkaClient.CheckTopics(topics); err != nil {
-			logger.Log.Error("collection topic mapping error: %v", err)
+func createKafkaClient(cc *config.Connector, secrets secret.Provider) (kafka.Client, error) {
+	kafkaClient := kafka.NewClient(cc, secrets)
+
+	if cc.Kafka.TopicCreation.Enabled {
+		created, err := kafkaClient.CreateMissingTopics(configuredTopics(&cc.Kafka))
+		if err != nil {
+			logger.Log.Error("topic creation error: %v", err)
 			return nil, err
 		}
+		if len(created) > 0 {
+			logger.Log.Info("created topics: %v", created)
+		}
+	}
+
+	if err := validateTopics(kafkaClient, &cc.Kafka, cc.Kafka.AllowAutoTopicCreation); err != nil {
+		logger.Log.Error("topic validation error: %v", err)
+		return nil, err
 	}
 
 	return kafkaClient, nil
@@ -180,35 +830,66 @@ func setKafkaMetadata(kafkaClient kafka.Client, dcpConfig *dcpConfig.Dcp, dcp dc
 	dcp.SetMetadata(kafkaMetadata)
 }
 
-func initializeMetricCollector(connector *connector, dcp dcp.Dcp) {
-	metricCollector := metric.NewMetricCollector(connector.producer)
+func initializeMetricCollector(connector *connector, dcp dcp.Dcp) *metric.Collector {
+	metricCollector := metric.NewMetricCollector(connector.producer, connector.lag, connector.pauser)
 	dcp.SetMetricCollectors(metricCollector)
+	return metricCollector
 }
 
-func newConnectorConfigFromPath(path string) (*config.Connector, error) {
-	file, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// newMetricSink returns nil when the metrics are only scraped by Prometheus.
+func newMetricSink(builder ConnectorBuilder, c *config.Connector) (metric.Sink, error) {
+	if builder.metricSink != nil {
+		return builder.metricSink, nil
 	}
-	var c config.Connector
-	err = yaml.Unmarshal(file, &c)
-	if err != nil {
-		return nil, err
+
+	switch c.Kafka.Metrics.Sink {
+	case config.MetricsSinkStatsD, config.MetricsSinkDatadog:
+		return metric.NewStatsD(
+			c.Kafka.Metrics.Address, c.Kafka.Metrics.Prefix, c.Kafka.Metrics.Tags, c.Kafka.Metrics.Sink == config.MetricsSinkDatadog,
+		)
+	default:
+		return nil, nil
 	}
-	c.ApplyDefaults()
-	return &c, nil
+}
+
+func newConnectorConfigFromPath(path string) (*config.Connector, error) {
+	return config.Load(config.Sources{Files: []string{path}})
 }
 
 type ConnectorBuilder struct {
-	mapper Mapper
-	config any
+	mapper          Mapper
+	timestampOf     TimestampExtractor
+	config          any
+	transforms      []transform.Transform
+	dedupCache      dedup.Cache
+	claimCheckStore claimcheck.Store
+	kms             encryption.KMS
+	secretProvider  secret.Provider
+	onFailover      func(event producer.FailoverEvent)
+	onDelivery      func(report producer.DeliveryReport)
+	onRollback      func(event RollbackEvent)
+	onRebalance     func(event RebalanceEvent)
+	flushHooks      producer.FlushHooks
+	wrapWriter      producer.WriterWrapper
+	metricSink      metric.Sink
 }
 
-func NewConnectorBuilder(config any) ConnectorBuilder {
-	return ConnectorBuilder{
-		config: config,
+// NewConnectorBuilder takes an optional config, a file path, a config.Connector or a *config.Connector.
+// It can also be set later with WithConfig, so the connector can be built without a yaml file.
+func NewConnectorBuilder(config ...any) ConnectorBuilder {
+	builder := ConnectorBuilder{
 		mapper: DefaultMapper,
 	}
+	if len(config) > 0 {
+		builder.config = config[0]
+	}
+	return builder
+}
+
+// WithConfig takes a file path, a config.Connector or a *config.Connector.
+func (c ConnectorBuilder) WithConfig(config any) ConnectorBuilder {
+	c.config = config
+	return c
 }
 
 func (c ConnectorBuilder) SetMapper(mapper Mapper) ConnectorBuilder {
@@ -216,8 +897,94 @@ func (c ConnectorBuilder) SetMapper(mapper Mapper) ConnectorBuilder {
 	return c
 }
 
+func (c ConnectorBuilder) WithMapper(mapper Mapper) ConnectorBuilder {
+	return c.SetMapper(mapper)
+}
+
+// SetTimestampExtractor sets a function deriving the record timestamps from the events, e.g. FieldTimestamp("updatedAt").
+func (c ConnectorBuilder) SetTimestampExtractor(extractor TimestampExtractor) ConnectorBuilder {
+	c.timestampOf = extractor
+	return c
+}
+
+// AddTransform appends a transform applied after the ones configured in kafka.transforms.
+func (c ConnectorBuilder) AddTransform(t transform.Transform) ConnectorBuilder {
+	c.transforms = append(append([]transform.Transform{}, c.transforms...), t)
+	return c
+}
+
+// SetDedupCache replaces the default in memory cache used when kafka.dedup is enabled.
+func (c ConnectorBuilder) SetDedupCache(cache dedup.Cache) ConnectorBuilder {
+	c.dedupCache = cache
+	return c
+}
+
+// SetClaimCheckStore replaces the store configured in kafka.claimCheck, e.g. with an S3 or GCS client.
+func (c ConnectorBuilder) SetClaimCheckStore(store claimcheck.Store) ConnectorBuilder {
+	c.claimCheckStore = store
+	return c
+}
+
+// SetKMS replaces the KMS configured in kafka.encryption, e.g. with a GCP or Vault transit client.
+func (c ConnectorBuilder) SetKMS(kms encryption.KMS) ConnectorBuilder {
+	c.kms = kms
+	return c
+}
+
+// SetSecretProvider replaces the provider configured in kafka.secrets, e.g. with a cloud secret manager client.
+func (c ConnectorBuilder) SetSecretProvider(provider secret.Provider) ConnectorBuilder {
+	c.secretProvider = provider
+	return c
+}
+
+// SetRollbackCallback sets a function called for every rollback detected when kafka.rollbackMarker is enabled.
+func (c ConnectorBuilder) SetRollbackCallback(callback func(event RollbackEvent)) ConnectorBuilder {
+	c.onRollback = callback
+	return c
+}
+
+// SetRebalanceCallback sets a function called on the start and the end of every DCP rebalance, e.g. to alert on
+// rebalance storms. It is called from the go-dcp goroutine and delays the rebalance, so it should be fast.
+func (c ConnectorBuilder) SetRebalanceCallback(callback func(event RebalanceEvent)) ConnectorBuilder {
+	c.onRebalance = callback
+	return c
+}
+
+// SetWriterWrapper sets a function wrapping every Kafka writer of the producer, e.g. to instrument the writes.
+func (c ConnectorBuilder) SetWriterWrapper(wrapper producer.WriterWrapper) ConnectorBuilder {
+	c.wrapWriter = wrapper
+	return c
+}
+
+// SetDeliveryCallback sets a function called with the partition and offset, or the error, of every message written
+// to Kafka, e.g. to write the offsets back to the documents.
+func (c ConnectorBuilder) SetDeliveryCallback(callback func(report producer.DeliveryReport)) ConnectorBuilder {
+	c.onDelivery = callback
+	return c
+}
+
+// SetFlushHooks sets functions called before and after every flush of the batch, e.g. for auditing or a last
+// mutation of the messages.
+func (c ConnectorBuilder) SetFlushHooks(hooks producer.FlushHooks) ConnectorBuilder {
+	c.flushHooks = hooks
+	return c
+}
+
+// SetMetricSink sets a sink the connector metrics are pushed to every kafka.metrics.interval, instead of the
+// kafka.metrics.sink one.
+func (c ConnectorBuilder) SetMetricSink(sink metric.Sink) ConnectorBuilder {
+	c.metricSink = sink
+	return c
+}
+
+// SetFailoverCallback sets a function called when the producer fails over to kafka.failover.brokers.
+func (c ConnectorBuilder) SetFailoverCallback(callback func(event producer.FailoverEvent)) ConnectorBuilder {
+	c.onFailover = callback
+	return c
+}
+
 func (c ConnectorBuilder) Build() (Connector, error) {
-	return newConnector(c.config, c.mapper)
+	return newConnector(c)
 }
 
 func (c ConnectorBuilder) SetLogger(l *logrus.Logger) ConnectorBuilder {
@@ -226,3 +993,25 @@ func (c ConnectorBuilder) SetLogger(l *logrus.Logger) ConnectorBuilder {
 	}
 	return c
 }
+
+// WithLogger takes any go-dcp logger, e.g. one of the logging adapters.
+func (c ConnectorBuilder) WithLogger(l logger.Logger) ConnectorBuilder {
+	logger.Log = l
+	return c
+}
+
+// SetSlogLogger backs the connector and go-dcp logs with slog, connector logs carry fields such as topic, vbId and seqNo.
+func (c ConnectorBuilder) SetSlogLogger(l *slog.Logger) ConnectorBuilder {
+	logger.Log = logging.NewSlogLogger(l)
+	return c
+}
+
+// SetZapLogger backs the connector and go-dcp logs with zap, connector logs carry fields such as topic, vbId and seqNo.
+func (c ConnectorBuilder) SetZapLogger(l *zap.Logger) ConnectorBuilder {
+	logger.Log = logging.NewZapLogger(l)
+	return c
+}
+
+func eventFields(e *couchbase.Event) []logging.Field {
+	return []logging.Field{logging.Key(e.Key), logging.VbID(e.VbID), logging.SeqNo(e.SeqNo)}
+}
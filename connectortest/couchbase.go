@@ -0,0 +1,124 @@
+package connectortest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+)
+
+const (
+	couchbaseHost    = "localhost:8091"
+	operationTimeout = 10 * time.Second
+)
+
+func startCouchbase(ctx context.Context, options *Options) (testcontainers.Container, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        options.CouchbaseImage,
+			ExposedPorts: []string{"8091:8091/tcp", "11210:11210/tcp"},
+			WaitingFor:   wait.ForHTTP("/pools").WithPort("8091/tcp").WithStartupTimeout(options.StartupTimeout),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commands := [][]string{
+		{
+			"couchbase-cli", "cluster-init", "-c", "127.0.0.1",
+			"--cluster-username", options.Username, "--cluster-password", options.Password,
+			"--services", "data", "--cluster-ramsize", "512",
+		},
+		{
+			"couchbase-cli", "bucket-create", "-c", "127.0.0.1", "-u", options.Username, "-p", options.Password,
+			"--bucket", options.BucketName, "--bucket-type", "couchbase", "--bucket-ramsize", "256",
+			"--bucket-replica", "0", "--wait",
+		},
+	}
+	for _, command := range commands {
+		if err := exec(ctx, container, command); err != nil {
+			_ = container.Terminate(context.Background())
+			return nil, err
+		}
+	}
+	return container, nil
+}
+
+func exec(ctx context.Context, container testcontainers.Container, command []string) error {
+	code, output, err := container.Exec(ctx, command)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		message, _ := io.ReadAll(output)
+		return fmt.Errorf("%s %s exited with %d: %s", command[0], command[1], code, message)
+	}
+	return nil
+}
+
+func connectBucket(options *Options) (*gocbcore.Agent, error) {
+	return dcpCouchbase.CreateAgent(
+		[]string{couchbaseHost}, options.BucketName, options.Username, options.Password, false, "", 20971520, operationTimeout,
+	)
+}
+
+// Upsert stores the value in the _default collection, values other than []byte are stored as JSON.
+func (e *Environment) Upsert(t testing.TB, key string, value any) {
+	t.Helper()
+
+	data, ok := value.([]byte)
+	if !ok {
+		var err error
+		if data, err = jsoniter.Marshal(value); err != nil {
+			t.Fatalf("cannot marshal %s: %v", key, err)
+		}
+	}
+
+	e.wait(t, key, func(callback func(error)) (gocbcore.PendingOp, error) {
+		return e.agent.Set(gocbcore.SetOptions{Key: []byte(key), Value: data}, func(_ *gocbcore.StoreResult, err error) {
+			callback(err)
+		})
+	})
+}
+
+// Delete removes the document from the _default collection.
+func (e *Environment) Delete(t testing.TB, key string) {
+	t.Helper()
+
+	e.wait(t, key, func(callback func(error)) (gocbcore.PendingOp, error) {
+		return e.agent.Delete(gocbcore.DeleteOptions{Key: []byte(key)}, func(_ *gocbcore.DeleteResult, err error) {
+			callback(err)
+		})
+	})
+}
+
+func (e *Environment) wait(t testing.TB, key string, operation func(callback func(error)) (gocbcore.PendingOp, error)) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	opm := dcpCouchbase.NewAsyncOp(ctx)
+	ch := make(chan error, 1)
+	op, err := operation(func(err error) {
+		opm.Resolve()
+		ch <- err
+	})
+
+	if err = opm.Wait(op, err); err == nil {
+		err = <-ch
+	}
+	if err != nil {
+		t.Fatalf("couchbase operation on %s failed: %v", key, err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package connectortest runs Couchbase and Kafka containers for end-to-end tests of connectors, e.g. of a mapper:
+//
+//	env := connectortest.Start(t, connectortest.Options{})
+//	connector, _ := dcpkafka.NewConnectorBuilder(env.Config()).SetMapper(mapper).Build()
+//	env.Run(t, connector)
+//
+//	env.Upsert(t, "order:1", map[string]any{"id": 1})
+//	message := env.WaitForMessage(t, "order:1", time.Minute)
+//
+// The containers bind the default ports 8091, 11210 and 9092 of the host, since the Couchbase and Kafka clients
+// connect to the advertised ports.
+package connectortest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+
+	dcpkafka "github.com/Trendyol/go-dcp-kafka"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/membership"
+)
+
+const (
+	DefaultCouchbaseImage = "couchbase:community-7.2.2"
+	DefaultKafkaImage     = "bitnami/kafka:3.5"
+)
+
+type Options struct {
+	CouchbaseImage string
+	KafkaImage     string
+	BucketName     string
+	Username       string
+	Password       string
+	// Topic is created with a single partition and mapped to the _default collection by Config.
+	Topic          string
+	StartupTimeout time.Duration
+}
+
+func (o *Options) applyDefaults() {
+	if o.CouchbaseImage == "" {
+		o.CouchbaseImage = DefaultCouchbaseImage
+	}
+	if o.KafkaImage == "" {
+		o.KafkaImage = DefaultKafkaImage
+	}
+	if o.BucketName == "" {
+		o.BucketName = "dcp-test"
+	}
+	if o.Username == "" {
+		o.Username = "user"
+	}
+	if o.Password == "" {
+		o.Password = "123456"
+	}
+	if o.Topic == "" {
+		o.Topic = "test"
+	}
+	if o.StartupTimeout == 0 {
+		o.StartupTimeout = 3 * time.Minute
+	}
+}
+
+type Environment struct {
+	couchbase testcontainers.Container
+	kafka     testcontainers.Container
+	agent     *gocbcore.Agent
+	reader    *kafka.Reader
+	received  *sync.Cond
+	messages  []kafka.Message
+	options   Options
+	lock      sync.Mutex
+}
+
+// Start runs the containers, creates the bucket and the topic and consumes the topic from the beginning. The
+// containers are terminated when the test ends.
+func Start(t testing.TB, options Options) *Environment {
+	t.Helper()
+	options.applyDefaults()
+
+	e := &Environment{options: options}
+	e.received = sync.NewCond(&e.lock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.StartupTimeout)
+	defer cancel()
+
+	var err error
+	if e.couchbase, err = startCouchbase(ctx, &options); err != nil {
+		t.Fatalf("couchbase container error: %v", err)
+	}
+	t.Cleanup(func() { _ = e.couchbase.Terminate(context.Background()) })
+
+	if e.kafka, err = startKafka(ctx, &options); err != nil {
+		t.Fatalf("kafka container error: %v", err)
+	}
+	t.Cleanup(func() { _ = e.kafka.Terminate(context.Background()) })
+
+	if e.agent, err = connectBucket(&options); err != nil {
+		t.Fatalf("couchbase connection error: %v", err)
+	}
+	t.Cleanup(func() { _ = e.agent.Close() })
+
+	if err = createTopic(options.Topic); err != nil {
+		t.Fatalf("kafka topic error: %v", err)
+	}
+	e.consume()
+	t.Cleanup(func() { _ = e.reader.Close() })
+
+	return e
+}
+
+// Config returns a new config streaming the bucket to the topic as a single member, with the checkpoints in the bucket.
+func (e *Environment) Config() *config.Connector {
+	return &config.Connector{
+		Dcp: dcpConfig.Dcp{
+			Hosts:              []string{couchbaseHost},
+			Username:           e.options.Username,
+			Password:           e.options.Password,
+			BucketName:         e.options.BucketName,
+			RollbackMitigation: dcpConfig.RollbackMitigation{Disabled: true},
+			Dcp: dcpConfig.ExternalDcp{
+				Group: dcpConfig.DCPGroup{
+					Name: "connectortest",
+					Membership: dcpConfig.DCPGroupMembership{
+						Type: membership.StaticMembershipType, MemberNumber: 1, TotalMembers: 1,
+					},
+				},
+			},
+			Metadata: dcpConfig.Metadata{
+				Type: dcpConfig.MetadataTypeCouchbase,
+				Config: map[string]any{
+					dcpConfig.CouchbaseMetadataBucketConfig:     e.options.BucketName,
+					dcpConfig.CouchbaseMetadataScopeConfig:      dcpConfig.DefaultScopeName,
+					dcpConfig.CouchbaseMetadataCollectionConfig: dcpConfig.DefaultCollectionName,
+				},
+			},
+			API: dcpConfig.API{Disabled: true},
+		},
+		Kafka: config.Kafka{
+			CollectionTopicMapping:      map[string]string{dcpConfig.DefaultCollectionName: e.options.Topic},
+			Brokers:                     []string{kafkaBroker},
+			ProducerBatchTickerDuration: 100 * time.Millisecond,
+		},
+	}
+}
+
+// Run starts the connector and closes it when the test ends.
+func (e *Environment) Run(t testing.TB, connector dcpkafka.Connector) {
+	t.Helper()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		connector.Start()
+	}()
+
+	t.Cleanup(func() {
+		connector.Close()
+		<-stopped
+	})
+}
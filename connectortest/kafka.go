@@ -0,0 +1,171 @@
+package connectortest
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const kafkaBroker = "localhost:9092"
+
+func startKafka(ctx context.Context, options *Options) (testcontainers.Container, error) {
+	return testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        options.KafkaImage,
+			ExposedPorts: []string{"9092:9092/tcp"},
+			Env: map[string]string{
+				"KAFKA_CFG_NODE_ID":                        "0",
+				"KAFKA_CFG_PROCESS_ROLES":                  "controller,broker",
+				"KAFKA_CFG_LISTENERS":                      "PLAINTEXT://:9092,CONTROLLER://:9093",
+				"KAFKA_CFG_ADVERTISED_LISTENERS":           "PLAINTEXT://" + kafkaBroker,
+				"KAFKA_CFG_LISTENER_SECURITY_PROTOCOL_MAP": "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+				"KAFKA_CFG_CONTROLLER_QUORUM_VOTERS":       "0@localhost:9093",
+				"KAFKA_CFG_CONTROLLER_LISTENER_NAMES":      "CONTROLLER",
+			},
+			WaitingFor: wait.ForLog("Kafka Server started").WithStartupTimeout(options.StartupTimeout),
+		},
+		Started: true,
+	})
+}
+
+func createTopic(topic string) error {
+	conn, err := kafka.Dial("tcp", kafkaBroker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	if err != nil {
+		return err
+	}
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return err
+	}
+	defer controllerConn.Close()
+
+	return controllerConn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+}
+
+// consume buffers the messages of the topic until the reader is closed.
+func (e *Environment) consume() {
+	e.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{kafkaBroker},
+		Topic:       e.options.Topic,
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     100 * time.Millisecond,
+	})
+
+	go func() {
+		for {
+			message, err := e.reader.ReadMessage(context.Background())
+			if err != nil {
+				return
+			}
+
+			e.lock.Lock()
+			e.messages = append(e.messages, message)
+			e.received.Broadcast()
+			e.lock.Unlock()
+		}
+	}()
+}
+
+// Messages returns the messages consumed so far.
+func (e *Environment) Messages() []kafka.Message {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return append([]kafka.Message(nil), e.messages...)
+}
+
+// WaitForMessages waits until n messages are consumed and returns them, it fails the test after the timeout.
+func (e *Environment) WaitForMessages(t testing.TB, n int, timeout time.Duration) []kafka.Message {
+	t.Helper()
+
+	messages, ok := e.waitFor(timeout, func(messages []kafka.Message) ([]kafka.Message, bool) {
+		return messages, len(messages) >= n
+	})
+	if !ok {
+		t.Fatalf("%d of %d messages are consumed after %v", len(messages), n, timeout)
+	}
+	return append([]kafka.Message(nil), messages[:n]...)
+}
+
+// WaitForMessage waits for the first message with the key, it fails the test after the timeout.
+func (e *Environment) WaitForMessage(t testing.TB, key string, timeout time.Duration) kafka.Message {
+	t.Helper()
+
+	messages, ok := e.waitFor(timeout, func(messages []kafka.Message) ([]kafka.Message, bool) {
+		for i := range messages {
+			if string(messages[i].Key) == key {
+				return messages[i : i+1], true
+			}
+		}
+		return nil, false
+	})
+	if !ok {
+		t.Fatalf("no message with key %s is consumed after %v", key, timeout)
+	}
+	return messages[0]
+}
+
+// AssertMessage waits for the first message with the key and fails the test when its value is not the expected one.
+func (e *Environment) AssertMessage(t testing.TB, key string, value []byte, timeout time.Duration) {
+	t.Helper()
+
+	if message := e.WaitForMessage(t, key, timeout); string(message.Value) != string(value) {
+		t.Fatalf("message %s has value %s, expected %s", key, message.Value, value)
+	}
+}
+
+// AssertNoMessage fails the test when a message with the key is consumed within the duration, e.g. for filtered events.
+func (e *Environment) AssertNoMessage(t testing.TB, key string, duration time.Duration) {
+	t.Helper()
+
+	if _, ok := e.waitFor(duration, func(messages []kafka.Message) ([]kafka.Message, bool) {
+		for i := range messages {
+			if string(messages[i].Key) == key {
+				return nil, true
+			}
+		}
+		return nil, false
+	}); ok {
+		t.Fatalf("message %s is consumed", key)
+	}
+}
+
+// waitFor checks the consumed messages on every new message until done or the timeout.
+func (e *Environment) waitFor(
+	timeout time.Duration,
+	check func(messages []kafka.Message) ([]kafka.Message, bool),
+) ([]kafka.Message, bool) {
+	timer := time.AfterFunc(timeout, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		e.received.Broadcast()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for {
+		if result, done := check(e.messages); done {
+			return result, true
+		}
+		if !time.Now().Before(deadline) {
+			return append([]kafka.Message(nil), e.messages...), false
+		}
+		e.received.Wait()
+	}
+}
@@ -3,15 +3,43 @@ package couchbase
 import "time"
 
 type Event struct {
+	ScopeName      string
 	CollectionName string
 	EventTime      time.Time
 	Key            []byte
 	Value          []byte
+	Xattrs         map[string][]byte
+	Cas            uint64
+	SeqNo          uint64
+	RevNo          uint64
+	VbUUID         uint64
+	Expiry         uint32
+	Flags          uint32
+	CollectionID   uint32
+	VbID           uint16
+	Datatype       uint8
 	IsDeleted      bool
 	IsExpired      bool
 	IsMutated      bool
 }
 
+const (
+	EventTypeMutation   = "mutation"
+	EventTypeDeletion   = "deletion"
+	EventTypeExpiration = "expiration"
+)
+
+func (e *Event) EventType() string {
+	switch {
+	case e.IsDeleted:
+		return EventTypeDeletion
+	case e.IsExpired:
+		return EventTypeExpiration
+	default:
+		return EventTypeMutation
+	}
+}
+
 func NewDeleteEvent(key []byte, value []byte, collectionName string, eventTime time.Time) Event {
 	return Event{
 		Key:            key,
@@ -0,0 +1,45 @@
+package couchbase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// DatatypeXattr is set on the values that start with an xattr section.
+const DatatypeXattr = 0x04
+
+var errInvalidXattrs = errors.New("invalid xattr section")
+
+// SplitXattrs separates the xattr section of a value with DatatypeXattr from the document body. The section is the
+// big endian length of its pairs, each pair is the length of `name\x00value\x00` followed by them.
+func SplitXattrs(value []byte) (xattrs map[string][]byte, body []byte, err error) {
+	if len(value) < 4 {
+		return nil, nil, errInvalidXattrs
+	}
+	size := binary.BigEndian.Uint32(value)
+	if uint64(size) > uint64(len(value)-4) {
+		return nil, nil, errInvalidXattrs
+	}
+
+	section, body := value[4:4+size], value[4+size:]
+	xattrs = map[string][]byte{}
+	for len(section) > 0 {
+		if len(section) < 4 {
+			return nil, nil, errInvalidXattrs
+		}
+		pairSize := binary.BigEndian.Uint32(section)
+		if uint64(pairSize) > uint64(len(section)-4) {
+			return nil, nil, errInvalidXattrs
+		}
+
+		pair := section[4 : 4+pairSize]
+		section = section[4+pairSize:]
+		nameEnd := bytes.IndexByte(pair, 0)
+		if nameEnd < 0 || len(pair) == nameEnd+1 || pair[len(pair)-1] != 0 {
+			return nil, nil, errInvalidXattrs
+		}
+		xattrs[string(pair[:nameEnd])] = pair[nameEnd+1 : len(pair)-1]
+	}
+	return xattrs, body, nil
+}
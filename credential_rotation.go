@@ -0,0 +1,51 @@
+package dcpkafka
+
+import (
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type credentialRotation struct {
+	client kafka.Client
+	stop   chan struct{}
+}
+
+func newCredentialRotation(client kafka.Client) *credentialRotation {
+	return &credentialRotation{
+		client: client,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start checks for rotated secrets and certificate files every interval, a failed check keeps the current transport.
+func (r *credentialRotation) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.rotate()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (r *credentialRotation) Close() {
+	close(r.stop)
+}
+
+func (r *credentialRotation) rotate() {
+	rotated, err := r.client.RotateCredentials()
+	if err != nil {
+		logger.Log.Error("credential rotation error: %v", err)
+		return
+	}
+	if rotated {
+		logger.Log.Info("kafka credentials are rotated, new connections use the new credentials")
+	}
+}
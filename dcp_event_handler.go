@@ -1,12 +1,58 @@
 package dcpkafka
 
-import "github.com/Trendyol/go-dcp-kafka/kafka/producer"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/kafka/producer"
+	"github.com/Trendyol/go-dcp-kafka/pause"
+)
+
+const (
+	RebalanceStart = "start"
+	RebalanceEnd   = "end"
+)
+
+// RebalanceEvent is passed to the rebalance callback on the start and the end of a DCP rebalance. Duration and the
+// messages held by the batch when the streams stopped and dropped with it are set on the end.
+type RebalanceEvent struct {
+	StartedAt       time.Time     `json:"startedAt"`
+	Type            string        `json:"type"`
+	Duration        time.Duration `json:"duration"`
+	HeldMessages    int64         `json:"heldMessages"`
+	DroppedMessages int64         `json:"droppedMessages"`
+}
 
 type DcpEventHandler struct {
+	lastRebalance time.Time
 	producerBatch *producer.Batch
+	metric        *producer.Metric
+	rollback      *rollbackDetector
+	seqNoDedup    *seqNoDedup
+	pauser        *pause.Pauser
+	tombstones    *tombstoneDelayer
+	onRebalance   func(event RebalanceEvent)
+	lock          sync.RWMutex
+	startHeld     int64
+	startDropped  int64
+	rebalancing   bool
+	streaming     bool
 }
 
 func (h *DcpEventHandler) BeforeRebalanceStart() {
+	h.lock.Lock()
+	h.rebalancing = true
+	h.lastRebalance = time.Now()
+	h.startHeld = atomic.LoadInt64(&h.metric.RebalanceHeldMessages)
+	h.startDropped = atomic.LoadInt64(&h.metric.RebalanceDroppedMessages)
+	event := RebalanceEvent{StartedAt: h.lastRebalance, Type: RebalanceStart}
+	h.lock.Unlock()
+
+	atomic.AddInt64(&h.metric.Rebalances, 1)
+	if h.onRebalance != nil {
+		h.onRebalance(event)
+	}
 }
 
 func (h *DcpEventHandler) AfterRebalanceStart() {
@@ -16,6 +62,21 @@ func (h *DcpEventHandler) BeforeRebalanceEnd() {
 }
 
 func (h *DcpEventHandler) AfterRebalanceEnd() {
+	h.lock.Lock()
+	h.rebalancing = false
+	event := RebalanceEvent{
+		StartedAt:       h.lastRebalance,
+		Type:            RebalanceEnd,
+		Duration:        time.Since(h.lastRebalance),
+		HeldMessages:    atomic.LoadInt64(&h.metric.RebalanceHeldMessages) - h.startHeld,
+		DroppedMessages: atomic.LoadInt64(&h.metric.RebalanceDroppedMessages) - h.startDropped,
+	}
+	h.lock.Unlock()
+
+	atomic.StoreInt64(&h.metric.RebalanceDuration, event.Duration.Milliseconds())
+	if h.onRebalance != nil {
+		h.onRebalance(event)
+	}
 }
 
 func (h *DcpEventHandler) BeforeStreamStart() {
@@ -23,11 +84,36 @@ func (h *DcpEventHandler) BeforeStreamStart() {
 }
 
 func (h *DcpEventHandler) AfterStreamStart() {
+	h.lock.Lock()
+	h.streaming = true
+	h.lock.Unlock()
 }
 
 func (h *DcpEventHandler) BeforeStreamStop() {
+	h.lock.Lock()
+	h.streaming = false
+	h.lock.Unlock()
 	h.producerBatch.PrepareStartRebalancing()
+	if h.rollback != nil {
+		h.rollback.reset()
+	}
+	if h.seqNoDedup != nil {
+		h.seqNoDedup.reset()
+	}
+	if h.pauser != nil {
+		h.pauser.Reset()
+	}
+	if h.tombstones != nil {
+		h.tombstones.reset()
+	}
 }
 
 func (h *DcpEventHandler) AfterStreamStop() {
 }
+
+func (h *DcpEventHandler) state() (rebalancing bool, streaming bool, lastRebalance time.Time) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.rebalancing, h.streaming, h.lastRebalance
+}
@@ -0,0 +1,27 @@
+package dcpkafka
+
+import (
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/kafka/producer"
+)
+
+type debugVars struct {
+	Batch             producer.BatchStats `json:"batch"`
+	ProducedMessages  int64               `json:"producedMessages"`
+	ProducedBytes     int64               `json:"producedBytes"`
+	ProduceErrors     int64               `json:"produceErrors"`
+	CheckpointCommits int64               `json:"checkpointCommits"`
+}
+
+// debugVars is the connector variable of the debug server, e.g. to follow the batch buffer while profiling.
+func (c *connector) debugVars() any {
+	metric := c.producer.GetMetric()
+	return debugVars{
+		Batch:             c.producer.ProducerBatch.Stats(),
+		ProducedMessages:  atomic.LoadInt64(&metric.ProducedMessages),
+		ProducedBytes:     atomic.LoadInt64(&metric.ProducedBytes),
+		ProduceErrors:     atomic.LoadInt64(&metric.ProduceErrors),
+		CheckpointCommits: atomic.LoadInt64(&metric.CheckpointCommits),
+	}
+}
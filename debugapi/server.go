@@ -0,0 +1,70 @@
+package debugapi
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// Server serves the pprof profiles under /debug/pprof/ and the expvar variables under /debug/vars. The connector
+// variable is read on every request instead of being published, so a rebuilt connector does not publish it twice.
+type Server struct {
+	server *http.Server
+	port   int
+}
+
+func NewServer(port int, connectorVars func() any) *Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", vars(connectorVars))
+
+	return &Server{
+		port: port,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+func (s *Server) Listen() {
+	logger.Log.Info("debug server starting on port %d", s.port)
+
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Log.Error("debug server cannot start on port %d, err: %v", s.port, err)
+	} else {
+		logger.Log.Info("debug server stopped")
+	}
+}
+
+func (s *Server) Shutdown() {
+	if err := s.server.Shutdown(context.Background()); err != nil {
+		logger.Log.Error("debug server cannot be shutdown, err: %v", err)
+	}
+}
+
+func vars(connectorVars func() any) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		values := map[string]any{"connector": connectorVars()}
+		expvar.Do(func(kv expvar.KeyValue) {
+			values[kv.Key] = jsoniter.RawMessage(kv.Value.String())
+		})
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = jsoniter.NewEncoder(w).Encode(values)
+	}
+}
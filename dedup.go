@@ -0,0 +1,34 @@
+package dcpkafka
+
+import (
+	"sync/atomic"
+
+	"github.com/cespare/xxhash/v2"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+// tombstoneFingerprint replaces the fingerprint of a key on a tombstone, so a document recreated with its value from
+// before the delete is not suppressed.
+const tombstoneFingerprint = 0
+
+// suppressDuplicates drops messages whose value is byte-identical to the last one produced for the same topic and key
+// within the dedup window, e.g. after touch operations or no-op updates. Tombstones are never suppressed.
+func (c *connector) suppressDuplicates(messages []sKafka.Message) []sKafka.Message {
+	kept := messages[:0]
+
+	for _, message := range messages {
+		key := message.Topic + "\x00" + string(message.Key)
+		if message.Value == nil {
+			c.dedup.Seen(key, tombstoneFingerprint)
+			kept = append(kept, message)
+			continue
+		}
+		if c.dedup.Seen(key, xxhash.Sum64(message.Value)) {
+			atomic.AddInt64(&c.producer.GetMetric().DedupSuppressed, 1)
+			continue
+		}
+		kept = append(kept, message)
+	}
+
+	return kept
+}
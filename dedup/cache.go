@@ -0,0 +1,46 @@
+package dedup
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+// Cache remembers the fingerprint of the last produced value per key for a time window.
+type Cache interface {
+	// Seen reports whether the fingerprint is the last one recorded for the key, and records it otherwise.
+	Seen(key string, fingerprint uint64) bool
+	Close()
+}
+
+type ristrettoCache struct {
+	cache  *ristretto.Cache
+	window time.Duration
+}
+
+// NewRistrettoCache keeps at most maxSize keys. Ristretto may drop writes under contention,
+// which only lets a duplicate through, so suppression is best effort.
+func NewRistrettoCache(window time.Duration, maxSize int64) (Cache, error) {
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: maxSize * 10,
+		MaxCost:     maxSize,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ristrettoCache{cache: cache, window: window}, nil
+}
+
+func (c *ristrettoCache) Seen(key string, fingerprint uint64) bool {
+	if value, ok := c.cache.Get(key); ok && value.(uint64) == fingerprint {
+		return true
+	}
+	c.cache.SetWithTTL(key, fingerprint, 1, c.window)
+	return false
+}
+
+func (c *ristrettoCache) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,73 @@
+package document
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	KindJSON    = "json"
+	KindBinary  = "binary"
+	KindCounter = "counter"
+)
+
+const (
+	datatypeJSON = 0x01
+	// common flags set by the SDK transcoders in the top byte
+	commonFlagsMask   = 0xff000000
+	commonFlagsJSON   = 0x02000000
+	commonFlagsBinary = 0x03000000
+)
+
+// Kind tells JSON documents apart from counters and raw binary documents by the DCP datatype and flags.
+// Counters are created by the server with no flags and contain only an ASCII uint64.
+func Kind(value []byte, datatype uint8, flags uint32) string {
+	commonFlags := flags & commonFlagsMask
+
+	if flags == 0 && isInteger(value) {
+		return KindCounter
+	}
+	if commonFlags == commonFlagsBinary || (datatype&datatypeJSON == 0 && commonFlags != commonFlagsJSON) {
+		return KindBinary
+	}
+	return KindJSON
+}
+
+type envelope struct {
+	Counter  *uint64 `json:"value,omitempty"`
+	Type     string  `json:"type"`
+	Encoding string  `json:"encoding,omitempty"`
+	Data     string  `json:"data,omitempty"`
+}
+
+// Envelope wraps a non JSON document into a JSON document, binary data is base64 encoded.
+func Envelope(kind string, value []byte) ([]byte, error) {
+	e := envelope{Type: kind}
+
+	if kind == KindCounter {
+		counter, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		e.Counter = &counter
+	} else {
+		e.Encoding = "base64"
+		e.Data = base64.StdEncoding.EncodeToString(value)
+	}
+
+	return jsoniter.Marshal(e)
+}
+
+func isInteger(value []byte) bool {
+	if len(value) == 0 {
+		return false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
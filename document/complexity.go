@@ -0,0 +1,211 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var (
+	ErrMaxDepthExceeded  = errors.New("document max depth exceeded")
+	ErrMaxFieldsExceeded = errors.New("document max fields exceeded")
+)
+
+// CheckComplexity walks the document token by token and returns as soon as one of the limits is exceeded,
+// so a pathological document is never fully decoded. Zero limits are ignored.
+func CheckComplexity(value []byte, maxDepth int, maxFields int) error {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+
+	var fields int
+	var stack []container
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		delim, isDelim := token.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if len(stack) > 0 {
+			parent := &stack[len(stack)-1]
+			if parent.isObject {
+				if parent.keyNext {
+					parent.keyNext = false
+					fields++
+					if maxFields > 0 && fields > maxFields {
+						return ErrMaxFieldsExceeded
+					}
+					continue
+				}
+				parent.keyNext = true
+			}
+		}
+
+		if isDelim {
+			if maxDepth > 0 && len(stack)+1 > maxDepth {
+				return ErrMaxDepthExceeded
+			}
+			stack = append(stack, container{isObject: delim == '{', keyNext: delim == '{'})
+		}
+	}
+}
+
+type container struct {
+	isObject bool
+	keyNext  bool
+}
+
+// TruncateComplexity rewrites the document so that it fits into the given limits.
+// Containers deeper than maxDepth are replaced with null and fields after maxFields are dropped.
+func TruncateComplexity(value []byte, maxDepth int, maxFields int) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	t := &truncater{
+		decoder:   decoder,
+		maxDepth:  maxDepth,
+		maxFields: maxFields,
+	}
+
+	if err := t.value(0); err != nil {
+		return nil, err
+	}
+
+	return t.out.Bytes(), nil
+}
+
+type truncater struct {
+	decoder   *json.Decoder
+	out       bytes.Buffer
+	maxDepth  int
+	maxFields int
+	fields    int
+}
+
+func (t *truncater) value(depth int) error {
+	token, err := t.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return t.scalar(token)
+	}
+
+	if t.maxDepth > 0 && depth >= t.maxDepth {
+		t.out.WriteString("null")
+		return t.skip()
+	}
+
+	if delim == '{' {
+		return t.object(depth)
+	}
+	return t.array(depth)
+}
+
+func (t *truncater) object(depth int) error {
+	t.out.WriteByte('{')
+	written := 0
+
+	for t.decoder.More() {
+		key, err := t.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		t.fields++
+		if t.maxFields > 0 && t.fields > t.maxFields {
+			if err := t.skipValue(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if written > 0 {
+			t.out.WriteByte(',')
+		}
+		written++
+
+		if err := t.scalar(key); err != nil {
+			return err
+		}
+		t.out.WriteByte(':')
+
+		if err := t.value(depth + 1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.decoder.Token(); err != nil {
+		return err
+	}
+	t.out.WriteByte('}')
+	return nil
+}
+
+func (t *truncater) array(depth int) error {
+	t.out.WriteByte('[')
+
+	for i := 0; t.decoder.More(); i++ {
+		if i > 0 {
+			t.out.WriteByte(',')
+		}
+		if err := t.value(depth + 1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.decoder.Token(); err != nil {
+		return err
+	}
+	t.out.WriteByte(']')
+	return nil
+}
+
+func (t *truncater) scalar(token json.Token) error {
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	t.out.Write(encoded)
+	return nil
+}
+
+func (t *truncater) skipValue() error {
+	token, err := t.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := token.(json.Delim); ok {
+		return t.skip()
+	}
+	return nil
+}
+
+// skip consumes tokens until the container that was just opened is closed.
+func (t *truncater) skip() error {
+	for depth := 1; depth > 0; {
+		token, err := t.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
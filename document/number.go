@@ -0,0 +1,47 @@
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Decode decodes a JSON document into any like jsoniter.Unmarshal, but keeps its integers exact with ExactNumbers.
+func Decode(value []byte) (any, error) {
+	decoder := jsoniter.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return ExactNumbers(decoded), nil
+}
+
+// ExactNumbers turns the json.Number integers of a document decoded with UseNumber into int64, float64 would round
+// the ones above 2^53. Integers beyond int64 stay json.Number and the other numbers become float64, so they can still
+// be compared.
+func ExactNumbers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = ExactNumbers(field)
+		}
+	case []any:
+		for i := range v {
+			v[i] = ExactNumbers(v[i])
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if strings.ContainsAny(string(v), ".eE") {
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,36 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// applyDocumentSizeLimit returns false when an oversized document must not reach the mapper. Its dead letter
+// carries the key and the reason without the value, which would fail the same way.
+func (c *connector) applyDocumentSizeLimit(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	limit := c.config.Kafka.DocumentSizeLimit
+	if len(e.Value) <= limit.MaxSize {
+		return true
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().OversizedDocuments, 1)
+	err := fmt.Errorf("document size %d exceeds %d", len(e.Value), limit.MaxSize)
+
+	switch limit.Policy {
+	case config.DocumentSizePolicyClaimCheck:
+		return true
+	case config.DocumentSizePolicyDeadLetter:
+		deadLetter := *e
+		deadLetter.Value = nil
+		c.produceDeadLetter(ctx, &deadLetter, err)
+	default:
+		logger.Log.Debug("skipping document, key: %s, err: %v", e.Key, err)
+		ctx.Ack()
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package dcpkafka
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+type dryRunMessage struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key"`
+	Value   string            `json:"value"`
+}
+
+// dryRun writes the messages as JSON lines until limit messages are written, limit 0 means unlimited.
+type dryRun struct {
+	encoder *jsoniter.Encoder
+	done    chan struct{}
+	err     error
+	lock    sync.Mutex
+	count   int
+	limit   int
+}
+
+func (d *dryRun) Produce(ctx *models.ListenerContext, _ time.Time, messages []sKafka.Message) {
+	d.write(messages)
+	ctx.Ack()
+}
+
+func (d *dryRun) write(messages []sKafka.Message) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for _, message := range messages {
+		if d.err != nil || (d.limit > 0 && d.count >= d.limit) {
+			return
+		}
+
+		m := dryRunMessage{Topic: message.Topic, Key: string(message.Key), Value: string(message.Value)}
+		if len(message.Headers) > 0 {
+			m.Headers = make(map[string]string, len(message.Headers))
+			for _, header := range message.Headers {
+				m.Headers[header.Key] = string(header.Value)
+			}
+		}
+
+		if d.err = d.encoder.Encode(m); d.err != nil {
+			close(d.done)
+			return
+		}
+		if d.count++; d.count == d.limit {
+			close(d.done)
+		}
+	}
+}
+
+// DryRun streams the events through the connector and writes up to limit mapped messages to out as JSON lines
+// instead of producing them, until ctx is done. Nothing is produced, see standaloneConfig for the disabled features.
+// Claim check, schema registry, value compression, encryption and topic creation are disabled too, so the values are
+// printed as mapped, as are the oversized documents left to the claim check.
+func DryRun(ctx context.Context, builder ConnectorBuilder, limit int, out io.Writer) error {
+	c, err := standaloneConfig(builder)
+	if err != nil {
+		return err
+	}
+	c.Kafka.ClaimCheck.Enabled = false
+	c.Kafka.SchemaRegistry.Enabled = false
+	c.Kafka.TopicCreation.Enabled = false
+	c.Kafka.ValueCompression.Enabled = false
+	c.Kafka.Encryption.Enabled = false
+	if c.Kafka.DocumentSizeLimit.Policy == config.DocumentSizePolicyClaimCheck {
+		c.Kafka.DocumentSizeLimit.MaxSize = 0
+	}
+	builder.config = c
+	builder.metricSink = nil
+
+	built, err := newConnector(builder)
+	if err != nil {
+		return err
+	}
+	connector := built.(*connector)
+
+	d := &dryRun{encoder: jsoniter.NewEncoder(out), done: make(chan struct{}), limit: limit}
+	connector.sink = d
+	runStandalone(ctx, connector, d.done)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.err
+}
+
+// standaloneConfig copies the config of the builder for a connector running next to the deployed ones, e.g. a dry run
+// or a replay. It streams as a single member on read only checkpoints, without the admin apis, debug server,
+// active-passive, hot reload, lag metric, metric push, dedup and reports. The callers clear the metric sink of the builder.
+func standaloneConfig(builder ConnectorBuilder) (*config.Connector, error) {
+	c, err := newConfig(builder.config)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := *c
+	cc.Dcp.Metadata.ReadOnly = true
+	cc.Dcp.Dcp.Group.Membership = dcpConfig.DCPGroupMembership{
+		Type: membership.StaticMembershipType, MemberNumber: 1, TotalMembers: 1,
+	}
+	cc.Dcp.LeaderElection.Enabled = false
+	cc.Dcp.API.Disabled = true
+	cc.Kafka.AdminAPI.Enabled = false
+	cc.Kafka.GRPCAPI.Enabled = false
+	cc.Kafka.Debug.Enabled = false
+	cc.Kafka.ActivePassive.Enabled = false
+	cc.Kafka.HotReload.Enabled = false
+	cc.Kafka.LagMetric.Enabled = false
+	cc.Kafka.Metrics.Sink = config.MetricsSinkPrometheus
+	cc.Kafka.Dedup.Enabled = false
+	cc.Kafka.SeqNoDedup.Enabled = false
+	cc.Kafka.StartupReport = false
+	cc.Kafka.ShutdownReportPath = ""
+	return &cc, nil
+}
+
+// runStandalone streams until done is closed or ctx is done, then closes the connector.
+func runStandalone(ctx context.Context, connector *connector, done <-chan struct{}) {
+	go func() {
+		<-connector.dcp.WaitUntilReady()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		connector.Close()
+	}()
+	connector.Start()
+}
@@ -0,0 +1,68 @@
+package dcpkafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/encryption"
+)
+
+// encryptor encrypts the values of the configured topics, every topic when none is configured.
+type encryptor struct {
+	envelope *encryption.Encryptor
+	topics   map[string]struct{}
+}
+
+func newEncryptor(encryptionConfig *config.Encryption, kms encryption.KMS) (*encryptor, error) {
+	if kms == nil {
+		switch encryptionConfig.Type {
+		case encryption.KMSTypeAWS:
+			if encryptionConfig.KeyID == "" {
+				return nil, errors.New("kafka.encryption.keyID must be set for the aws kms")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), encryptionConfig.Timeout)
+			defer cancel()
+
+			var err error
+			if kms, err = encryption.NewAWSKMS(ctx, encryptionConfig.KeyID, encryptionConfig.Region); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid kms type: %s", encryptionConfig.Type)
+		}
+	}
+
+	var topics map[string]struct{}
+	if len(encryptionConfig.Topics) > 0 {
+		topics = make(map[string]struct{}, len(encryptionConfig.Topics))
+		for _, topic := range encryptionConfig.Topics {
+			topics[topic] = struct{}{}
+		}
+	}
+	return &encryptor{
+		envelope: encryption.NewEncryptor(kms, encryptionConfig.DataKeyTTL, encryptionConfig.Timeout),
+		topics:   topics,
+	}, nil
+}
+
+// encryptValues runs after the value compression, since encrypted values do not compress.
+func (c *connector) encryptValues(messages []sKafka.Message) {
+	for i := range messages {
+		if messages[i].Value == nil {
+			continue
+		}
+		if _, ok := c.encryptor.topics[messages[i].Topic]; c.encryptor.topics != nil && !ok {
+			continue
+		}
+
+		if err := c.encryptor.envelope.Encrypt(&messages[i]); err != nil {
+			panic(fmt.Errorf("encryption error, key: %s, err: %w", messages[i].Key, err))
+		}
+		atomic.AddInt64(&c.producer.GetMetric().EncryptedValues, 1)
+	}
+}
@@ -0,0 +1,149 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KeyHeader holds the wrapped data key of an encrypted value.
+const KeyHeader = "dcp-kafka-encryption-key"
+
+type dataKey struct {
+	createdAt time.Time
+	aead      cipher.AEAD
+	wrapped   []byte
+}
+
+// Encryptor encrypts the values with AES-256-GCM under a data key of the KMS, prefixed with the nonce. The message
+// key is authenticated with the value, so an encrypted value cannot be moved to another key. A data key is used for
+// keyTTL before a new one is generated, so the KMS is not called for every message.
+type Encryptor struct {
+	kms     KMS
+	key     *dataKey
+	timeout time.Duration
+	keyTTL  time.Duration
+	lock    sync.Mutex
+}
+
+func NewEncryptor(kms KMS, keyTTL time.Duration, timeout time.Duration) *Encryptor {
+	return &Encryptor{kms: kms, keyTTL: keyTTL, timeout: timeout}
+}
+
+// Encrypt replaces the value with its ciphertext and adds the wrapped data key in the KeyHeader.
+func (e *Encryptor) Encrypt(message *kafka.Message) error {
+	key, err := e.dataKey()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, key.aead.NonceSize(), key.aead.NonceSize()+len(message.Value)+key.aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	headers := make([]kafka.Header, 0, len(message.Headers)+1)
+	message.Headers = append(append(headers, message.Headers...), kafka.Header{Key: KeyHeader, Value: key.wrapped})
+	message.Value = key.aead.Seal(nonce, nonce, message.Value, message.Key)
+	return nil
+}
+
+func (e *Encryptor) dataKey() (*dataKey, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.key != nil && time.Since(e.key.createdAt) < e.keyTTL {
+		return e.key, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	plaintext, wrapped, err := e.kms.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	e.key = &dataKey{createdAt: time.Now(), aead: aead, wrapped: wrapped}
+	return e.key, nil
+}
+
+// Decryptor restores the values encrypted by an Encryptor, for consumers. The unwrapped data keys are cached by their
+// wrapped form, so the KMS is called once per data key.
+type Decryptor struct {
+	kms  KMS
+	keys map[string]cipher.AEAD
+	lock sync.Mutex
+}
+
+func NewDecryptor(kms KMS) *Decryptor {
+	return &Decryptor{kms: kms, keys: map[string]cipher.AEAD{}}
+}
+
+// Decrypt replaces the value of a message with a KeyHeader with its plaintext, other messages are kept as is.
+func (d *Decryptor) Decrypt(ctx context.Context, message *kafka.Message) error {
+	var wrapped []byte
+	for _, header := range message.Headers {
+		if header.Key == KeyHeader {
+			wrapped = header.Value
+		}
+	}
+	if wrapped == nil {
+		return nil
+	}
+
+	aead, err := d.aead(ctx, wrapped)
+	if err != nil {
+		return err
+	}
+	if len(message.Value) < aead.NonceSize() {
+		return errors.New("encrypted value is shorter than the nonce")
+	}
+
+	nonce, ciphertext := message.Value[:aead.NonceSize()], message.Value[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, message.Key)
+	if err != nil {
+		return err
+	}
+	message.Value = value
+	return nil
+}
+
+func (d *Decryptor) aead(ctx context.Context, wrapped []byte) (cipher.AEAD, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if aead, ok := d.keys[string(wrapped)]; ok {
+		return aead, nil
+	}
+
+	plaintext, err := d.kms.Decrypt(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	d.keys[string(wrapped)] = aead
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,65 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+const KMSTypeAWS = "aws"
+
+// KMS generates the data keys the values are encrypted with and unwraps them again. The wrapped key is produced
+// with the message, the plaintext key never leaves the process.
+type KMS interface {
+	GenerateDataKey(ctx context.Context) (plaintext []byte, wrapped []byte, err error)
+	Decrypt(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+type awsKMS struct {
+	client *kms.Client
+	keyID  string
+}
+
+// NewAWSKMS wraps the data keys with the AWS KMS key keyID, the credentials are resolved by the default chain of the
+// AWS SDK, e.g. the environment or the web identity of the pod.
+func NewAWSKMS(ctx context.Context, keyID string, region string) (KMS, error) {
+	var options []func(*awsConfig.LoadOptions) error
+	if region != "" {
+		options = append(options, awsConfig.WithRegion(region))
+	}
+
+	cfg, err := awsConfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &awsKMS{client: kms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (k *awsKMS) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	output, err := k.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(k.keyID),
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return output.Plaintext, output.CiphertextBlob, nil
+}
+
+func (k *awsKMS) Decrypt(ctx context.Context, wrapped []byte) ([]byte, error) {
+	output, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob: wrapped,
+		KeyId:          aws.String(k.keyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Plaintext) == 0 {
+		return nil, errors.New("kms returned an empty data key")
+	}
+	return output.Plaintext, nil
+}
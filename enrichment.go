@@ -0,0 +1,64 @@
+package dcpkafka
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/enrichment"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/redis/go-redis/v9"
+)
+
+func newEnricher(c *config.Connector) (*enrichment.Enricher, error) {
+	cache, err := newEnrichmentCache(&c.Kafka.Enrichment.Cache)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := dcpCouchbase.CreateAgent(
+		c.Dcp.Hosts, c.Kafka.Enrichment.BucketName, c.Dcp.Username, c.Dcp.Password, c.Dcp.SecureConnection, c.Dcp.RootCAPath,
+		uint(helpers.ResolveUnionIntOrStringValue(c.Dcp.ConnectionBufferSize)), c.Dcp.ConnectionTimeout,
+	)
+	if err != nil {
+		return nil, errors.Join(err, cache.Close())
+	}
+
+	enricher, err := enrichment.NewEnricher(agent, cache, c.Kafka.Enrichment)
+	if err != nil {
+		return nil, errors.Join(err, agent.Close(), cache.Close())
+	}
+	return enricher, nil
+}
+
+func newEnrichmentCache(cacheConfig *config.EnrichmentCache) (enrichment.Cache, error) {
+	switch cacheConfig.Type {
+	case enrichment.CacheTypeMemory:
+		return enrichment.NewMemoryCache(cacheConfig.TTL, cacheConfig.NegativeTTL, cacheConfig.MaxSize), nil
+	case enrichment.CacheTypeRedis:
+		client := redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:    cacheConfig.Redis.Addresses,
+			Username: cacheConfig.Redis.Username,
+			Password: cacheConfig.Redis.Password,
+			DB:       cacheConfig.Redis.DB,
+		})
+		return enrichment.NewRedisCache(client, cacheConfig.Redis.KeyPrefix, cacheConfig.TTL, cacheConfig.NegativeTTL), nil
+	default:
+		return nil, fmt.Errorf("invalid enrichment cache type: %s", cacheConfig.Type)
+	}
+}
+
+// enrich keeps the original value when enrichment fails, the failure is counted in the metrics.
+func (c *connector) enrich(e *couchbase.Event) {
+	enriched, err := c.enricher.Enrich(e.Value)
+	if err != nil {
+		atomic.AddInt64(&c.producer.GetMetric().EnrichmentErrors, 1)
+		logging.Error(append(eventFields(e), logging.Err(err)), "cannot enrich document")
+		return
+	}
+	e.Value = enriched
+}
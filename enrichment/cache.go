@@ -0,0 +1,88 @@
+package enrichment
+
+import (
+	"sync"
+	"time"
+)
+
+const CacheTypeMemory = "memory"
+
+// Cache stores looked up documents by Couchbase key. A nil value is a valid entry for a missing document,
+// which is kept for the negative TTL.
+type Cache interface {
+	Get(key string) (value []byte, found bool)
+	Set(key string, value []byte)
+	Close() error
+}
+
+type memoryCacheEntry struct {
+	expiresAt time.Time
+	value     []byte
+}
+
+type memoryCache struct {
+	entries     map[string]memoryCacheEntry
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxSize     int
+	lock        sync.Mutex
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *memoryCache) Set(key string, value []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.entries) >= c.maxSize {
+		c.evict()
+	}
+	ttl := c.ttl
+	if value == nil {
+		ttl = c.negativeTTL
+	}
+	c.entries[key] = memoryCacheEntry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// evict removes expired entries, or an arbitrary one if none is expired.
+func (c *memoryCache) Close() error {
+	return nil
+}
+
+func (c *memoryCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	for key := range c.entries {
+		if len(c.entries) < c.maxSize {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
+
+func NewMemoryCache(ttl time.Duration, negativeTTL time.Duration, maxSize int) Cache {
+	return &memoryCache{
+		entries:     make(map[string]memoryCacheEntry, maxSize),
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		maxSize:     maxSize,
+	}
+}
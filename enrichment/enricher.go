@@ -0,0 +1,157 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type lookup struct {
+	keyTemplate    *template.Template
+	field          string
+	scopeName      string
+	collectionName string
+}
+
+// Enricher embeds documents referenced by the produced document, fetched from Couchbase by rendered key templates.
+type Enricher struct {
+	agent   *gocbcore.Agent
+	cache   Cache
+	lookups []lookup
+	timeout time.Duration
+}
+
+func NewEnricher(agent *gocbcore.Agent, cache Cache, enrichmentConfig config.Enrichment) (*Enricher, error) {
+	e := &Enricher{
+		agent:   agent,
+		cache:   cache,
+		timeout: enrichmentConfig.Timeout,
+	}
+
+	for _, l := range enrichmentConfig.Lookups {
+		keyTemplate, err := template.New(l.Field).Option("missingkey=error").Parse(l.KeyTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		e.lookups = append(e.lookups, lookup{
+			keyTemplate:    keyTemplate,
+			field:          l.Field,
+			scopeName:      l.ScopeName,
+			collectionName: l.CollectionName,
+		})
+	}
+
+	return e, nil
+}
+
+// Enrich returns the document with every lookup field set to the referenced document, or null when it is missing.
+// Lookups whose key cannot be rendered from the document are left out.
+func (e *Enricher) Enrich(value []byte) ([]byte, error) {
+	decoder := jsoniter.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(e.lookups))
+	for i, l := range e.lookups {
+		var key bytes.Buffer
+		if err := l.keyTemplate.Execute(&key, document); err != nil {
+			logger.Log.Debug("cannot render enrichment key, field: %s, err: %v", l.field, err)
+			continue
+		}
+		keys[i] = key.String()
+	}
+
+	results, err := e.fetch(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, l := range e.lookups {
+		if keys[i] == "" {
+			continue
+		}
+		if results[i] == nil {
+			document[l.field] = nil
+		} else {
+			document[l.field] = jsoniter.RawMessage(results[i])
+		}
+	}
+
+	return jsoniter.Marshal(document)
+}
+
+// fetch gets all uncached keys concurrently, only the fetched ones are cached so hits keep their expiry.
+func (e *Enricher) fetch(keys []string) ([][]byte, error) {
+	results := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	fetched := make([]bool, len(keys))
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		if key == "" {
+			continue
+		}
+		if value, found := e.cache.Get(e.cacheKey(i, key)); found {
+			results[i] = value
+			continue
+		}
+
+		fetched[i] = true
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			results[i], errs[i] = e.get(ctx, e.lookups[i], key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		if fetched[i] {
+			e.cache.Set(e.cacheKey(i, keys[i]), results[i])
+		}
+	}
+
+	return results, nil
+}
+
+func (e *Enricher) get(ctx context.Context, l lookup, key string) ([]byte, error) {
+	value, err := dcpCouchbase.Get(ctx, e.agent, l.scopeName, l.collectionName, []byte(key))
+	if errors.Is(err, gocbcore.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (e *Enricher) cacheKey(i int, key string) string {
+	return e.lookups[i].scopeName + ":" + e.lookups[i].collectionName + ":" + key
+}
+
+func (e *Enricher) Close() {
+	if err := e.agent.Close(); err != nil {
+		logger.Log.Error("error while closing enrichment agent: %v", err)
+	}
+	if err := e.cache.Close(); err != nil {
+		logger.Log.Error("error while closing enrichment cache: %v", err)
+	}
+}
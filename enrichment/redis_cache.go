@@ -0,0 +1,75 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+const CacheTypeRedis = "redis"
+
+// entries are prefixed to tell a missing document apart from an empty one
+const (
+	redisMissingPrefix = '0'
+	redisFoundPrefix   = '1'
+)
+
+// redisCache shares lookup results between connector replicas. Redis errors are treated as cache misses.
+type redisCache struct {
+	client      redis.UniversalClient
+	keyPrefix   string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	timeout     time.Duration
+}
+
+func (c *redisCache) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Log.Error("enrichment redis cache get error: %v", err)
+		}
+		return nil, false
+	}
+
+	if len(value) == 0 || value[0] == redisMissingPrefix {
+		return nil, true
+	}
+	return value[1:], true
+}
+
+func (c *redisCache) Set(key string, value []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	entry, ttl := []byte{redisMissingPrefix}, c.negativeTTL
+	if value != nil {
+		entry, ttl = append([]byte{redisFoundPrefix}, value...), c.ttl
+	}
+
+	if err := c.client.Set(ctx, c.keyPrefix+key, entry, ttl).Err(); err != nil {
+		logger.Log.Error("enrichment redis cache set error: %v", err)
+	}
+}
+
+// Close closes the client, which is owned by the cache.
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
+
+func NewRedisCache(client redis.UniversalClient, keyPrefix string, ttl time.Duration, negativeTTL time.Duration) Cache {
+	return &redisCache{
+		client:      client,
+		keyPrefix:   keyPrefix,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		timeout:     time.Second,
+	}
+}
@@ -0,0 +1,73 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const ExpirationEnvelopeOp = "expire"
+
+type expirationEnvelope struct {
+	EventTime  time.Time `json:"eventTime"`
+	Op         string    `json:"op"`
+	Key        string    `json:"key"`
+	Scope      string    `json:"scope"`
+	Collection string    `json:"collection"`
+	Cas        uint64    `json:"cas"`
+	SeqNo      uint64    `json:"seqNo"`
+	VbID       uint16    `json:"vbId"`
+}
+
+// handleExpiration returns false when the expiration goes through the mapper, or kafka.tombstone when it is enabled.
+func (c *connector) handleExpiration(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	switch c.config.Kafka.GetExpirationPolicy() {
+	case config.ExpirationPolicyTombstone:
+		c.produceTombstone(ctx, e)
+	case config.ExpirationPolicyEnvelope:
+		c.produceExpirationEnvelope(ctx, e)
+	case config.ExpirationPolicyDrop:
+		ctx.Ack()
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *connector) produceExpirationEnvelope(ctx *models.ListenerContext, e *couchbase.Event) {
+	value, err := jsoniter.Marshal(expirationEnvelope{
+		EventTime:  e.EventTime,
+		Op:         ExpirationEnvelopeOp,
+		Key:        string(e.Key),
+		Scope:      e.ScopeName,
+		Collection: e.CollectionName,
+		Cas:        e.Cas,
+		SeqNo:      e.SeqNo,
+		VbID:       e.VbID,
+	})
+	if err != nil {
+		panic(fmt.Errorf("expiration envelope error, key: %s, err: %w", e.Key, err))
+	}
+
+	envelope := sKafka.Message{
+		Topic: c.getTopicName(e.CollectionName, ""),
+		Key:   e.Key,
+		Value: value,
+	}
+
+	if c.keyOf != nil {
+		envelope.Key = c.keyOf(e)
+	}
+
+	if c.config.Kafka.DcpMetadataHeaders {
+		envelope.Headers = newDcpMetadataHeaders(e)
+	}
+
+	c.sink.Produce(ctx, e.EventTime, []sKafka.Message{envelope})
+}
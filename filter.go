@@ -0,0 +1,118 @@
+package dcpkafka
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// applyFilter acks events that do not match the filter expression and returns false for them.
+// Events that cannot be evaluated are produced, so an unexpected document shape does not drop data.
+func (c *connector) applyFilter(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	match, err := c.filter.Match(e)
+	if err != nil {
+		logging.Error(append(eventFields(e), logging.Err(err)), "cannot evaluate filter expression")
+		return true
+	}
+
+	if !match {
+		atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+		ctx.Ack()
+	}
+	return match
+}
+
+// skipEventType acks the events whose type is not in kafka.eventTypes, when it is set.
+func (c *connector) skipEventType(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	eventTypes := c.config.Kafka.EventTypes
+	if len(eventTypes) == 0 {
+		return false
+	}
+
+	eventType := e.EventType()
+	for _, produced := range eventTypes {
+		if produced == eventType {
+			return false
+		}
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+	ctx.Ack()
+	return true
+}
+
+type keyFilter struct {
+	include         []*regexp.Regexp
+	exclude         []*regexp.Regexp
+	includePrefixes []string
+	excludePrefixes []string
+}
+
+func newKeyFilter(filterConfig config.KeyFilter) (*keyFilter, error) {
+	if len(filterConfig.Include) == 0 && len(filterConfig.Exclude) == 0 &&
+		len(filterConfig.IncludePrefixes) == 0 && len(filterConfig.ExcludePrefixes) == 0 {
+		return nil, nil
+	}
+
+	f := &keyFilter{includePrefixes: filterConfig.IncludePrefixes, excludePrefixes: filterConfig.ExcludePrefixes}
+	var err error
+	if f.include, err = compilePatterns(filterConfig.Include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compilePatterns(filterConfig.Exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+func (f *keyFilter) allows(key []byte) bool {
+	if matchesKey(key, f.exclude, f.excludePrefixes) {
+		return false
+	}
+	if len(f.include) == 0 && len(f.includePrefixes) == 0 {
+		return true
+	}
+	return matchesKey(key, f.include, f.includePrefixes)
+}
+
+func matchesKey(key []byte, regexes []*regexp.Regexp, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	for _, regex := range regexes {
+		if regex.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipFilteredKey acks the events of document keys excluded by kafka.keyFilter before they are mapped.
+func (c *connector) skipFilteredKey(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.keyFilter == nil || c.keyFilter.allows(e.Key) {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+	ctx.Ack()
+	return true
+}
@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/vm"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+)
+
+// Expression matches events with a boolean expression(https://expr-lang.org) over
+// key, value(decoded JSON document, integers are int64 so they compare exactly), scope, collection, eventType, cas, seqNo, revNo and vbId.
+type Expression struct {
+	program   *vm.Program
+	usesValue bool
+}
+
+func NewExpression(expression string) (*Expression, error) {
+	program, err := expr.Compile(expression, expr.AsBool(), expr.Env(expressionEnv{}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter expression: %w", err)
+	}
+
+	finder := &identifierFinder{name: "value"}
+	ast.Walk(&program.Node, finder)
+
+	return &Expression{
+		program:   program,
+		usesValue: finder.found,
+	}, nil
+}
+
+// Match decodes the document only when the expression refers to the value.
+func (f *Expression) Match(e *couchbase.Event) (bool, error) {
+	env := expressionEnv{
+		Key:        string(e.Key),
+		Scope:      e.ScopeName,
+		Collection: e.CollectionName,
+		EventType:  e.EventType(),
+		Cas:        e.Cas,
+		SeqNo:      e.SeqNo,
+		RevNo:      e.RevNo,
+		VbID:       e.VbID,
+	}
+
+	if f.usesValue && len(e.Value) > 0 {
+		value, err := document.Decode(e.Value)
+		if err != nil {
+			return false, err
+		}
+		env.Value = value
+	}
+
+	result, err := expr.Run(f.program, env)
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+// expressionEnv also declares the variables on compile, so unknown identifiers are rejected on the start.
+type expressionEnv struct {
+	Value      any    `expr:"value"`
+	Key        string `expr:"key"`
+	Scope      string `expr:"scope"`
+	Collection string `expr:"collection"`
+	EventType  string `expr:"eventType"`
+	Cas        uint64 `expr:"cas"`
+	SeqNo      uint64 `expr:"seqNo"`
+	RevNo      uint64 `expr:"revNo"`
+	VbID       uint16 `expr:"vbId"`
+}
+
+type identifierFinder struct {
+	name  string
+	found bool
+}
+
+func (v *identifierFinder) Visit(node *ast.Node) {
+	if identifier, ok := (*node).(*ast.IdentifierNode); ok && identifier.Value == v.name {
+		v.found = true
+	}
+}
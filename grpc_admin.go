@@ -0,0 +1,107 @@
+package dcpkafka
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	jsoniter "github.com/json-iterator/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/Trendyol/go-dcp-kafka/grpcapi"
+	"github.com/Trendyol/go-dcp-kafka/pause"
+)
+
+// grpcAdmin serves the operations of the HTTP admin api, plus flush, checkpoint and config introspection.
+type grpcAdmin struct {
+	connector *connector
+}
+
+func (a *grpcAdmin) Pause(_ context.Context, request *structpb.Struct) (*structpb.Struct, error) {
+	s, err := pauseStateOf(request)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := a.connector.pauser.Pause(s); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return a.pauseState()
+}
+
+func (a *grpcAdmin) Resume(_ context.Context, request *structpb.Struct) (*structpb.Struct, error) {
+	s, err := pauseStateOf(request)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := a.connector.pauser.Resume(s); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return a.pauseState()
+}
+
+func (a *grpcAdmin) Flush(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	batch := a.connector.producer.ProducerBatch
+	batch.FlushMessages()
+	return toStatusStruct(map[string]int{"pending": batch.Pending()})
+}
+
+// GetCheckpoints reads the saved checkpoints over a new connection, the in-memory offsets of go-dcp are not exposed.
+func (a *grpcAdmin) GetCheckpoints(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	snapshot, err := ExportCheckpoints(a.connector.config)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return toStatusStruct(snapshot)
+}
+
+func (a *grpcAdmin) GetMetrics(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	return toStatusStruct(metricSnapshot(a.connector.producer.GetMetric()))
+}
+
+func (a *grpcAdmin) GetConfig(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	fields, err := grpcapi.YAMLToStruct(a.connector.config.Redacted())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return fields, nil
+}
+
+func (a *grpcAdmin) pauseState() (*structpb.Struct, error) {
+	return toStatusStruct(map[string]any{
+		"state": a.connector.pauser.State(),
+		"held":  a.connector.pauser.HeldCounts(),
+	})
+}
+
+func pauseStateOf(request *structpb.Struct) (pause.State, error) {
+	var s pause.State
+	data, err := request.MarshalJSON()
+	if err != nil {
+		return s, err
+	}
+	err = jsoniter.Unmarshal(data, &s)
+	return s, err
+}
+
+// metricSnapshot reads the counters of the producer metric, keyed by field name.
+func metricSnapshot(metric any) map[string]int64 {
+	value := reflect.ValueOf(metric).Elem()
+	snapshot := make(map[string]int64, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Field(i); field.Kind() == reflect.Int64 {
+			snapshot[value.Type().Field(i).Name] = atomic.LoadInt64(field.Addr().Interface().(*int64))
+		}
+	}
+	return snapshot
+}
+
+func toStatusStruct(v any) (*structpb.Struct, error) {
+	fields, err := grpcapi.ToStruct(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return fields, nil
+}
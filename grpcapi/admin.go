@@ -0,0 +1,119 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const serviceName = "dcpkafka.admin.v1.Admin"
+
+// AdminServer is the service of admin.proto. The messages are well-known types, so the service is declared by hand
+// instead of generated.
+type AdminServer interface {
+	Pause(ctx context.Context, request *structpb.Struct) (*structpb.Struct, error)
+	Resume(ctx context.Context, request *structpb.Struct) (*structpb.Struct, error)
+	Flush(ctx context.Context, request *emptypb.Empty) (*structpb.Struct, error)
+	GetCheckpoints(ctx context.Context, request *emptypb.Empty) (*structpb.Struct, error)
+	GetMetrics(ctx context.Context, request *emptypb.Empty) (*structpb.Struct, error)
+	GetConfig(ctx context.Context, request *emptypb.Empty) (*structpb.Struct, error)
+}
+
+func RegisterAdminServer(registrar grpc.ServiceRegistrar, server AdminServer) {
+	registrar.RegisterService(&adminServiceDesc, server)
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		structMethod("Pause", AdminServer.Pause),
+		structMethod("Resume", AdminServer.Resume),
+		emptyMethod("Flush", AdminServer.Flush),
+		emptyMethod("GetCheckpoints", AdminServer.GetCheckpoints),
+		emptyMethod("GetMetrics", AdminServer.GetMetrics),
+		emptyMethod("GetConfig", AdminServer.GetConfig),
+	},
+	Metadata: "grpcapi/admin.proto",
+}
+
+type handler[T any] func(AdminServer, context.Context, T) (*structpb.Struct, error)
+
+func structMethod(name string, call handler[*structpb.Struct]) grpc.MethodDesc {
+	return method(name, func() *structpb.Struct { return &structpb.Struct{} }, call)
+}
+
+func emptyMethod(name string, call handler[*emptypb.Empty]) grpc.MethodDesc {
+	return method(name, func() *emptypb.Empty { return &emptypb.Empty{} }, call)
+}
+
+func method[T any](name string, newRequest func() T, call handler[T]) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(
+			server any, ctx context.Context, decode func(any) error, interceptor grpc.UnaryServerInterceptor,
+		) (any, error) {
+			request := newRequest()
+			if err := decode(request); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(server.(AdminServer), ctx, request)
+			}
+
+			info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + serviceName + "/" + name}
+			return interceptor(ctx, request, info, func(ctx context.Context, request any) (any, error) {
+				return call(server.(AdminServer), ctx, request.(T))
+			})
+		},
+	}
+}
+
+// AdminClient calls the admin service of a connector, e.g. for fleet tooling.
+type AdminClient struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewAdminClient(conn grpc.ClientConnInterface) *AdminClient {
+	return &AdminClient{conn: conn}
+}
+
+func (c *AdminClient) Pause(
+	ctx context.Context, request *structpb.Struct, opts ...grpc.CallOption,
+) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "Pause", request, opts)
+}
+
+func (c *AdminClient) Resume(
+	ctx context.Context, request *structpb.Struct, opts ...grpc.CallOption,
+) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "Resume", request, opts)
+}
+
+func (c *AdminClient) Flush(ctx context.Context, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "Flush", &emptypb.Empty{}, opts)
+}
+
+func (c *AdminClient) GetCheckpoints(ctx context.Context, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "GetCheckpoints", &emptypb.Empty{}, opts)
+}
+
+func (c *AdminClient) GetMetrics(ctx context.Context, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "GetMetrics", &emptypb.Empty{}, opts)
+}
+
+func (c *AdminClient) GetConfig(ctx context.Context, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	return invoke(ctx, c.conn, "GetConfig", &emptypb.Empty{}, opts)
+}
+
+func invoke(
+	ctx context.Context, conn grpc.ClientConnInterface, name string, request any, opts []grpc.CallOption,
+) (*structpb.Struct, error) {
+	response := &structpb.Struct{}
+	if err := conn.Invoke(ctx, "/"+serviceName+"/"+name, request, response, opts...); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
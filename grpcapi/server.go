@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type Server struct {
+	server *grpc.Server
+	port   int
+}
+
+func (s *Server) Listen() {
+	logger.Log.Info("grpc api starting on port %d", s.port)
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		logger.Log.Error("grpc api cannot start on port %d, err: %v", s.port, err)
+		return
+	}
+
+	if err = s.server.Serve(listener); err != nil {
+		logger.Log.Error("grpc api stopped, err: %v", err)
+	} else {
+		logger.Log.Info("grpc api stopped")
+	}
+}
+
+// Shutdown waits up to the timeout for the calls in progress, e.g. a flush.
+func (s *Server) Shutdown(timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		s.server.Stop()
+	}
+}
+
+func NewServer(port int, admin AdminServer, opts ...grpc.ServerOption) *Server {
+	server := grpc.NewServer(opts...)
+	RegisterAdminServer(server, admin)
+	return &Server{server: server, port: port}
+}
+
+// ToStruct converts v through its JSON, so the fields match the HTTP admin api.
+func ToStruct(v any) (*structpb.Struct, error) {
+	data, err := jsoniter.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := jsoniter.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
+
+// YAMLToStruct converts v through its YAML, for values with yaml tags only such as the config.
+func YAMLToStruct(v any) (*structpb.Struct, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields any
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return ToStruct(stringKeys(fields))
+}
+
+// stringKeys converts the maps with non-string keys of yaml, e.g. vBucket ids, for the JSON conversion.
+func stringKeys(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = stringKeys(item)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = stringKeys(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
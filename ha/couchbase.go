@@ -0,0 +1,179 @@
+package ha
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	jsoniter "github.com/json-iterator/go"
+
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type leaseDocument struct {
+	RenewedAt time.Time `json:"renewedAt"`
+	Holder    string    `json:"holder"`
+}
+
+// couchbaseElector holds the lease as a document expiring after leaseDuration, which the leader replaces with its
+// cas. A standby creates the document once it has expired.
+type couchbaseElector struct {
+	agent          *gocbcore.Agent
+	scopeName      string
+	collectionName string
+	key            []byte
+	options        Options
+}
+
+func (e *couchbaseElector) Run(ctx context.Context, callbacks Callbacks) {
+	ticker := time.NewTicker(e.options.RetryPeriod)
+	defer ticker.Stop()
+
+	var cas gocbcore.Cas
+	var renewedAt time.Time
+
+	for {
+		if cas == 0 {
+			acquired, err := e.store(ctx, 0)
+			if err != nil && !errors.Is(err, gocbcore.ErrDocumentExists) {
+				logger.Log.Error("cannot acquire the lease: %v", err)
+			} else if err == nil {
+				cas, renewedAt = acquired, time.Now()
+				logger.Log.Info("acquired the lease as %s", e.options.Identity)
+				callbacks.OnStartedLeading()
+			}
+		} else {
+			renewed, err := e.store(ctx, cas)
+			switch {
+			case err == nil:
+				cas, renewedAt = renewed, time.Now()
+			case errors.Is(err, gocbcore.ErrCasMismatch) || errors.Is(err, gocbcore.ErrDocumentNotFound) ||
+				time.Since(renewedAt) >= e.options.LeaseDuration-e.options.RetryPeriod:
+				logger.Log.Error("lost the lease: %v", err)
+				callbacks.OnStoppedLeading()
+				return
+			default:
+				logger.Log.Error("cannot renew the lease: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if cas != 0 {
+				e.release(cas)
+				callbacks.OnStoppedLeading()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// store adds the lease document when cas is 0, otherwise replaces it.
+func (e *couchbaseElector) store(ctx context.Context, cas gocbcore.Cas) (gocbcore.Cas, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.options.RetryPeriod)
+	defer cancel()
+
+	value, err := jsoniter.Marshal(leaseDocument{Holder: e.options.Identity, RenewedAt: time.Now()})
+	if err != nil {
+		return 0, err
+	}
+
+	opm := dcpCouchbase.NewAsyncOp(ctx)
+	deadline, _ := ctx.Deadline()
+	expiry := uint32(math.Ceil(e.options.LeaseDuration.Seconds()))
+
+	ch := make(chan struct {
+		err error
+		cas gocbcore.Cas
+	}, 1)
+	callback := func(result *gocbcore.StoreResult, err error) {
+		opm.Resolve()
+
+		var resultCas gocbcore.Cas
+		if err == nil {
+			resultCas = result.Cas
+		}
+		ch <- struct {
+			err error
+			cas gocbcore.Cas
+		}{err: err, cas: resultCas}
+	}
+
+	var op gocbcore.PendingOp
+	if cas == 0 {
+		op, err = e.agent.Add(gocbcore.AddOptions{
+			Key:            e.key,
+			Value:          value,
+			Expiry:         expiry,
+			Deadline:       deadline,
+			ScopeName:      e.scopeName,
+			CollectionName: e.collectionName,
+		}, callback)
+	} else {
+		op, err = e.agent.Replace(gocbcore.ReplaceOptions{
+			Key:            e.key,
+			Value:          value,
+			Cas:            cas,
+			Expiry:         expiry,
+			Deadline:       deadline,
+			ScopeName:      e.scopeName,
+			CollectionName: e.collectionName,
+		}, callback)
+	}
+
+	if err = opm.Wait(op, err); err != nil {
+		return 0, err
+	}
+
+	result := <-ch
+	return result.cas, result.err
+}
+
+// release deletes the lease document, so a standby takes over without waiting for the expiry.
+func (e *couchbaseElector) release(cas gocbcore.Cas) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.options.RetryPeriod)
+	defer cancel()
+
+	opm := dcpCouchbase.NewAsyncOp(ctx)
+	deadline, _ := ctx.Deadline()
+	ch := make(chan error, 1)
+
+	op, err := e.agent.Delete(gocbcore.DeleteOptions{
+		Key:            e.key,
+		Cas:            cas,
+		Deadline:       deadline,
+		ScopeName:      e.scopeName,
+		CollectionName: e.collectionName,
+	}, func(_ *gocbcore.DeleteResult, err error) {
+		opm.Resolve()
+		ch <- err
+	})
+
+	if err = opm.Wait(op, err); err == nil {
+		err = <-ch
+	}
+	if err != nil {
+		logger.Log.Error("cannot release the lease: %v", err)
+	}
+}
+
+// NewCouchbaseElector keeps the lease document named key in the collection, e.g. next to the checkpoints.
+func NewCouchbaseElector(
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	key string,
+	options Options,
+) Elector {
+	return &couchbaseElector{
+		agent:          agent,
+		scopeName:      scopeName,
+		collectionName: collectionName,
+		key:            []byte(key),
+		options:        options,
+	}
+}
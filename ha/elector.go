@@ -0,0 +1,41 @@
+package ha
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	ElectorTypeKubernetes = "kubernetes"
+	ElectorTypeCouchbase  = "couchbase"
+)
+
+// Callbacks are called from the goroutine of Run. OnStoppedLeading is called once the leader stops renewing the
+// lease, including when ctx is done.
+type Callbacks struct {
+	OnStartedLeading func()
+	OnStoppedLeading func()
+}
+
+// Elector keeps at most one replica leading. A standby takes over at the latest leaseDuration after the leader
+// stopped renewing.
+type Elector interface {
+	// Run competes for the lease until ctx is done or the leadership is lost.
+	Run(ctx context.Context, callbacks Callbacks)
+}
+
+type Options struct {
+	Identity      string
+	LeaseDuration time.Duration
+	RetryPeriod   time.Duration
+}
+
+// DefaultIdentity is the hostname, the pod name on Kubernetes.
+func DefaultIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "dcp-kafka"
+	}
+	return hostname
+}
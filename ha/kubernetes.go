@@ -0,0 +1,51 @@
+package ha
+
+import (
+	"context"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientSet "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+type kubernetesElector struct {
+	client    clientSet.Interface
+	name      string
+	namespace string
+	options   Options
+}
+
+func (e *kubernetesElector) Run(ctx context.Context, callbacks Callbacks) {
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metaV1.ObjectMeta{Name: e.name, Namespace: e.namespace},
+			Client:     e.client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: e.options.Identity},
+		},
+		ReleaseOnCancel: true,
+		LeaseDuration:   e.options.LeaseDuration,
+		RenewDeadline:   e.options.LeaseDuration * 2 / 3,
+		RetryPeriod:     e.options.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { callbacks.OnStartedLeading() },
+			OnStoppedLeading: callbacks.OnStoppedLeading,
+		},
+	})
+}
+
+// NewKubernetesElector uses a coordination.k8s.io Lease with the in-cluster config of the pod.
+func NewKubernetesElector(name string, namespace string, options Options) (Elector, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := clientSet.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubernetesElector{client: client, name: name, namespace: namespace, options: options}, nil
+}
@@ -0,0 +1,167 @@
+package dcpkafka
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	jsoniter "github.com/json-iterator/go"
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+)
+
+const jsonPathPrefix = "$."
+
+// headerTemplate is a "$." json path or a text/template of the event, e.g. {{ .value.tenantId }}.
+type headerTemplate struct {
+	template *template.Template
+	key      string
+	path     []any
+}
+
+type headerTemplates []headerTemplate
+
+func newHeaderTemplates(headers map[string]string) (headerTemplates, error) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	templates := make(headerTemplates, 0, len(headers))
+	for _, key := range keys {
+		value := headers[key]
+		if strings.HasPrefix(value, jsonPathPrefix) {
+			templates = append(templates, headerTemplate{key: key, path: splitFieldPath(strings.TrimPrefix(value, jsonPathPrefix))})
+			continue
+		}
+
+		parsed, err := parseEventTemplate(key, value, nil)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, headerTemplate{key: key, template: parsed})
+	}
+	return templates, nil
+}
+
+// headers skips the headers evaluated to an empty value, e.g. a missing field or a deletion without a document.
+func (t headerTemplates) headers(e *couchbase.Event, isJSON bool) []sKafka.Header {
+	var data map[string]any
+	headers := make([]sKafka.Header, 0, len(t))
+
+	for i := range t {
+		var value string
+		if t[i].template == nil {
+			if !isJSON {
+				continue
+			}
+			value, _ = jsonPathValue(e.Value, t[i].path)
+		} else {
+			if data == nil {
+				data = templateData(e, isJSON)
+			}
+
+			var sb strings.Builder
+			if err := t[i].template.Execute(&sb, data); err != nil {
+				logging.Error(append(eventFields(e), logging.Err(err)), "cannot evaluate header template %s", t[i].key)
+				continue
+			}
+			value = sb.String()
+		}
+
+		if value != "" {
+			headers = append(headers, sKafka.Header{Key: t[i].key, Value: []byte(value)})
+		}
+	}
+	return headers
+}
+
+func jsonPathValue(document []byte, path []any) (string, bool) {
+	field := jsoniter.Get(document, path...)
+	if field.LastError() != nil {
+		return "", false
+	}
+	if field.ValueType() == jsoniter.StringValue {
+		return field.ToString(), true
+	}
+	return jsoniter.Wrap(field.GetInterface()).ToString(), true
+}
+
+// parseEventTemplate parses a template of the event data, every action ends with orEmpty so missing keys, like the
+// value of a deletion, are rendered empty instead of "<no value>".
+func parseEventTemplate(name string, text string, funcs template.FuncMap) (*template.Template, error) {
+	parsed, err := template.New(name).
+		Option("missingkey=zero").
+		Funcs(template.FuncMap{"orEmpty": orEmpty}).
+		Funcs(funcs).
+		Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range parsed.Templates() {
+		if t.Tree != nil {
+			appendOrEmpty(t.Tree, t.Tree.Root)
+		}
+	}
+	return parsed, nil
+}
+
+func appendOrEmpty(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			appendOrEmpty(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			identifier := parse.NewIdentifier("orEmpty").SetTree(tree).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{identifier}})
+		}
+	case *parse.IfNode:
+		appendOrEmpty(tree, n.List)
+		appendOrEmpty(tree, n.ElseList)
+	case *parse.RangeNode:
+		appendOrEmpty(tree, n.List)
+		appendOrEmpty(tree, n.ElseList)
+	case *parse.WithNode:
+		appendOrEmpty(tree, n.List)
+		appendOrEmpty(tree, n.ElseList)
+	}
+}
+
+func orEmpty(value any) any {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// templateData decodes the document with exact integers, so {{ json .value }} keeps the ones above 2^53.
+func templateData(e *couchbase.Event, isJSON bool) map[string]any {
+	var value map[string]any
+	if isJSON {
+		decoded, _ := document.Decode(e.Value)
+		value, _ = decoded.(map[string]any)
+	}
+
+	return map[string]any{
+		"key":        string(e.Key),
+		"scope":      e.ScopeName,
+		"collection": e.CollectionName,
+		"value":      value,
+		"cas":        e.Cas,
+		"seqNo":      e.SeqNo,
+		"vbId":       e.VbID,
+		"isDeleted":  e.IsDeleted,
+		"isExpired":  e.IsExpired,
+	}
+}
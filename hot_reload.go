@@ -0,0 +1,181 @@
+package dcpkafka
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// hotReloadableFields can change without restarting the dcp stream, every other change of the file is rejected.
+var hotReloadableFields = []string{
+	"kafka.producerBatchSize",
+	"kafka.producerBatchBytes",
+	"kafka.producerBatchTickerDuration",
+	"kafka.rateLimit",
+	"logging.level",
+}
+
+type hotReload struct {
+	connector *connector
+	loaded    *config.Connector
+	stop      chan struct{}
+	path      string
+	modTime   time.Time
+}
+
+// newHotReload parses the file again instead of keeping the running config, since go-dcp applies its defaults in place.
+func newHotReload(c *connector, cf any) (*hotReload, error) {
+	path, ok := cf.(string)
+	if !ok {
+		return nil, errors.New("hot reload requires the config to be a file path")
+	}
+
+	loaded, err := newConnectorConfigFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hotReload{
+		connector: c,
+		loaded:    loaded,
+		path:      path,
+		modTime:   info.ModTime(),
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Start reloads on SIGHUP and when the modification time of the file changes.
+func (h *hotReload) Start(interval time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				h.reload()
+			case <-ticker.C:
+				if info, err := os.Stat(h.path); err == nil && !info.ModTime().Equal(h.modTime) {
+					h.modTime = info.ModTime()
+					h.reload()
+				}
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (h *hotReload) Close() {
+	close(h.stop)
+}
+
+func (h *hotReload) reload() {
+	next, err := newConnectorConfigFromPath(h.path)
+	if err != nil {
+		logger.Log.Error("config reload error: %v", err)
+		return
+	}
+
+	if changed := restartRequiredChanges(h.loaded, next); len(changed) > 0 {
+		logger.Log.Error("config reload rejected, changes of %s require a restart, only %s can be reloaded",
+			strings.Join(changed, ", "), strings.Join(hotReloadableFields, ", "))
+		return
+	}
+
+	if err := h.connector.applyReloadedConfig(next); err != nil {
+		logger.Log.Error("config reload error: %v", err)
+		return
+	}
+
+	h.loaded = next
+	logger.Log.Info("config reloaded from %s", h.path)
+}
+
+// restartRequiredChanges returns the yaml names of the changed fields that are not hot reloadable.
+func restartRequiredChanges(current *config.Connector, next *config.Connector) []string {
+	comparable := *next
+	comparable.Kafka.ProducerBatchSize = current.Kafka.ProducerBatchSize
+	comparable.Kafka.ProducerBatchBytes = current.Kafka.ProducerBatchBytes
+	comparable.Kafka.ProducerBatchTickerDuration = current.Kafka.ProducerBatchTickerDuration
+	comparable.Kafka.RateLimit = current.Kafka.RateLimit
+	comparable.Dcp.Logging.Level = current.Dcp.Logging.Level
+
+	changed := changedFields("kafka.", reflect.ValueOf(current.Kafka), reflect.ValueOf(comparable.Kafka))
+	return append(changed, changedFields("", reflect.ValueOf(current.Dcp), reflect.ValueOf(comparable.Dcp))...)
+}
+
+func changedFields(prefix string, current reflect.Value, next reflect.Value) []string {
+	var changed []string
+	for i := 0; i < current.NumField(); i++ {
+		if reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		field := current.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		changed = append(changed, prefix+name)
+	}
+	return changed
+}
+
+func (c *connector) applyReloadedConfig(next *config.Connector) error {
+	if err := c.producer.GetRateLimiter().SetLimits(next.Kafka.RateLimit.MessagesPerSecond, next.Kafka.RateLimit.BytesPerSecond); err != nil {
+		return err
+	}
+
+	c.producer.ProducerBatch.SetLimits(
+		next.Kafka.ProducerBatchSize,
+		next.Kafka.ProducerBatchBytes,
+		next.Kafka.ProducerBatchTickerDuration,
+	)
+
+	c.config.Kafka.RateLimit = next.Kafka.RateLimit
+	c.config.Kafka.ProducerBatchSize = next.Kafka.ProducerBatchSize
+	c.config.Kafka.ProducerBatchBytes = next.Kafka.ProducerBatchBytes
+	c.config.Kafka.ProducerBatchTickerDuration = next.Kafka.ProducerBatchTickerDuration
+
+	if next.Dcp.Logging.Level != "" && next.Dcp.Logging.Level != c.config.Dcp.Logging.Level {
+		if err := setLogLevel(next.Dcp.Logging.Level); err != nil {
+			return err
+		}
+		c.config.Dcp.Logging.Level = next.Dcp.Logging.Level
+	}
+	return nil
+}
+
+// setLogLevel supports the logrus logger only, slog and zap levels belong to their handlers and cores.
+func setLogLevel(level string) error {
+	loggers, ok := logger.Log.(*logger.Loggers)
+	if !ok {
+		return fmt.Errorf("log level cannot be changed for %T", logger.Log)
+	}
+
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	loggers.Logrus.SetLevel(parsed)
+	return nil
+}
@@ -0,0 +1,124 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/apiversions"
+	"github.com/segmentio/kafka-go/protocol/saslauthenticate"
+	"github.com/segmentio/kafka-go/protocol/saslhandshake"
+	"github.com/segmentio/kafka-go/sasl"
+)
+
+// dialBroker opens an authenticated connection to the first reachable broker with the settings of the transport.
+// The transport serves the metadata requests from its cache, so the requests it cannot answer use this connection.
+func (c *client) dialBroker(ctx context.Context) (*protocol.Conn, error) {
+	transport := c.transport.current.Load()
+	dial := transport.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 5 * time.Second, DualStack: true}).DialContext
+	}
+
+	var err error
+	for _, broker := range c.config.Kafka.Brokers {
+		var conn *protocol.Conn
+		if conn, err = connectBroker(ctx, transport, dial, broker); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func connectBroker(ctx context.Context, transport *kafka.Transport, dial dialFunc, broker string) (*protocol.Conn, error) {
+	netConn, err := dial(ctx, "tcp", broker)
+	if err != nil {
+		return nil, err
+	}
+
+	host, port, err := net.SplitHostPort(broker)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if transport.TLS != nil {
+		tlsConfig := transport.TLS
+		if tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = host
+		}
+		netConn = tls.Client(netConn, tlsConfig)
+	}
+
+	conn := protocol.NewConn(netConn, transport.ClientID)
+	if err = negotiateVersions(conn); err == nil && transport.SASL != nil {
+		portNumber, _ := strconv.Atoi(port)
+		err = authenticate(sasl.WithMetadata(ctx, &sasl.Metadata{Host: host, Port: portNumber}), conn, transport.SASL)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("broker=%s, err=%w", broker, err)
+	}
+	return conn, nil
+}
+
+func negotiateVersions(conn *protocol.Conn) error {
+	response, err := conn.RoundTrip(&apiversions.Request{})
+	if err != nil {
+		return err
+	}
+
+	versionsResponse := response.(*apiversions.Response)
+	if versionsResponse.ErrorCode != 0 {
+		return kafka.Error(versionsResponse.ErrorCode)
+	}
+
+	versions := make(map[protocol.ApiKey]int16, len(versionsResponse.ApiKeys))
+	for _, apiKey := range versionsResponse.ApiKeys {
+		key := protocol.ApiKey(apiKey.ApiKey)
+		versions[key] = key.SelectVersion(apiKey.MinVersion, apiKey.MaxVersion)
+	}
+	conn.SetVersions(versions)
+	return nil
+}
+
+func authenticate(ctx context.Context, conn *protocol.Conn, mechanism sasl.Mechanism) error {
+	response, err := conn.RoundTrip(&saslhandshake.Request{Mechanism: mechanism.Name()})
+	if err != nil {
+		return err
+	}
+	if errorCode := response.(*saslhandshake.Response).ErrorCode; errorCode != 0 {
+		return kafka.Error(errorCode)
+	}
+
+	session, state, err := mechanism.Start(ctx)
+	if err != nil {
+		return err
+	}
+	for completed := false; !completed; {
+		response, err = conn.RoundTrip(&saslauthenticate.Request{AuthBytes: state})
+		if errors.Is(err, io.EOF) {
+			// the broker closes the connection when the exchange fails
+			return kafka.SASLAuthenticationFailed
+		}
+		if err != nil {
+			return err
+		}
+
+		authenticateResponse := response.(*saslauthenticate.Response)
+		if authenticateResponse.ErrorCode != 0 {
+			return fmt.Errorf("%w: %s", kafka.Error(authenticateResponse.ErrorCode), authenticateResponse.ErrorMessage)
+		}
+		if completed, state, err = session.Next(ctx, authenticateResponse.AuthBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,17 +6,20 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
-	"math"
 	"net"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go/sasl"
 
 	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/secret"
 	"github.com/Trendyol/go-dcp/logger"
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/sasl/scram"
 )
 
 type Client interface {
@@ -24,17 +27,26 @@ type Client interface {
 	GetPartitions(topic string) ([]int, error)
 	CreateCompactedTopic(topic string, partition int, replicationFactor int) error
 	Producer() *kafka.Writer
+	ClusterProducer(brokers []string) *kafka.Writer
 	Consumer(topic string, partition int, startOffset int64) *kafka.Reader
 	CheckTopicIsCompacted(topic string) error
 	CheckTopics(topics []string) error
+	DescribeTopics(topics []string) ([]TopicDescription, error)
+	// CreateMissingTopics creates the topics missing on the brokers with kafka.topicCreation and returns them.
+	CreateMissingTopics(topics []string) ([]string, error)
+	// RotateCredentials rebuilds the transport when the secrets or the certificate files have changed.
+	RotateCredentials() (bool, error)
 }
 
 type client struct {
 	addr        net.Addr
+	secrets     secret.Provider
 	kafkaClient *kafka.Client
 	config      *config.Connector
-	transport   *kafka.Transport
+	transport   *rotatingTransport
 	dialer      *kafka.Dialer
+	fingerprint string
+	lock        sync.RWMutex
 }
 
 type tlsContent struct {
@@ -43,23 +55,18 @@ type tlsContent struct {
 }
 
 func newTLSContent(
-	scramUsername,
-	scramPassword,
-	rootCAPath,
-	interCAPath string,
+	kafkaConfig *config.Kafka,
+	credentials *credentials,
 ) (*tlsContent, error) {
-	mechanism, err := scram.Mechanism(scram.SHA512, scramUsername, scramPassword)
-	if err != nil {
-		return nil, err
-	}
+	var mechanism sasl.Mechanism = &scramMechanism{credentials: credentials}
 
-	caCert, err := os.ReadFile(os.ExpandEnv(rootCAPath))
+	caCert, err := os.ReadFile(os.ExpandEnv(kafkaConfig.RootCAPath))
 	if err != nil {
 		logger.Log.Error("an error occurred while reading ca.pem file! Error: %s", err.Error())
 		return nil, err
 	}
 
-	intCert, err := os.ReadFile(os.ExpandEnv(interCAPath))
+	intCert, err := os.ReadFile(os.ExpandEnv(kafkaConfig.InterCAPath))
 	if err != nil {
 		logger.Log.Error("an error occurred while reading int.pem file! Error: %s", err.Error())
 		return nil, err
@@ -69,12 +76,23 @@ func newTLSContent(
 	caCertPool.AppendCertsFromPEM(caCert)
 	caCertPool.AppendCertsFromPEM(intCert)
 
+	tlsConfig := &tls.Config{
+		RootCAs:    caCertPool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if kafkaConfig.ClientCertPath != "" {
+		certificate, err := newClientCertificate(kafkaConfig.ClientCertPath, kafkaConfig.ClientKeyPath, credentials)
+		if err != nil {
+			logger.Log.Error("an error occurred while loading the client certificate! Error: %s", err.Error())
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = certificate.GetClientCertificate
+	}
+
 	return &tlsContent{
-		config: &tls.Config{
-			RootCAs:    caCertPool,
-			MinVersion: tls.VersionTLS12,
-		},
-		sasl: mechanism,
+		config: tlsConfig,
+		sasl:   mechanism,
 	}, nil
 }
 
@@ -125,7 +143,7 @@ func (c *client) CheckTopicIsCompacted(topic string) error {
 
 		if resource.ResourceType == int8(kafka.ResourceTypeTopic) {
 			for _, entity := range resource.ConfigEntries {
-				if entity.ConfigName == "cleanup.policy" && entity.ConfigValue == "compact" {
+				if entity.ConfigName == "cleanup.policy" && slices.Contains(strings.Split(entity.ConfigValue, ","), "compact") {
 					return nil
 				}
 			}
@@ -175,12 +193,77 @@ func (c *client) CheckTopics(topics []string) error {
 	return nil
 }
 
+func (c *client) CreateMissingTopics(topics []string) ([]string, error) {
+	response, err := c.kafkaClient.Metadata(context.Background(), &kafka.MetadataRequest{
+		Topics: topics,
+		Addr:   c.addr,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	topicCreation := c.config.Kafka.TopicCreation
+	var entries []kafka.ConfigEntry
+	if topicCreation.Retention > 0 {
+		entries = append(entries, kafka.ConfigEntry{
+			ConfigName:  "retention.ms",
+			ConfigValue: strconv.FormatInt(topicCreation.Retention.Milliseconds(), 10),
+		})
+	}
+	if c.config.Kafka.CompactedTopics {
+		entries = append(entries, kafka.ConfigEntry{ConfigName: "cleanup.policy", ConfigValue: "compact"})
+	}
+
+	var missing []kafka.TopicConfig
+	for _, responseTopic := range response.Topics {
+		if !errors.Is(responseTopic.Error, kafka.UnknownTopicOrPartition) {
+			continue
+		}
+		missing = append(missing, kafka.TopicConfig{
+			Topic:             responseTopic.Name,
+			NumPartitions:     brokerDefault(topicCreation.Partitions),
+			ReplicationFactor: brokerDefault(topicCreation.ReplicationFactor),
+			ConfigEntries:     entries,
+		})
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	createResponse, err := c.kafkaClient.CreateTopics(context.Background(), &kafka.CreateTopicsRequest{Topics: missing})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]string, 0, len(missing))
+	for _, topic := range missing {
+		// another member may have created the topic in the meantime
+		if topicError := createResponse.Errors[topic.Topic]; topicError != nil && !errors.Is(topicError, kafka.TopicAlreadyExists) {
+			return created, fmt.Errorf("topic=%s, err=%v", topic.Topic, topicError)
+		}
+		created = append(created, topic.Topic)
+	}
+	return created, nil
+}
+
+// brokerDefault maps an unset partition count or replication factor to -1, which the brokers replace with their default.
+func brokerDefault(value int) int {
+	if value == 0 {
+		return -1
+	}
+	return value
+}
+
 func (c *client) Producer() *kafka.Writer {
+	return c.ClusterProducer(c.config.Kafka.Brokers)
+}
+
+func (c *client) ClusterProducer(brokers []string) *kafka.Writer {
 	return &kafka.Writer{
-		Addr:                   kafka.TCP(c.config.Kafka.Brokers...),
+		Addr:                   kafka.TCP(brokers...),
 		Balancer:               &kafka.Hash{},
 		BatchSize:              c.config.Kafka.ProducerBatchSize,
-		BatchBytes:             math.MaxInt,
+		BatchBytes:             c.config.Kafka.ProducerRequestBytes,
 		BatchTimeout:           c.config.Kafka.ProducerBatchTimeout,
 		MaxAttempts:            c.config.Kafka.ProducerMaxAttempts,
 		ReadTimeout:            c.config.Kafka.ReadTimeout,
@@ -200,9 +283,11 @@ func (c *client) Consumer(topic string, partition int, startOffset int64) *kafka
 		StartOffset: startOffset,
 	}
 
+	c.lock.RLock()
 	if c.dialer != nil {
 		readerConfig.Dialer = c.dialer
 	}
+	c.lock.RUnlock()
 
 	return kafka.NewReader(readerConfig)
 }
@@ -249,7 +334,8 @@ func (c *client) CreateCompactedTopic(topic string, partition int, replicationFa
 	return nil
 }
 
-func NewClient(config *config.Connector) Client {
+// NewClient resolves the credentials from secrets when it is not nil, otherwise from the config.
+func NewClient(config *config.Connector, secrets secret.Provider) Client {
 	addr := kafka.TCP(config.Kafka.Brokers...)
 
 	newClient := &client{
@@ -257,36 +343,94 @@ func NewClient(config *config.Connector) Client {
 		kafkaClient: &kafka.Client{
 			Addr: addr,
 		},
-		config: config,
+		config:    config,
+		secrets:   secrets,
+		transport: &rotatingTransport{},
 	}
 
-	newClient.transport = &kafka.Transport{
-		MetadataTTL:    config.Kafka.MetadataTTL,
-		MetadataTopics: config.Kafka.MetadataTopics,
-		ClientID:       config.Kafka.ClientID,
+	transport, dialer, err := newTransport(config, secrets)
+	if err != nil {
+		panic(err)
 	}
+	newClient.transport.current.Store(transport)
+	newClient.dialer = dialer
 
 	if config.Kafka.SecureConnection {
-		tlsContent, err := newTLSContent(
-			config.Kafka.ScramUsername,
-			config.Kafka.ScramPassword,
-			config.Kafka.RootCAPath,
-			config.Kafka.InterCAPath,
-		)
+		newClient.fingerprint, err = credentialsFingerprint(&config.Kafka, secrets)
 		if err != nil {
 			panic(err)
 		}
+	}
+
+	newClient.kafkaClient.Transport = newClient.transport
+	return newClient
+}
 
-		newClient.transport.TLS = tlsContent.config
-		newClient.transport.SASL = tlsContent.sasl
+func newTransport(config *config.Connector, secrets secret.Provider) (*kafka.Transport, *kafka.Dialer, error) {
+	transport := &kafka.Transport{
+		MetadataTTL:    config.Kafka.MetadataTTL,
+		MetadataTopics: config.Kafka.MetadataTopics,
+		ClientID:       config.Kafka.ClientID,
+	}
 
-		newClient.dialer = &kafka.Dialer{
-			Timeout:       10 * time.Second,
-			DualStack:     true,
-			TLS:           tlsContent.config,
-			SASLMechanism: tlsContent.sasl,
+	netDialer := newNetDialer(&config.Kafka.Dialer)
+	var proxyDial dialFunc
+	if netDialer != nil {
+		transport.Dial = netDialer.DialContext
+		transport.DialTimeout = netDialer.Timeout
+	}
+	if config.Kafka.Dialer.Proxy != "" {
+		var err error
+		if proxyDial, err = newProxyDial(config.Kafka.Dialer.Proxy, netDialer); err != nil {
+			return nil, nil, err
 		}
+		transport.Dial = proxyDial
 	}
-	newClient.kafkaClient.Transport = newClient.transport
-	return newClient
+
+	if !config.Kafka.SecureConnection {
+		if netDialer == nil {
+			return transport, nil, nil
+		}
+		return transport, newDialer(netDialer, proxyDial), nil
+	}
+
+	credentials, err := newCredentials(&config.Kafka, secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsContent, err := newTLSContent(&config.Kafka, credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transport.TLS = tlsContent.config
+	transport.SASL = tlsContent.sasl
+
+	dialer := newDialer(netDialer, proxyDial)
+	dialer.TLS = tlsContent.config
+	dialer.SASLMechanism = tlsContent.sasl
+	return transport, dialer, nil
+}
+
+// newDialer returns the dialer of the consumers with the kafka.dialer settings of netDialer, which may be nil.
+func newDialer(netDialer *net.Dialer, proxyDial dialFunc) *kafka.Dialer {
+	dialer := &kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+		DialFunc:  proxyDial,
+	}
+	if netDialer == nil {
+		return dialer
+	}
+
+	if netDialer.Timeout > 0 {
+		dialer.Timeout = netDialer.Timeout
+	}
+	dialer.KeepAlive = netDialer.KeepAlive
+	dialer.LocalAddr = netDialer.LocalAddr
+	if netDialer.Resolver != nil {
+		dialer.Resolver = netDialer.Resolver
+	}
+	return dialer
 }
@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/secret"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+type resolvedCredentials struct {
+	scramUsername       string
+	scramPassword       string
+	clientKeyPassphrase string
+}
+
+// credentials falls back to the config values when there is no secret provider. A failed refresh keeps the last
+// resolved credentials, so a secret store outage does not break new connections.
+type credentials struct {
+	resolvedAt time.Time
+	provider   secret.Provider
+	config     *config.Kafka
+	current    resolvedCredentials
+	mutex      sync.Mutex
+}
+
+func newCredentials(kafkaConfig *config.Kafka, provider secret.Provider) (*credentials, error) {
+	c := &credentials{
+		provider: provider,
+		config:   kafkaConfig,
+	}
+
+	current, err := c.resolve()
+	if err != nil {
+		return nil, err
+	}
+	c.current = current
+	c.resolvedAt = time.Now()
+	return c, nil
+}
+
+func (c *credentials) resolve() (resolvedCredentials, error) {
+	resolved := resolvedCredentials{
+		scramUsername:       c.config.ScramUsername,
+		scramPassword:       c.config.ScramPassword,
+		clientKeyPassphrase: c.config.ClientKeyPassphrase,
+	}
+	if c.provider == nil {
+		return resolved, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Secrets.Timeout)
+	defer cancel()
+
+	var errs []error
+	for _, reference := range []struct {
+		value *string
+		name  string
+	}{
+		{&resolved.scramUsername, c.config.Secrets.ScramUsername},
+		{&resolved.scramPassword, c.config.Secrets.ScramPassword},
+		{&resolved.clientKeyPassphrase, c.config.Secrets.ClientKeyPassphrase},
+	} {
+		if reference.name == "" {
+			continue
+		}
+		value, err := c.provider.Get(ctx, reference.name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		*reference.value = value
+	}
+	return resolved, errors.Join(errs...)
+}
+
+func (c *credentials) get() resolvedCredentials {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.provider != nil && time.Since(c.resolvedAt) >= c.config.Secrets.RefreshInterval {
+		c.resolvedAt = time.Now()
+		current, err := c.resolve()
+		if err != nil {
+			logger.Log.Error("secret refresh error, the last credentials are kept: %v", err)
+		} else {
+			c.current = current
+		}
+	}
+	return c.current
+}
+
+// scramMechanism resolves the credentials on every new connection, so rotated passwords are picked up.
+type scramMechanism struct {
+	credentials *credentials
+}
+
+func (m *scramMechanism) Name() string {
+	return scram.SHA512.Name()
+}
+
+func (m *scramMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	current := m.credentials.get()
+	mechanism, err := scram.Mechanism(scram.SHA512, current.scramUsername, current.scramPassword)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mechanism.Start(ctx)
+}
+
+// clientCertificate reloads the key pair whenever the passphrase is rotated.
+type clientCertificate struct {
+	credentials *credentials
+	certificate *tls.Certificate
+	certPath    string
+	keyPath     string
+	passphrase  string
+	mutex       sync.Mutex
+}
+
+func newClientCertificate(certPath, keyPath string, credentials *credentials) (*clientCertificate, error) {
+	c := &clientCertificate{
+		credentials: credentials,
+		certPath:    certPath,
+		keyPath:     keyPath,
+	}
+	if _, err := c.get(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *clientCertificate) get() (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	passphrase := c.credentials.get().clientKeyPassphrase
+	if c.certificate != nil && passphrase == c.passphrase {
+		return c.certificate, nil
+	}
+
+	certificate, err := loadKeyPair(c.certPath, c.keyPath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	c.certificate = certificate
+	c.passphrase = passphrase
+	return certificate, nil
+}
+
+func (c *clientCertificate) GetClientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.get()
+}
+
+func loadKeyPair(certPath, keyPath, passphrase string) (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(os.ExpandEnv(certPath))
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(os.ExpandEnv(keyPath))
+	if err != nil {
+		return nil, err
+	}
+
+	// legacy pem encryption is the only encrypted key format the standard library can read
+	if block, _ := pem.Decode(keyPEM); block != nil && passphrase != "" && x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck
+		der, err := x509.DecryptPEMBlock(block, []byte(passphrase)) //nolint:staticcheck
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der})
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+// newNetDialer returns nil without kafka.dialer settings, so the kafka-go defaults are kept.
+func newNetDialer(dialerConfig *config.Dialer) *net.Dialer {
+	if !dialerConfig.IsSet() {
+		return nil
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   dialerConfig.Timeout,
+		KeepAlive: dialerConfig.KeepAlive,
+		DualStack: true,
+	}
+	if dialerConfig.LocalAddress != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(dialerConfig.LocalAddress)}
+	}
+	if dialerConfig.Resolver != "" {
+		dialer.Resolver = newResolver(dialerConfig.Resolver, dialerConfig.Timeout)
+	}
+	return dialer
+}
+
+// newResolver sends the DNS queries of the broker host names to the given server instead of the system one.
+func newResolver(address string, timeout time.Duration) *net.Resolver {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: timeout}
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
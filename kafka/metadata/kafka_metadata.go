@@ -96,7 +96,10 @@ func (s *kafkaMetadata) Load( //nolint:funlen
 	state := wrapper.CreateConcurrentSwissMap[uint16, *models.CheckpointDocument](1024)
 	exist := false
 
+	// loaded is closed once every consumed message is stored, so the state is complete when Load returns
+	loaded := make(chan struct{})
 	go func() {
+		defer close(loaded)
 		for m := range ch {
 			var doc *models.CheckpointDocument
 
@@ -118,6 +121,8 @@ func (s *kafkaMetadata) Load( //nolint:funlen
 	}()
 
 	wg.Wait()
+	close(ch)
+	<-loaded
 
 	for _, vbID := range vbIDs {
 		_, ok := state.Load(vbID)
@@ -129,8 +134,17 @@ func (s *kafkaMetadata) Load( //nolint:funlen
 	return state, exist, nil
 }
 
-func (s *kafkaMetadata) Clear(_ []uint16) error {
-	return nil
+// Clear produces tombstones, so the compacted topic drops the checkpoints of the vbuckets.
+func (s *kafkaMetadata) Clear(vbIDs []uint16) error {
+	messages := make([]kafka.Message, 0, len(vbIDs))
+	for _, vbID := range vbIDs {
+		messages = append(messages, kafka.Message{
+			Topic: s.topic,
+			Key:   []byte(strconv.Itoa(int(vbID))),
+		})
+	}
+
+	return s.writer.WriteMessages(context.Background(), messages...)
 }
 
 func NewKafkaMetadata(
@@ -0,0 +1,38 @@
+package producer
+
+import "github.com/Trendyol/go-dcp-kafka/config"
+
+func isFlushAckMode(kafkaConfig *config.Kafka) bool {
+	return kafkaConfig.GetAckMode() == config.AckModeFlush
+}
+
+// DeferAck wraps a listener ack in flush ack mode, so the event is acknowledged only after every message
+// buffered before it is written. Acks of events without pending messages, e.g. filtered ones, run right away.
+// Acks are dropped while rebalancing, the buffered messages are discarded and their events streamed again.
+func (b *Batch) DeferAck(ack func()) func() {
+	if !b.deferAcks {
+		return ack
+	}
+
+	return func() {
+		b.flushLock.Lock()
+		defer b.flushLock.Unlock()
+
+		if b.isDcpRebalancing {
+			return
+		}
+		if b.pending() == 0 {
+			ack()
+			return
+		}
+		b.acks = append(b.acks, ack)
+	}
+}
+
+// releaseAcks runs under the flush lock once a flush wrote everything.
+func (b *Batch) releaseAcks() {
+	for _, ack := range b.acks {
+		ack()
+	}
+	b.acks = b.acks[:0]
+}
@@ -0,0 +1,65 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+const rateSmoothing = 0.3
+
+// adaptiveBatch tunes the batch limit and the ticker duration after every flush. The limit shrinks when a flush is
+// slower than the target latency and grows when full batches are written faster, the ticker follows the time the
+// observed throughput needs to fill the batch. Both stay within the configured bounds.
+type adaptiveBatch struct {
+	lastFlush         time.Time
+	rate              float64
+	targetLatency     time.Duration
+	minTickerDuration time.Duration
+	maxTickerDuration time.Duration
+	minSize           int
+	maxSize           int
+}
+
+func newAdaptiveBatch(adaptiveConfig config.AdaptiveBatch) *adaptiveBatch {
+	return &adaptiveBatch{
+		targetLatency:     adaptiveConfig.TargetLatency,
+		minTickerDuration: adaptiveConfig.MinTickerDuration,
+		maxTickerDuration: adaptiveConfig.MaxTickerDuration,
+		minSize:           adaptiveConfig.MinSize,
+		maxSize:           adaptiveConfig.MaxSize,
+	}
+}
+
+// observe runs under the flush lock with the message count and the write latency of a flush.
+func (a *adaptiveBatch) observe(b *Batch, messages int, latency time.Duration) {
+	switch {
+	case latency > a.targetLatency:
+		b.batchLimit = max(a.minSize, b.batchLimit*3/4)
+	case messages >= b.batchLimit:
+		b.batchLimit = min(a.maxSize, b.batchLimit*5/4+1)
+	}
+
+	now := time.Now()
+	if elapsed := now.Sub(a.lastFlush).Seconds(); !a.lastFlush.IsZero() && elapsed > 0 {
+		rate := float64(messages) / elapsed
+		if a.rate == 0 {
+			a.rate = rate
+		} else {
+			a.rate = rateSmoothing*rate + (1-rateSmoothing)*a.rate
+		}
+	}
+	a.lastFlush = now
+
+	if a.rate > 0 {
+		fill := time.Duration(float64(b.batchLimit) / a.rate * float64(time.Second))
+		if ticker := min(max(fill, a.minTickerDuration), a.maxTickerDuration); ticker != b.batchTickerDuration {
+			b.batchTickerDuration = ticker
+			b.batchTicker.Reset(ticker)
+		}
+	}
+
+	atomic.StoreInt64(&b.metric.BatchLimit, int64(b.batchLimit))
+	atomic.StoreInt64(&b.metric.BatchTickerDuration, b.batchTickerDuration.Milliseconds())
+}
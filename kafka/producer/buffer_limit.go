@@ -0,0 +1,230 @@
+package producer
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/segmentio/kafka-go"
+)
+
+const bufferLimitWait = 10 * time.Millisecond
+
+// bufferLimit caps the bytes held by the buffer and the in-flight flushes. Messages over the cap block the listener,
+// drop the oldest buffered messages or go to the spill queue, which is refilled into the buffer as the flushes make room.
+// refilled and flying count the spilled messages in the buffer and in the flights, the spill file keeps them until
+// they are written.
+type bufferLimit struct {
+	spill    *spillQueue
+	policy   string
+	maxBytes int64
+	refilled int
+	flying   int
+}
+
+func (b *Batch) setBufferLimit(limit config.BufferLimit) error {
+	if limit.MaxBytes == 0 {
+		return nil
+	}
+
+	b.bufferLimit = &bufferLimit{policy: limit.Policy, maxBytes: limit.MaxBytes}
+	if limit.Policy == config.BufferOverflowSpill {
+		spill, err := newSpillQueue(limit.SpillDirectory)
+		if err != nil {
+			return err
+		}
+		b.bufferLimit.spill = spill
+		b.metric.SpilledMessages = int64(spill.count)
+	}
+	return nil
+}
+
+// overflows lets a single message bigger than the cap into an empty buffer, so it is not held forever.
+func (l *bufferLimit) overflows(buffered, size int64) bool {
+	return buffered > 0 && buffered+size > l.maxBytes
+}
+
+// bufferedBytes runs under the flush lock.
+func (b *Batch) bufferedBytes() int64 {
+	buffered := b.currentMessageBytes
+	for _, f := range b.flights {
+		if !f.done {
+			buffered += f.bytes
+		}
+	}
+	return buffered
+}
+
+// waitForBuffer blocks the listener with the block policy until the messages fit, flushing meanwhile.
+func (b *Batch) waitForBuffer(size int64) {
+	for overflowed := false; ; overflowed = true {
+		b.flushLock.Lock()
+		full := !b.isDcpRebalancing && b.bufferLimit.overflows(b.bufferedBytes(), size)
+		b.flushLock.Unlock()
+		if !full {
+			return
+		}
+
+		if !overflowed {
+			atomic.AddInt64(&b.metric.BufferOverflows, 1)
+		} else {
+			time.Sleep(bufferLimitWait)
+		}
+		b.FlushMessages()
+	}
+}
+
+// limitBuffer runs under the flush lock, it returns true when the messages are spilled instead of buffered. While the
+// spill queue is not empty every message goes to it, so the messages keep their order.
+func (b *Batch) limitBuffer(messages []kafka.Message, size int64, eventTime time.Time) bool {
+	overflows := b.bufferLimit.overflows(b.bufferedBytes(), size)
+
+	switch b.bufferLimit.policy {
+	case config.BufferOverflowDropOldest:
+		if overflows {
+			atomic.AddInt64(&b.metric.BufferOverflows, 1)
+			b.dropOldest(size)
+		}
+	case config.BufferOverflowSpill:
+		spill := b.bufferLimit.spill
+		if !overflows && spill.count == 0 {
+			return false
+		}
+		if overflows {
+			atomic.AddInt64(&b.metric.BufferOverflows, 1)
+		}
+		if err := spill.push(messages, eventTime); err != nil {
+			panic(fmt.Errorf("cannot spill buffered messages: %w", err))
+		}
+		atomic.StoreInt64(&b.metric.SpilledMessages, int64(spill.count))
+		return true
+	}
+	return false
+}
+
+// dropOldest drops buffered messages until the new ones fit, the acks of their events are not held back.
+func (b *Batch) dropOldest(size int64) {
+	dropped := 0
+	for dropped < len(b.messages) && b.bufferLimit.overflows(b.bufferedBytes(), size) {
+		b.currentMessageBytes -= messageBytes(b.messages[dropped : dropped+1])
+		dropped++
+	}
+	if dropped == 0 {
+		return
+	}
+
+	b.messages = append(b.messages[:0], b.messages[dropped:]...)
+	b.eventTimes = append(b.eventTimes[:0], b.eventTimes[dropped:]...)
+	atomic.AddInt64(&b.metric.BufferDroppedMessages, int64(dropped))
+	b.errorLog.Error("bufferLimit", "dropped %d buffered messages over kafka.bufferLimit.maxBytes", dropped)
+}
+
+// refill runs under the flush lock, it moves the spilled messages back into the buffer while they fit, oldest first.
+func (b *Batch) refill() {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil || b.isDcpRebalancing {
+		return
+	}
+
+	spill := b.bufferLimit.spill
+	for spill.count > 0 && len(b.messages) < b.batchLimit {
+		spilled, err := spill.peek()
+		if err != nil {
+			panic(fmt.Errorf("cannot read spilled messages: %w", err))
+		}
+		message := []kafka.Message{spilled.message()}
+		if b.bufferLimit.overflows(b.bufferedBytes(), messageBytes(message)) {
+			break
+		}
+		b.buffer(message, spilled.EventTime)
+		spill.pop()
+		b.bufferLimit.refilled++
+	}
+	atomic.StoreInt64(&b.metric.SpilledMessages, int64(spill.count))
+}
+
+// bufferWritten runs under the flush lock after a synchronous flush wrote the buffer.
+func (b *Batch) bufferWritten() {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil {
+		return
+	}
+	b.bufferLimit.refilled = 0
+	b.truncateSpill()
+}
+
+// flightWritten runs under the flush lock for every completed flight.
+func (b *Batch) flightWritten(f *flight) {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil {
+		return
+	}
+	b.bufferLimit.flying -= f.refilled
+	b.truncateSpill()
+}
+
+func (b *Batch) truncateSpill() {
+	if b.bufferLimit.refilled > 0 || b.bufferLimit.flying > 0 {
+		return
+	}
+	if err := b.bufferLimit.spill.truncate(); err != nil {
+		panic(fmt.Errorf("cannot truncate spilled messages: %w", err))
+	}
+}
+
+// takeRefilled moves the refilled messages of the buffer to a starting flight.
+func (b *Batch) takeRefilled() int {
+	if b.bufferLimit == nil {
+		return 0
+	}
+
+	refilled := b.bufferLimit.refilled
+	b.bufferLimit.flying += refilled
+	b.bufferLimit.refilled = 0
+	return refilled
+}
+
+// rewindSpill makes the refilled messages discarded by a rebalance pending again, they are written twice at worst.
+func (b *Batch) rewindSpill() {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil {
+		return
+	}
+
+	b.bufferLimit.refilled, b.bufferLimit.flying = 0, 0
+	if err := b.bufferLimit.spill.rewindAll(); err != nil {
+		panic(fmt.Errorf("cannot rewind spilled messages: %w", err))
+	}
+	atomic.StoreInt64(&b.metric.SpilledMessages, int64(b.bufferLimit.spill.count))
+}
+
+func (b *Batch) spilled() int {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil {
+		return 0
+	}
+	return b.bufferLimit.spill.count
+}
+
+// closeSpill flushes the spilled messages on Close while the flushes drain them, the rest is produced on the next start.
+func (b *Batch) closeSpill() {
+	if b.bufferLimit == nil || b.bufferLimit.spill == nil {
+		return
+	}
+
+	for spilled := b.spilled(); spilled > 0; {
+		b.FlushMessages()
+		b.waitFlights()
+
+		b.flushLock.Lock()
+		left := b.spilled()
+		b.flushLock.Unlock()
+		if left == spilled {
+			break
+		}
+		spilled = left
+	}
+	// the last refill is still buffered
+	b.FlushMessages()
+	b.waitFlights()
+
+	if err := b.bufferLimit.spill.close(); err != nil {
+		b.errorLog.Error("bufferLimit", "cannot close spill queue %v", err)
+	}
+}
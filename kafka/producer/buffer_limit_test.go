@@ -0,0 +1,154 @@
+package producer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// every test message is 7 bytes, so the limit buffers two of them
+const testBufferBytes = 14
+
+func newTestSpillBatch(t *testing.T, writer Writer, directory string) *Batch {
+	t.Helper()
+
+	b := newTestBatch(t, writer)
+	limit := config.BufferLimit{Policy: config.BufferOverflowSpill, SpillDirectory: directory, MaxBytes: testBufferBytes}
+	if err := b.setBufferLimit(limit); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.bufferLimit.spill.close() })
+	return b
+}
+
+func addMessages(b *Batch, values ...string) int {
+	acks := 0
+	ctx := &models.ListenerContext{Ack: func() { acks++ }}
+	for _, value := range values {
+		b.AddMessages(ctx, []kafka.Message{message("users", "k", value)}, time.Now())
+	}
+	return acks
+}
+
+func spillFileSize(t *testing.T, directory string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(filepath.Join(directory, spillFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func collectRequests(writer *fakeWriter) []kafka.Message {
+	var written []kafka.Message
+	for _, request := range writer.requests {
+		written = append(written, request...)
+	}
+	return written
+}
+
+func TestBufferLimitSpillsThenRefillsThroughTheFlights(t *testing.T) {
+	directory := t.TempDir()
+	writer := &fakeWriter{}
+	b := newTestSpillBatch(t, writer, directory)
+	b.inFlight = make(chan struct{}, 1)
+
+	if acks := addMessages(b, "1", "2", "3", "4", "5"); acks != 5 {
+		t.Fatalf("expected the spilled events to be acknowledged, got %d acks", acks)
+	}
+	assertValues(t, []string{"1", "2"}, b.messages)
+	if b.spilled() != 3 || b.metric.SpilledMessages != 3 || b.metric.BufferOverflows != 3 {
+		t.Fatalf("expected 3 spilled messages over the limit, got %d spilled, %d overflows",
+			b.spilled(), b.metric.BufferOverflows)
+	}
+
+	b.FlushMessages()
+	b.waitFlights()
+	assertValues(t, []string{"3", "4"}, b.messages)
+	if b.spilled() != 1 || spillFileSize(t, directory) == 0 {
+		t.Fatal("expected the spill file to keep the refilled messages until they are written")
+	}
+
+	b.FlushMessages()
+	b.waitFlights()
+	assertValues(t, []string{"5"}, b.messages)
+	if b.spilled() != 0 || spillFileSize(t, directory) == 0 {
+		t.Fatal("expected the spill file to keep the last refilled message until it is written")
+	}
+
+	b.FlushMessages()
+	b.waitFlights()
+	if size := spillFileSize(t, directory); size != 0 {
+		t.Fatalf("expected the spill file to be truncated, got %d bytes", size)
+	}
+	assertValues(t, []string{"1", "2", "3", "4", "5"}, collectRequests(writer))
+}
+
+func TestBufferLimitRewindsTheRefilledMessagesOnARebalance(t *testing.T) {
+	writer := &fakeWriter{}
+	b := newTestSpillBatch(t, writer, t.TempDir())
+
+	addMessages(b, "1", "2", "3", "4", "5")
+	b.FlushMessages()
+	assertValues(t, []string{"3", "4"}, b.messages)
+
+	b.PrepareStartRebalancing()
+	if b.spilled() != 3 || b.metric.SpilledMessages != 3 {
+		t.Fatalf("expected the discarded refilled messages pending again, got %d spilled", b.spilled())
+	}
+	if b.metric.RebalanceDroppedMessages != 2 {
+		t.Fatalf("expected the 2 refilled messages dropped, got %d", b.metric.RebalanceDroppedMessages)
+	}
+	b.PrepareEndRebalancing()
+
+	b.FlushMessages()
+	b.FlushMessages()
+	assertValues(t, []string{"1", "2", "3", "4", "5"}, collectRequests(writer))
+	if b.spilled() != 0 || len(b.messages) != 0 {
+		t.Fatalf("expected every message written, got %d spilled, %d buffered", b.spilled(), len(b.messages))
+	}
+}
+
+func TestSpillQueueKeepsTheCompleteMessagesAcrossARestart(t *testing.T) {
+	directory := t.TempDir()
+
+	q, err := newSpillQueue(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = q.push([]kafka.Message{message("users", "k", "1"), message("users", "k", "2")}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = q.file.WriteString(`{"topic":"users"`); err != nil {
+		t.Fatal(err)
+	}
+	if err = q.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := newSpillQueue(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.close()
+
+	if restarted.count != 2 {
+		t.Fatalf("expected 2 spilled messages without the partly written one, got %d", restarted.count)
+	}
+	for _, expected := range []string{"1", "2"} {
+		spilled, err := restarted.peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(spilled.Value) != expected {
+			t.Fatalf("expected %s, got %s", expected, spilled.Value)
+		}
+		restarted.pop()
+	}
+}
@@ -0,0 +1,55 @@
+package producer
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+var ErrChaosInjected = errors.New("chaos injected flush error")
+
+// Chaos delays or fails a percentage of batch writes without touching the brokers, to rehearse incidents on staging.
+// Injected errors are handled like temporary Kafka errors, so the batch is retried on the next flush.
+type Chaos struct {
+	random          *rand.Rand
+	delay           time.Duration
+	delayPercentage float64
+	errorPercentage float64
+	lock            sync.Mutex
+}
+
+func NewChaos(chaosConfig config.Chaos) (*Chaos, error) {
+	if chaosConfig.DelayPercentage < 0 || chaosConfig.DelayPercentage > 100 ||
+		chaosConfig.ErrorPercentage < 0 || chaosConfig.ErrorPercentage > 100 {
+		return nil, errors.New("chaos percentages must be between 0 and 100")
+	}
+
+	return &Chaos{
+		random:          rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		delay:           chaosConfig.Delay,
+		delayPercentage: chaosConfig.DelayPercentage,
+		errorPercentage: chaosConfig.ErrorPercentage,
+	}, nil
+}
+
+func (c *Chaos) inject(metric *Metric) error {
+	c.lock.Lock()
+	delay := c.random.Float64()*100 < c.delayPercentage
+	fail := c.random.Float64()*100 < c.errorPercentage
+	c.lock.Unlock()
+
+	if delay {
+		atomic.AddInt64(&metric.ChaosDelays, 1)
+		time.Sleep(c.delay)
+	}
+
+	if fail {
+		atomic.AddInt64(&metric.ChaosErrors, 1)
+		return ErrChaosInjected
+	}
+	return nil
+}
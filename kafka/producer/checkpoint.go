@@ -0,0 +1,33 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// commitCheckpoint runs under the flush lock once a flush wrote everything. With a commit interval or a flush count
+// the commit is skipped until both are reached, which reduces the metadata writes for large vbucket counts.
+func (b *Batch) commitCheckpoint() {
+	b.uncommittedFlushes++
+	if b.uncommittedFlushes < b.commitEveryFlushes || time.Since(b.lastCommit) < b.commitInterval {
+		return
+	}
+	b.forceCommitCheckpoint()
+}
+
+func (b *Batch) forceCommitCheckpoint() {
+	b.dcpCheckpointCommit()
+	atomic.AddInt64(&b.metric.CheckpointCommits, 1)
+	b.uncommittedFlushes = 0
+	b.lastCommit = time.Now()
+}
+
+// commitSkippedCheckpoint commits the flushes skipped by the cadence, e.g. before shutting down.
+func (b *Batch) commitSkippedCheckpoint() {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	if b.uncommittedFlushes > 0 {
+		b.forceCommitCheckpoint()
+	}
+}
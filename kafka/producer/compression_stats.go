@@ -0,0 +1,86 @@
+package producer
+
+import (
+	"bytes"
+	"math"
+	"sync/atomic"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/compress"
+)
+
+// CompressionStats estimates the bytes on wire, since the writer does not report compressed sizes.
+// Every sampleRate-th flush is compressed again with the codec of the writer to measure the ratio,
+// which is applied to the flushes in between.
+type CompressionStats struct {
+	codec      compress.Codec
+	sampleRate int64
+	flushes    int64
+	ratio      uint64
+}
+
+func NewCompressionStats(compression kafka.Compression, sampleRate int) *CompressionStats {
+	s := &CompressionStats{
+		codec:      compress.Compression(compression).Codec(),
+		sampleRate: int64(sampleRate),
+	}
+	s.setRatio(1)
+	return s
+}
+
+// Ratio returns the last sampled compressed to uncompressed byte ratio, 1 when compression is disabled.
+func (s *CompressionStats) Ratio() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.ratio))
+}
+
+func (s *CompressionStats) setRatio(ratio float64) {
+	atomic.StoreUint64(&s.ratio, math.Float64bits(ratio))
+}
+
+func (s *CompressionStats) record(messages []kafka.Message, metric *Metric) {
+	var size int
+	for i := range messages {
+		size += messageSize(&messages[i])
+	}
+	if size == 0 {
+		return
+	}
+
+	if s.codec != nil && s.flushes%s.sampleRate == 0 {
+		if ratio, ok := s.sample(messages, size); ok {
+			s.setRatio(ratio)
+		}
+	}
+	s.flushes++
+
+	atomic.AddInt64(&metric.ProducedBytes, int64(size))
+	atomic.AddInt64(&metric.EstimatedWireBytes, int64(float64(size)*s.Ratio()))
+}
+
+func (s *CompressionStats) sample(messages []kafka.Message, size int) (float64, bool) {
+	var compressed bytes.Buffer
+	writer := s.codec.NewWriter(&compressed)
+
+	for i := range messages {
+		if _, err := writer.Write(messages[i].Key); err != nil {
+			return 0, false
+		}
+		if _, err := writer.Write(messages[i].Value); err != nil {
+			return 0, false
+		}
+		for _, header := range messages[i].Headers {
+			if _, err := writer.Write([]byte(header.Key)); err != nil {
+				return 0, false
+			}
+			if _, err := writer.Write(header.Value); err != nil {
+				return 0, false
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, false
+	}
+
+	return float64(compressed.Len()) / float64(size), true
+}
@@ -0,0 +1,32 @@
+package producer
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// DeliveryReport is the outcome of writing a message, Kafka sets its Partition and Offset when Err is nil.
+type DeliveryReport struct {
+	Err     error
+	Message kafka.Message
+}
+
+// SetDeliveryCallback sets a function called for every message written to Kafka, mirror writes excluded. It is
+// called from the writer goroutines and blocks the flush, so it should be fast. It must be set before the start.
+func (p *Producer) SetDeliveryCallback(callback func(report DeliveryReport)) {
+	p.ProducerBatch.onDelivery = callback
+}
+
+// reporting reports the deliveries of the writer.
+func (b *Batch) reporting(writer *kafka.Writer) *kafka.Writer {
+	writer.Completion = b.reportDelivery
+	return writer
+}
+
+func (b *Batch) reportDelivery(messages []kafka.Message, err error) {
+	if b.onDelivery == nil {
+		return
+	}
+	for i := range messages {
+		b.onDelivery(DeliveryReport{Message: messages[i], Err: err})
+	}
+}
@@ -0,0 +1,39 @@
+package producer
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+// setDeliverySemantics checks the ack mode of kafka.deliverySemantics. At least once needs the acks deferred until the
+// messages are written, at most once needs them acked on enqueue so the checkpoint can be committed before writing.
+func (b *Batch) setDeliverySemantics(kafkaConfig *config.Kafka) error {
+	switch kafkaConfig.DeliverySemantics {
+	case config.DeliveryAtLeastOnce:
+		if !b.deferAcks {
+			return errors.New("at least once delivery requires flush ack mode")
+		}
+	case config.DeliveryAtMostOnce:
+		if b.deferAcks {
+			return errors.New("at most once delivery requires enqueue ack mode")
+		}
+		b.atMostOnce = true
+	}
+	return nil
+}
+
+// commitBeforeWrite commits the checkpoint of the buffered events in at most once delivery, so a crash during the
+// write never delivers them again.
+func (b *Batch) commitBeforeWrite() {
+	if b.atMostOnce {
+		b.forceCommitCheckpoint()
+	}
+}
+
+// dropUnwritten counts the messages of a failed write in at most once delivery, they are not retried.
+func (b *Batch) dropUnwritten(count int) {
+	atomic.AddInt64(&b.metric.AtMostOnceDropped, int64(count))
+	b.errorLog.Error("atMostOnce", "dropped %d messages of a failed write", count)
+}
@@ -0,0 +1,143 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+type FailoverEvent struct {
+	Time    time.Time
+	Cause   error
+	From    []string
+	To      []string
+	Elapsed time.Duration
+}
+
+// Failover switches the primary writer to a standby cluster once the primary is unreachable longer than the threshold.
+// There is no automatic failback, the connector stays on the standby cluster until it is restarted.
+type Failover struct {
+	failingSince   time.Time
+	primary        Writer
+	standby        Writer
+	callback       func(event FailoverEvent)
+	primaryBrokers []string
+	standbyBrokers []string
+	threshold      time.Duration
+	lock           sync.Mutex
+	active         int32
+}
+
+func NewFailover(
+	primary Writer, primaryBrokers []string,
+	standby Writer, standbyBrokers []string,
+	threshold time.Duration,
+) *Failover {
+	return &Failover{
+		primary:        primary,
+		primaryBrokers: primaryBrokers,
+		standby:        standby,
+		standbyBrokers: standbyBrokers,
+		threshold:      threshold,
+	}
+}
+
+// SetCallback sets a function called once when the failover happens.
+func (f *Failover) SetCallback(callback func(event FailoverEvent)) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.callback = callback
+}
+
+func (f *Failover) IsActive() bool {
+	return atomic.LoadInt32(&f.active) == 1
+}
+
+// observe returns true when the error means the primary is unreachable, and the writer to use from now on.
+// Primary writes are bounded by the threshold, so a hanging cluster fails over after a single write at the latest.
+func (f *Failover) observe(err error, started time.Time, metric *Metric) (bool, Writer) {
+	if !isUnreachableError(err) {
+		return false, nil
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.IsActive() {
+		return true, f.standby
+	}
+
+	now := time.Now()
+	if f.failingSince.IsZero() {
+		f.failingSince = started
+	}
+
+	elapsed := now.Sub(f.failingSince)
+	if elapsed < f.threshold {
+		logger.Log.Error("primary kafka cluster unreachable for %v, failover after %v, err: %v", elapsed, f.threshold, err)
+		return true, f.primary
+	}
+
+	atomic.StoreInt32(&f.active, 1)
+	atomic.AddInt64(&metric.Failovers, 1)
+	logger.Log.Error("primary kafka cluster unreachable for %v, failing over to %v, err: %v", elapsed, f.standbyBrokers, err)
+
+	if f.callback != nil {
+		go f.callback(FailoverEvent{
+			Time:    now,
+			Cause:   err,
+			From:    f.primaryBrokers,
+			To:      f.standbyBrokers,
+			Elapsed: elapsed,
+		})
+	}
+
+	return true, f.standby
+}
+
+func (f *Failover) succeeded() {
+	if f.IsActive() {
+		return
+	}
+
+	f.lock.Lock()
+	f.failingSince = time.Time{}
+	f.lock.Unlock()
+}
+
+// close closes the writer that is not used by the batch anymore.
+func (f *Failover) close() error {
+	if f.IsActive() {
+		return f.primary.Close()
+	}
+	return f.standby.Close()
+}
+
+func isUnreachableError(err error) bool {
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) {
+		for _, writeErr := range writeErrors {
+			if writeErr != nil && isUnreachableError(writeErr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
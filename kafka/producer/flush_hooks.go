@@ -0,0 +1,48 @@
+package producer
+
+import (
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// FlushResult is the outcome of a flush of the buffer. Failed are the messages not written by it, they are retried
+// by the next flush, queued in kafka.outageQueue or dropped in at most once delivery.
+type FlushResult struct {
+	Written []kafka.Message
+	Failed  []kafka.Message
+	Latency time.Duration
+}
+
+// FlushHooks are called around every flush of the buffer, mirror and migration writes excluded. Before gets the
+// messages about to be written and may change them in place as a last chance, e.g. add audit headers, but not
+// reorder them. It gets the failed messages again when the next flush retries them. Both block the flush, so they
+// should be fast.
+type FlushHooks struct {
+	Before func(messages []kafka.Message)
+	After  func(result FlushResult)
+}
+
+// SetFlushHooks sets the hooks called around every flush, it must be set before the start.
+func (p *Producer) SetFlushHooks(hooks FlushHooks) {
+	p.ProducerBatch.flushHooks = hooks
+}
+
+func (b *Batch) beforeFlush(messages []kafka.Message) {
+	if b.flushHooks.Before != nil {
+		b.flushHooks.Before(messages)
+	}
+}
+
+// afterFlush runs after a synchronous flush of count messages, writePrimary leaves only the failed ones in the buffer.
+func (b *Batch) afterFlush(count int, written []kafka.Message, latency time.Duration) {
+	if b.flushHooks.After == nil {
+		return
+	}
+
+	var failed []kafka.Message
+	if len(written) < count {
+		failed = append(failed, b.messages...)
+	}
+	b.flushHooks.After(FlushResult{Written: written, Failed: failed, Latency: latency})
+}
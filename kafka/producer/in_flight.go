@@ -0,0 +1,187 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// flight is a batch detached from the buffer and written in the background. Flights complete in any order,
+// their acks are released and the checkpoint is committed in flush order only.
+type flight struct {
+	messages          []kafka.Message
+	eventTimes        []time.Time
+	migrationMessages []kafka.Message
+	acks              []func()
+	bytes             int64
+	refilled          int
+	done              bool
+}
+
+// startFlight waits for a free in-flight slot before taking the buffered messages, so a full pipeline
+// blocks the producers of the batch like a slow synchronous flush does.
+func (b *Batch) startFlight() {
+	b.inFlight <- struct{}{}
+
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	if b.isDcpRebalancing {
+		<-b.inFlight
+		return
+	}
+
+	if b.mirror != nil {
+		b.mirror.flush(b.metric)
+	}
+	b.refill()
+
+	if len(b.messages) == 0 && len(b.migrationMessages) == 0 && len(b.acks) == 0 {
+		<-b.inFlight
+		return
+	}
+
+	if b.latencyBudget != nil {
+		b.latencyBudget.apply(b.messages, b.eventTimes, b.metric)
+		b.latencyBudget.apply(b.migrationMessages, b.migrationEventTimes, b.metric)
+	}
+
+	f := &flight{
+		messages:          b.messages,
+		eventTimes:        b.eventTimes,
+		migrationMessages: b.migrationMessages,
+		acks:              b.acks,
+		bytes:             b.currentMessageBytes,
+		refilled:          b.takeRefilled(),
+	}
+	b.flights = append(b.flights, f)
+
+	b.messages = make([]kafka.Message, 0, b.batchLimit)
+	b.eventTimes = nil
+	b.migrationMessages = nil
+	b.migrationEventTimes = nil
+	b.acks = nil
+	b.currentMessageBytes = 0
+	b.batchTicker.Reset(b.batchTickerDuration)
+	b.refill()
+
+	if len(f.messages) == 0 && len(f.migrationMessages) == 0 {
+		f.done = true
+		b.completeFlights()
+		<-b.inFlight
+		return
+	}
+
+	go b.fly(f)
+}
+
+// fly retries the failed messages of a flight until all are written, an in-flight batch cannot go back to the buffer
+// without breaking the order of the messages buffered after it.
+func (b *Batch) fly(f *flight) {
+	defer func() { <-b.inFlight }()
+
+	b.beforeFlush(f.messages)
+
+	startedTime := time.Now()
+	written, queued := b.writeFlight(f.messages, f.eventTimes)
+	latency := time.Since(startedTime)
+	b.metric.BatchProduceLatency = latency.Milliseconds()
+	if b.flushHooks.After != nil {
+		b.flushHooks.After(FlushResult{Written: written, Failed: queued, Latency: latency})
+	}
+
+	var failingSince time.Time
+	for len(f.migrationMessages) > 0 && !b.writeMigration(f.migrationMessages) {
+		b.checkFailing(&failingSince, len(f.migrationMessages))
+		time.Sleep(b.batchTickerDuration)
+	}
+
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	if b.compressionStats != nil {
+		b.compressionStats.record(written, b.metric)
+	}
+	if b.mirror != nil {
+		b.mirror.add(written)
+	}
+
+	if b.adaptive != nil {
+		b.adaptive.observe(b, len(f.messages), latency)
+	}
+
+	f.done = true
+	b.completeFlights()
+}
+
+// writeFlight retries the failed messages until all are written, or queues them once the outage queue is enabled.
+func (b *Batch) writeFlight(messages []kafka.Message, eventTimes []time.Time) ([]kafka.Message, []kafka.Message) {
+	var written, queued []kafka.Message
+	var failingSince time.Time
+
+	if b.outage != nil && b.outage.pending() {
+		b.queueOutage(messages, eventTimes)
+		queued, messages = messages, nil
+	}
+	for len(messages) > 0 {
+		shardWritten, failed := b.writeShards(messages)
+		atomic.AddInt64(&b.metric.ProducedMessages, int64(len(shardWritten)))
+		b.metric.EndToEndLatency.observeWritten(eventTimes, failed)
+		written = append(written, shardWritten...)
+		if len(failed) == 0 {
+			break
+		}
+		if b.outage == nil {
+			b.checkFailing(&failingSince, len(failed))
+		}
+		messages = collect(messages, failed)
+		keptEventTimes := make([]time.Time, 0, len(failed))
+		for _, i := range failed {
+			keptEventTimes = append(keptEventTimes, eventTimes[i])
+		}
+		eventTimes = keptEventTimes
+		if b.outage != nil {
+			b.queueOutage(messages, eventTimes)
+			queued = messages
+			break
+		}
+		time.Sleep(b.batchTickerDuration)
+	}
+	return written, queued
+}
+
+// completeFlights runs under the flush lock. Flights dropped by a rebalance are not in the list anymore.
+func (b *Batch) completeFlights() {
+	completed := 0
+	for _, f := range b.flights {
+		if !f.done {
+			break
+		}
+		for _, ack := range f.acks {
+			ack()
+		}
+		b.flightWritten(f)
+		completed++
+	}
+	if completed == 0 {
+		return
+	}
+
+	b.flights = b.flights[completed:]
+	b.commitCheckpoint()
+	b.refill()
+}
+
+// waitFlights returns once every in-flight batch is written, by taking all slots.
+func (b *Batch) waitFlights() {
+	if b.inFlight == nil {
+		return
+	}
+	for i := 0; i < cap(b.inFlight); i++ {
+		b.inFlight <- struct{}{}
+	}
+	for i := 0; i < cap(b.inFlight); i++ {
+		<-b.inFlight
+	}
+}
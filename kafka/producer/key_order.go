@@ -0,0 +1,58 @@
+package producer
+
+import (
+	"errors"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// retryIndexes returns the indexes of the messages to retry after a failed write. On a partial failure reported with
+// kafka.WriteErrors, a key is retried from its first failed message on, including the later messages of the key that
+// were written, so a retry never writes an older message of a key after a newer one. Keyless messages have no order
+// and only the failed ones are retried. Any other error retries all messages.
+func retryIndexes(messages []kafka.Message, err error) []int {
+	var writeErrors kafka.WriteErrors
+	if !errors.As(err, &writeErrors) || len(writeErrors) != len(messages) {
+		return allIndexes(len(messages))
+	}
+
+	failedKeys := map[string]struct{}{}
+	var retried []int
+	for i := range messages {
+		if len(messages[i].Key) == 0 {
+			if writeErrors[i] != nil {
+				retried = append(retried, i)
+			}
+			continue
+		}
+
+		key := messages[i].Topic + "\x00" + string(messages[i].Key)
+		if _, behind := failedKeys[key]; !behind && writeErrors[i] == nil {
+			continue
+		}
+		failedKeys[key] = struct{}{}
+		retried = append(retried, i)
+	}
+	return retried
+}
+
+func allIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// without returns the messages except the ones of the sorted indexes.
+func without(messages []kafka.Message, indexes []int) []kafka.Message {
+	kept := make([]kafka.Message, 0, len(messages)-len(indexes))
+	for i := range messages {
+		if len(indexes) > 0 && indexes[0] == i {
+			indexes = indexes[1:]
+			continue
+		}
+		kept = append(kept, messages[i])
+	}
+	return kept
+}
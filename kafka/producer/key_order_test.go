@@ -0,0 +1,231 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+var errWrite = errors.New("write failed")
+
+// fakeWriter records the written requests and fails the messages with the failValue through kafka.WriteErrors, or
+// all of them with err.
+type fakeWriter struct {
+	err       error
+	failValue string
+	requests  [][]kafka.Message
+	lock      sync.Mutex
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.requests = append(w.requests, append([]kafka.Message(nil), messages...))
+	if w.err != nil {
+		return w.err
+	}
+
+	writeErrors := make(kafka.WriteErrors, len(messages))
+	failed := false
+	for i := range messages {
+		if w.failValue != "" && string(messages[i].Value) == w.failValue {
+			writeErrors[i] = context.DeadlineExceeded
+			failed = true
+		}
+	}
+	if failed {
+		return writeErrors
+	}
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+func newTestBatch(t *testing.T, writer Writer) *Batch {
+	t.Helper()
+	logger.InitDefaultLogger("fatal")
+
+	b := &Batch{
+		Writer:              writer,
+		metric:              &Metric{Topics: NewTopicMetrics(), EndToEndLatency: NewLatencyHistogram(nil)},
+		flushParallelism:    1,
+		batchLimit:          100,
+		batchBytes:          1 << 20,
+		batchTickerDuration: time.Hour,
+		batchTicker:         time.NewTicker(time.Hour),
+		dcpCheckpointCommit: func() {},
+	}
+	t.Cleanup(b.batchTicker.Stop)
+	b.rateLimiter, _ = NewRateLimiter(0, 0)
+	return b
+}
+
+func message(topic string, key string, value string) kafka.Message {
+	m := kafka.Message{Topic: topic, Value: []byte(value)}
+	if key != "" {
+		m.Key = []byte(key)
+	}
+	return m
+}
+
+func TestRetryIndexes(t *testing.T) {
+	messages := []kafka.Message{
+		message("users", "1", "a"),
+		message("users", "2", "b"),
+		message("users", "1", "c"),
+		message("users", "", "d"),
+		message("users", "", "e"),
+		message("orders", "1", "f"),
+		message("users", "2", "g"),
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected []int
+	}{
+		{
+			name:     "retries all messages on a generic error",
+			err:      errWrite,
+			expected: []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "retries a key from its first failed message on",
+			err:      kafka.WriteErrors{errWrite, nil, nil, nil, nil, nil, nil},
+			expected: []int{0, 2},
+		},
+		{
+			name:     "does not retry the written messages before the first failure of a key",
+			err:      kafka.WriteErrors{nil, nil, errWrite, nil, nil, nil, nil},
+			expected: []int{2},
+		},
+		{
+			name:     "keeps the same key of different topics apart",
+			err:      kafka.WriteErrors{nil, nil, nil, nil, nil, errWrite, nil},
+			expected: []int{5},
+		},
+		{
+			name:     "retries only the failed keyless messages",
+			err:      kafka.WriteErrors{nil, nil, nil, errWrite, nil, nil, nil},
+			expected: []int{3},
+		},
+		{
+			name:     "retries several keys",
+			err:      kafka.WriteErrors{nil, errWrite, nil, nil, errWrite, nil, nil},
+			expected: []int{1, 4, 6},
+		},
+		{
+			name:     "retries all messages on a mismatched length",
+			err:      kafka.WriteErrors{errWrite, nil},
+			expected: []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:     "retries nothing without a failed message",
+			err:      kafka.WriteErrors{nil, nil, nil, nil, nil, nil, nil},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := retryIndexes(messages, tt.err); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWithout(t *testing.T) {
+	messages := []kafka.Message{message("t", "", "a"), message("t", "", "b"), message("t", "", "c")}
+
+	kept := without(messages, []int{0, 2})
+	if len(kept) != 1 || string(kept[0].Value) != "b" {
+		t.Errorf("expected only b, got %v", kept)
+	}
+}
+
+func TestWriteShardsMergesSortedIndexes(t *testing.T) {
+	messages := []kafka.Message{
+		message("users", "1", "ok"),
+		message("users", "2", "fail"),
+		message("users", "3", "ok"),
+		message("users", "1", "fail"),
+		message("users", "2", "ok"),
+		message("users", "4", "ok"),
+		message("users", "1", "ok"),
+		message("users", "", "fail"),
+		message("users", "3", "fail"),
+	}
+	writeErrors := make(kafka.WriteErrors, len(messages))
+	for i := range messages {
+		if string(messages[i].Value) == "fail" {
+			writeErrors[i] = context.DeadlineExceeded
+		}
+	}
+	expected := retryIndexes(messages, writeErrors)
+
+	for _, parallelism := range []int{1, 2, 4, 8} {
+		writer := &fakeWriter{failValue: "fail"}
+		b := newTestBatch(t, writer)
+		b.flushParallelism = parallelism
+
+		written, failed := b.writeShards(messages)
+
+		if !sort.IntsAreSorted(failed) {
+			t.Errorf("parallelism %d: expected sorted indexes, got %v", parallelism, failed)
+		}
+		if !reflect.DeepEqual(failed, expected) {
+			t.Errorf("parallelism %d: expected failed %v, got %v", parallelism, expected, failed)
+		}
+		if len(written) != len(messages)-len(failed) {
+			t.Errorf("parallelism %d: expected %d written, got %d", parallelism, len(messages)-len(failed), len(written))
+		}
+		if parallelism > 1 && len(writer.requests) < 2 {
+			t.Errorf("parallelism %d: expected several shards, got %d requests", parallelism, len(writer.requests))
+		}
+	}
+}
+
+func TestWriteShardsKeepsKeysInOrderInOneShard(t *testing.T) {
+	var messages []kafka.Message
+	for i := 0; i < 20; i++ {
+		messages = append(messages, message("users", strconv.Itoa(i%5), strconv.Itoa(i)))
+	}
+
+	writer := &fakeWriter{}
+	b := newTestBatch(t, writer)
+	b.flushParallelism = 4
+
+	if _, failed := b.writeShards(messages); len(failed) != 0 {
+		t.Fatalf("expected no failed messages, got %v", failed)
+	}
+
+	shardOfKey := map[string]int{}
+	lastOfKey := map[string]int{}
+	for shard, request := range writer.requests {
+		for i := range request {
+			key := string(request[i].Key)
+			if previous, ok := shardOfKey[key]; ok && previous != shard {
+				t.Errorf("key %s written by shards %d and %d", key, previous, shard)
+			}
+			shardOfKey[key] = shard
+
+			index, _ := strconv.Atoi(string(request[i].Value))
+			if last, ok := lastOfKey[key]; ok && last > index {
+				t.Errorf("key %s wrote message %d after %d", key, index, last)
+			}
+			lastOfKey[key] = index
+		}
+	}
+}
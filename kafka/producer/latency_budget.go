@@ -0,0 +1,100 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const OriginalTopicHeader = "dcp-kafka-original-topic"
+
+// LatencyBudget reroutes staged messages older than the budget to the late topic, so the live topics
+// keep getting fresh events while the broker is slow. Without a late topic, exceeding messages are only counted.
+// The late messages are written in their own requests, concurrently with the live ones of the same flush.
+type LatencyBudget struct {
+	lateTopic string
+	budget    time.Duration
+}
+
+func NewLatencyBudget(budget time.Duration, lateTopic string) *LatencyBudget {
+	return &LatencyBudget{
+		budget:    budget,
+		lateTopic: lateTopic,
+	}
+}
+
+func (l *LatencyBudget) apply(messages []kafka.Message, eventTimes []time.Time, metric *Metric) {
+	now := time.Now()
+
+	for i := range messages {
+		message := &messages[i]
+		if l.lateTopic != "" && message.Topic == l.lateTopic {
+			continue
+		}
+		if now.Sub(eventTimes[i]) <= l.budget {
+			continue
+		}
+
+		atomic.AddInt64(&metric.LatencyBudgetExceeded, 1)
+
+		if l.lateTopic == "" {
+			continue
+		}
+
+		headers := make([]kafka.Header, 0, len(message.Headers)+1)
+		headers = append(headers, message.Headers...)
+		message.Headers = append(headers, kafka.Header{Key: OriginalTopicHeader, Value: []byte(message.Topic)})
+		message.Topic = l.lateTopic
+
+		atomic.AddInt64(&metric.LatencyBudgetShed, 1)
+	}
+}
+
+func (b *Batch) hasLate(messages []kafka.Message) bool {
+	if b.latencyBudget == nil || b.latencyBudget.lateTopic == "" {
+		return false
+	}
+	for i := range messages {
+		if messages[i].Topic == b.latencyBudget.lateTopic {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLate splits a writer group into its live and its late messages, so a throttled late topic does not hold back
+// the live messages in the same request.
+func (b *Batch) splitLate(messages []kafka.Message, group writerGroup) []writerGroup {
+	if !b.hasLate(messages) {
+		return []writerGroup{group}
+	}
+
+	live, late := writerGroup{writer: group.writer}, writerGroup{writer: group.writer}
+	for _, i := range group.indexes {
+		if messages[i].Topic == b.latencyBudget.lateTopic {
+			late.indexes = append(late.indexes, i)
+		} else {
+			live.indexes = append(live.indexes, i)
+		}
+	}
+
+	var groups []writerGroup
+	for _, g := range []writerGroup{live, late} {
+		if len(g.indexes) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// writeMigration writes the migration messages like write, the late ones in a separate request.
+func (b *Batch) writeMigration(messages []kafka.Message) bool {
+	if !b.hasLate(messages) {
+		return b.write(b.migration.writer, messages)
+	}
+
+	group := writerGroup{writer: b.migration.writer, indexes: allIndexes(len(messages))}
+	_, failed := b.writeGroups(messages, b.splitLate(messages, group))
+	return len(failed) == 0
+}
@@ -0,0 +1,65 @@
+package producer
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyHistogram counts the milliseconds from the event time of a message to the time Kafka acknowledged its write.
+type LatencyHistogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     uint64
+}
+
+func NewLatencyHistogram(buckets []float64) *LatencyHistogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &LatencyHistogram{
+		buckets: sorted,
+		counts:  make([]uint64, len(sorted)),
+	}
+}
+
+func (h *LatencyHistogram) observe(eventTime time.Time) {
+	ms := float64(time.Since(eventTime).Microseconds()) / 1000
+
+	if i := sort.SearchFloat64s(h.buckets, ms); i < len(h.buckets) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	atomic.AddUint64(&h.count, 1)
+
+	for {
+		old := atomic.LoadUint64(&h.sum)
+		if atomic.CompareAndSwapUint64(&h.sum, old, math.Float64bits(math.Float64frombits(old)+ms)) {
+			return
+		}
+	}
+}
+
+// observeWritten skips the event times of the failed indexes, which are sorted.
+func (h *LatencyHistogram) observeWritten(eventTimes []time.Time, failed []int) {
+	for i := range eventTimes {
+		if len(failed) > 0 && failed[0] == i {
+			failed = failed[1:]
+			continue
+		}
+		h.observe(eventTimes[i])
+	}
+}
+
+// Snapshot returns the count, the sum and the cumulative count of every upper bound, as prometheus expects them.
+func (h *LatencyHistogram) Snapshot() (uint64, float64, map[float64]uint64) {
+	buckets := make(map[float64]uint64, len(h.buckets))
+
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		buckets[bound] = cumulative
+	}
+	return atomic.LoadUint64(&h.count), math.Float64frombits(atomic.LoadUint64(&h.sum)), buckets
+}
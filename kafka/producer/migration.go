@@ -0,0 +1,83 @@
+package producer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// Migration decides per key whether a message goes to the current or to the new destination.
+// Routing is based on a key hash, so the same key always lands on the same side for a given percentage.
+type Migration struct {
+	topicMapping map[string]string
+	writer       Writer
+	percentage   int32
+	dualWrite    bool
+}
+
+func NewMigration(migrationConfig config.Migration, writer Writer) (*Migration, error) {
+	m := &Migration{
+		topicMapping: migrationConfig.TopicMapping,
+		writer:       writer,
+		dualWrite:    migrationConfig.DualWrite,
+	}
+
+	if err := m.SetPercentage(migrationConfig.Percentage); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Migration) SetPercentage(percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("migration percentage must be between 0 and 100, got %d", percentage)
+	}
+	atomic.StoreInt32(&m.percentage, int32(percentage))
+	return nil
+}
+
+func (m *Migration) GetPercentage() int {
+	return int(atomic.LoadInt32(&m.percentage))
+}
+
+// Route splits messages into the ones for the current destination and the ones for the new destination.
+// When dual write is enabled, shifted messages are also kept on the current destination, unless both are the same topic.
+func (m *Migration) Route(messages []kafka.Message) ([]kafka.Message, []kafka.Message) {
+	percentage := uint32(m.GetPercentage())
+	current := make([]kafka.Message, 0, len(messages))
+	var migrated []kafka.Message
+
+	for _, message := range messages {
+		h := fnv.New32a()
+		_, _ = h.Write(message.Key)
+
+		if h.Sum32()%100 >= percentage {
+			current = append(current, message)
+			continue
+		}
+
+		topic, ok := m.topicMapping[message.Topic]
+		if m.dualWrite {
+			current = append(current, message)
+			// the same topic on the same cluster is already written by the current destination
+			if !m.isCrossCluster() && (!ok || topic == message.Topic) {
+				continue
+			}
+		}
+
+		if ok {
+			message.Topic = topic
+		}
+		migrated = append(migrated, message)
+	}
+
+	return current, migrated
+}
+
+func (m *Migration) isCrossCluster() bool {
+	return m.writer != nil
+}
@@ -0,0 +1,85 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/segmentio/kafka-go"
+)
+
+const MirrorErrorHeader = "dcp-kafka-mirror-error"
+
+// Mirror produces every flushed primary batch to a second cluster. Messages are kept pending until the mirror
+// accepts them, so a mirror outage is retried on its own without producing the primary batch again.
+type Mirror struct {
+	writer          Writer
+	deadLetterTopic string
+	errorLog        *logging.Sampler
+	pending         []kafka.Message
+}
+
+func NewMirror(writer Writer, deadLetterTopic string) *Mirror {
+	return &Mirror{writer: writer, deadLetterTopic: deadLetterTopic}
+}
+
+func (m *Mirror) add(messages []kafka.Message) {
+	m.pending = append(m.pending, messages...)
+}
+
+// flush returns false while pending messages could not be written, permanent errors go to the mirror
+// dead letter topic when it is set.
+func (m *Mirror) flush(metric *Metric) bool {
+	if len(m.pending) == 0 {
+		return true
+	}
+
+	err := m.writer.WriteMessages(context.Background(), m.pending...)
+	if err != nil {
+		atomic.AddInt64(&metric.MirrorErrors, 1)
+
+		if !isFatalError(err) {
+			m.errorLog.Error("mirror", "mirror producer flush error %v", err)
+			return false
+		}
+		if m.deadLetterTopic == "" {
+			panic(fmt.Errorf("permanent error on mirror Kafka side %v", err))
+		}
+		if !m.deadLetter(err, metric) {
+			return false
+		}
+	} else {
+		atomic.AddInt64(&metric.MirrorProduced, int64(len(m.pending)))
+	}
+
+	m.pending = m.pending[:0]
+	return true
+}
+
+func (m *Mirror) deadLetter(cause error, metric *Metric) bool {
+	messages := make([]kafka.Message, 0, len(m.pending))
+	for _, message := range m.pending {
+		headers := make([]kafka.Header, 0, len(message.Headers)+2)
+		headers = append(headers, message.Headers...)
+		headers = append(headers,
+			kafka.Header{Key: OriginalTopicHeader, Value: []byte(message.Topic)},
+			kafka.Header{Key: MirrorErrorHeader, Value: []byte(cause.Error())},
+		)
+		messages = append(messages, kafka.Message{
+			Topic:   m.deadLetterTopic,
+			Key:     message.Key,
+			Value:   message.Value,
+			Headers: headers,
+		})
+	}
+
+	if err := m.writer.WriteMessages(context.Background(), messages...); err != nil {
+		atomic.AddInt64(&metric.MirrorErrors, 1)
+		m.errorLog.Error("mirrorDeadLetter", "mirror dead letter flush error %v", err)
+		return false
+	}
+
+	atomic.AddInt64(&metric.MirrorDeadLettered, int64(len(messages)))
+	return true
+}
@@ -0,0 +1,242 @@
+package producer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+	bolt "go.etcd.io/bbolt"
+)
+
+var outageBucket = []byte("batches")
+
+// outageQueue persists the batches Kafka cannot take in a bbolt file, keyed by sequence so the oldest is first. The
+// flushes go to the queue while it is not empty and the replay writes its oldest batch every interval, so the batches
+// keep their order across the outage and a restart.
+type outageQueue struct {
+	db       *bolt.DB
+	stop     chan struct{}
+	replay   sync.WaitGroup
+	interval time.Duration
+	batches  int64
+}
+
+func (b *Batch) setOutageQueue(queueConfig config.OutageQueue) error {
+	if !queueConfig.Enabled {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(queueConfig.Path), 0o700); err != nil {
+		return err
+	}
+	db, err := bolt.Open(queueConfig.Path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+
+	q := &outageQueue{db: db, stop: make(chan struct{}), interval: queueConfig.ReplayInterval}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(outageBucket)
+		if err != nil {
+			return err
+		}
+		q.batches = int64(bucket.Stats().KeyN)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot open outage queue: %w", err)
+	}
+
+	b.outage = q
+	b.metric.OutageQueueBatches = q.batches
+	return nil
+}
+
+func (q *outageQueue) pending() bool {
+	return atomic.LoadInt64(&q.batches) > 0
+}
+
+func (q *outageQueue) push(messages []kafka.Message, eventTimes []time.Time) error {
+	value, err := encodeOutageBatch(messages, eventTimes)
+	if err != nil {
+		return err
+	}
+
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(outageBucket)
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(binary.BigEndian.AppendUint64(nil, sequence), value)
+	})
+	if err == nil {
+		atomic.AddInt64(&q.batches, 1)
+	}
+	return err
+}
+
+// oldest returns a nil key when the queue is empty.
+func (q *outageQueue) oldest() (key []byte, messages []kafka.Message, eventTimes []time.Time, err error) {
+	err = q.db.View(func(tx *bolt.Tx) error {
+		k, value := tx.Bucket(outageBucket).Cursor().First()
+		if k == nil {
+			return nil
+		}
+		key = append([]byte(nil), k...)
+		messages, eventTimes, err = decodeOutageBatch(value)
+		return err
+	})
+	return key, messages, eventTimes, err
+}
+
+// replace keeps the messages of a partly replayed batch that are still unwritten, the batch is removed without them.
+func (q *outageQueue) replace(key []byte, messages []kafka.Message, eventTimes []time.Time) error {
+	if len(messages) == 0 {
+		err := q.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(outageBucket).Delete(key)
+		})
+		if err == nil {
+			atomic.AddInt64(&q.batches, -1)
+		}
+		return err
+	}
+
+	value, err := encodeOutageBatch(messages, eventTimes)
+	if err != nil {
+		return err
+	}
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(outageBucket).Put(key, value)
+	})
+}
+
+func encodeOutageBatch(messages []kafka.Message, eventTimes []time.Time) ([]byte, error) {
+	batch := make([]spilledMessage, len(messages))
+	for i := range messages {
+		batch[i] = spilledMessage{
+			Time:      messages[i].Time,
+			EventTime: eventTimes[i],
+			Topic:     messages[i].Topic,
+			Key:       messages[i].Key,
+			Value:     messages[i].Value,
+			Headers:   messages[i].Headers,
+		}
+	}
+	return jsoniter.Marshal(batch)
+}
+
+func decodeOutageBatch(value []byte) ([]kafka.Message, []time.Time, error) {
+	var batch []spilledMessage
+	if err := jsoniter.Unmarshal(value, &batch); err != nil {
+		return nil, nil, err
+	}
+
+	messages := make([]kafka.Message, len(batch))
+	eventTimes := make([]time.Time, len(batch))
+	for i := range batch {
+		messages[i], eventTimes[i] = batch[i].message(), batch[i].EventTime
+	}
+	return messages, eventTimes, nil
+}
+
+// writeOrQueue writes the buffer like writePrimary, the messages a write leaves in the buffer are queued instead of
+// retried and reported as written with the whole buffer while the queue is not empty.
+func (b *Batch) writeOrQueue() ([]kafka.Message, bool) {
+	if b.outage == nil {
+		return b.writePrimary()
+	}
+
+	var written []kafka.Message
+	if !b.outage.pending() {
+		var ok bool
+		if written, ok = b.writePrimary(); ok {
+			return written, true
+		}
+	}
+	b.queueOutage(b.messages, b.eventTimes)
+	return written, true
+}
+
+// queueOutage panics when the queue cannot persist the batch, its events may already be acknowledged.
+func (b *Batch) queueOutage(messages []kafka.Message, eventTimes []time.Time) {
+	if err := b.outage.push(messages, eventTimes); err != nil {
+		panic(fmt.Errorf("cannot queue messages for the outage: %w", err))
+	}
+	atomic.AddInt64(&b.metric.OutageQueuedMessages, int64(len(messages)))
+	atomic.StoreInt64(&b.metric.OutageQueueBatches, atomic.LoadInt64(&b.outage.batches))
+}
+
+func (b *Batch) startOutageReplay() {
+	if b.outage == nil {
+		return
+	}
+
+	b.outage.replay.Add(1)
+	go func() {
+		defer b.outage.replay.Done()
+
+		ticker := time.NewTicker(b.outage.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.outage.stop:
+				return
+			case <-ticker.C:
+			}
+			for b.replayOldest() {
+			}
+		}
+	}()
+}
+
+// replayOldest returns true when the oldest batch was written completely, so the next one is replayed at once.
+func (b *Batch) replayOldest() bool {
+	key, messages, eventTimes, err := b.outage.oldest()
+	if err != nil {
+		b.errorLog.Error("outageQueue", "cannot read outage queue %v", err)
+		return false
+	}
+	if key == nil {
+		return false
+	}
+
+	written, failed := b.writeShards(messages)
+	atomic.AddInt64(&b.metric.ProducedMessages, int64(len(written)))
+	b.metric.EndToEndLatency.observeWritten(eventTimes, failed)
+	if b.mirror != nil {
+		b.flushLock.Lock()
+		b.mirror.add(written)
+		b.flushLock.Unlock()
+	}
+
+	keptEventTimes := make([]time.Time, 0, len(failed))
+	for _, i := range failed {
+		keptEventTimes = append(keptEventTimes, eventTimes[i])
+	}
+	if err = b.outage.replace(key, collect(messages, failed), keptEventTimes); err != nil {
+		panic(fmt.Errorf("cannot update outage queue: %w", err))
+	}
+	atomic.StoreInt64(&b.metric.OutageQueueBatches, atomic.LoadInt64(&b.outage.batches))
+	return len(failed) == 0
+}
+
+// closeOutageQueue keeps the batches not replayed yet for the next start.
+func (b *Batch) closeOutageQueue() {
+	if b.outage == nil {
+		return
+	}
+
+	close(b.outage.stop)
+	b.outage.replay.Wait()
+	if err := b.outage.db.Close(); err != nil {
+		b.errorLog.Error("outageQueue", "cannot close outage queue %v", err)
+	}
+}
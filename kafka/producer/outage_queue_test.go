@@ -0,0 +1,160 @@
+package producer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/segmentio/kafka-go"
+)
+
+func newTestOutageBatch(t *testing.T, writer Writer, path string) *Batch {
+	t.Helper()
+
+	b := newTestBatch(t, writer)
+	if err := b.setOutageQueue(config.OutageQueue{Enabled: true, Path: path, ReplayInterval: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func stage(b *Batch, batch ...string) {
+	for i, value := range batch {
+		b.messages = append(b.messages, message("users", string(rune('a'+i)), value))
+		b.eventTimes = append(b.eventTimes, time.Now())
+	}
+}
+
+func valuesOf(messages []kafka.Message) []string {
+	var values []string
+	for i := range messages {
+		values = append(values, string(messages[i].Value))
+	}
+	return values
+}
+
+func assertValues(t *testing.T, expected []string, messages []kafka.Message) {
+	t.Helper()
+
+	actual := valuesOf(messages)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestOutageQueueQueuesTheBatchesDuringAnOutage(t *testing.T) {
+	writer := &fakeWriter{err: context.DeadlineExceeded}
+	b := newTestOutageBatch(t, writer, filepath.Join(t.TempDir(), "outage.db"))
+	defer b.closeOutageQueue()
+
+	stage(b, "1", "2")
+	if _, ok := b.writeOrQueue(); !ok {
+		t.Fatal("expected the failed batch to be queued")
+	}
+	if !b.outage.pending() || b.metric.OutageQueueBatches != 1 || b.metric.OutageQueuedMessages != 2 {
+		t.Fatalf("expected 1 queued batch of 2 messages, got %d batches, %d messages",
+			b.metric.OutageQueueBatches, b.metric.OutageQueuedMessages)
+	}
+
+	writer.err = nil
+	requests := len(writer.requests)
+	b.messages, b.eventTimes = b.messages[:0], b.eventTimes[:0]
+	stage(b, "3")
+	if _, ok := b.writeOrQueue(); !ok {
+		t.Fatal("expected the batch to be queued")
+	}
+	if len(writer.requests) != requests {
+		t.Fatal("expected no write while older batches are queued")
+	}
+	if b.metric.OutageQueueBatches != 2 {
+		t.Fatalf("expected 2 queued batches, got %d", b.metric.OutageQueueBatches)
+	}
+}
+
+func TestOutageQueueReplaysInOrder(t *testing.T) {
+	writer := &fakeWriter{}
+	b := newTestOutageBatch(t, writer, filepath.Join(t.TempDir(), "outage.db"))
+	defer b.closeOutageQueue()
+
+	for _, batch := range [][]string{{"1", "2"}, {"3"}, {"4", "5"}} {
+		b.messages, b.eventTimes = b.messages[:0], b.eventTimes[:0]
+		stage(b, batch...)
+		b.queueOutage(b.messages, b.eventTimes)
+	}
+
+	for b.replayOldest() {
+	}
+
+	assertValues(t, []string{"1", "2", "3", "4", "5"}, collectRequests(writer))
+	if b.outage.pending() || b.metric.OutageQueueBatches != 0 {
+		t.Fatalf("expected an empty queue, got %d batches", b.metric.OutageQueueBatches)
+	}
+	if b.metric.ProducedMessages != 5 {
+		t.Fatalf("expected 5 produced messages, got %d", b.metric.ProducedMessages)
+	}
+}
+
+func TestOutageQueueKeepsTheUnwrittenMessagesOfAPartialReplay(t *testing.T) {
+	writer := &fakeWriter{failValue: "fail"}
+	b := newTestOutageBatch(t, writer, filepath.Join(t.TempDir(), "outage.db"))
+	defer b.closeOutageQueue()
+
+	stage(b, "1", "fail", "3")
+	b.queueOutage(b.messages, b.eventTimes)
+
+	if b.replayOldest() {
+		t.Fatal("expected the partial replay to stop")
+	}
+	key, messages, eventTimes, err := b.outage.oldest()
+	if err != nil || key == nil {
+		t.Fatalf("expected the batch to stay queued, got %v", err)
+	}
+	assertValues(t, []string{"fail"}, messages)
+	if len(eventTimes) != 1 || b.metric.OutageQueueBatches != 1 || b.metric.ProducedMessages != 2 {
+		t.Fatalf("expected 1 batch with 2 produced messages, got %d batches, %d produced",
+			b.metric.OutageQueueBatches, b.metric.ProducedMessages)
+	}
+
+	writer.failValue = ""
+	if !b.replayOldest() {
+		t.Fatal("expected the rest of the batch to be written")
+	}
+	assertValues(t, []string{"fail"}, writer.requests[len(writer.requests)-1])
+	if b.outage.pending() {
+		t.Fatal("expected an empty queue")
+	}
+}
+
+func TestOutageQueueKeepsThePendingBatchesAcrossARestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outage.db")
+
+	b := newTestOutageBatch(t, &fakeWriter{err: context.DeadlineExceeded}, path)
+	stage(b, "1", "2")
+	b.queueOutage(b.messages, b.eventTimes)
+	b.messages, b.eventTimes = b.messages[:0], b.eventTimes[:0]
+	stage(b, "3")
+	b.queueOutage(b.messages, b.eventTimes)
+	b.closeOutageQueue()
+
+	writer := &fakeWriter{}
+	restarted := newTestOutageBatch(t, writer, path)
+	defer restarted.closeOutageQueue()
+
+	if !restarted.outage.pending() || restarted.metric.OutageQueueBatches != 2 {
+		t.Fatalf("expected 2 pending batches after the restart, got %d", restarted.metric.OutageQueueBatches)
+	}
+	for restarted.replayOldest() {
+	}
+	if len(writer.requests) != 2 {
+		t.Fatalf("expected 2 replayed batches, got %d", len(writer.requests))
+	}
+	assertValues(t, []string{"1", "2"}, writer.requests[0])
+	assertValues(t, []string{"3"}, writer.requests[1])
+}
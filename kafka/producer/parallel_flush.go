@@ -0,0 +1,122 @@
+package producer
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// writePrimary returns the messages written to the primary writer and whether all of them were written.
+// Messages that were not written stay in the batch for the next flush.
+func (b *Batch) writePrimary() ([]kafka.Message, bool) {
+	written, failed := b.writeShards(b.messages)
+	b.metric.EndToEndLatency.observeWritten(b.eventTimes, failed)
+	if len(failed) == 0 {
+		return written, true
+	}
+
+	var kept []kafka.Message
+	var keptEventTimes []time.Time
+	for _, i := range failed {
+		kept = append(kept, b.messages[i])
+		keptEventTimes = append(keptEventTimes, b.eventTimes[i])
+	}
+
+	b.messages = append(b.messages[:0], kept...)
+	b.eventTimes = append(b.eventTimes[:0], keptEventTimes...)
+	return written, false
+}
+
+// writeShards splits the messages into shards by writer, late topic, topic and key hash and writes them concurrently,
+// one goroutine per shard. All messages of a key share a shard and keep their order. It returns the written messages
+// and the indexes of the messages to retry, so successful shards and partitions are not produced twice.
+func (b *Batch) writeShards(messages []kafka.Message) ([]kafka.Message, []int) {
+	writer := b.currentWriter()
+	if b.flushParallelism <= 1 && len(b.topicWriters) == 0 && !b.hasLate(messages) {
+		failed := b.writeTracked(writer, messages)
+		return without(messages, failed), failed
+	}
+
+	var shards []writerGroup
+	for _, group := range b.groupByWriter(writer, messages) {
+		for _, split := range b.splitLate(messages, group) {
+			shards = append(shards, b.hashShards(messages, split)...)
+		}
+	}
+	return b.writeGroups(messages, shards)
+}
+
+func (b *Batch) hashShards(messages []kafka.Message, group writerGroup) []writerGroup {
+	if b.flushParallelism <= 1 {
+		return []writerGroup{group}
+	}
+
+	hashed := make([][]int, b.flushParallelism)
+	for _, i := range group.indexes {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(messages[i].Topic))
+		_, _ = h.Write(messages[i].Key)
+		shard := h.Sum32() % uint32(b.flushParallelism)
+		hashed[shard] = append(hashed[shard], i)
+	}
+
+	var shards []writerGroup
+	for _, indexes := range hashed {
+		if len(indexes) > 0 {
+			shards = append(shards, writerGroup{writer: group.writer, indexes: indexes})
+		}
+	}
+	return shards
+}
+
+// writeGroups writes the groups concurrently and returns the written messages and the sorted indexes to retry.
+func (b *Batch) writeGroups(messages []kafka.Message, shards []writerGroup) ([]kafka.Message, []int) {
+	failedShards := make([][]int, len(shards))
+
+	var wg sync.WaitGroup
+	for i := range shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			failedShards[i] = b.writeTracked(shards[i].writer, collect(messages, shards[i].indexes))
+		}(i)
+	}
+	wg.Wait()
+
+	var written []kafka.Message
+	var failed []int
+	for i := range shards {
+		shardMessages := collect(messages, shards[i].indexes)
+		written = append(written, without(shardMessages, failedShards[i])...)
+		for _, j := range failedShards[i] {
+			failed = append(failed, shards[i].indexes[j])
+		}
+	}
+	sort.Ints(failed)
+	return written, failed
+}
+
+func collect(messages []kafka.Message, indexes []int) []kafka.Message {
+	collected := make([]kafka.Message, 0, len(indexes))
+	for _, i := range indexes {
+		collected = append(collected, messages[i])
+	}
+	return collected
+}
+
+func (b *Batch) currentWriter() Writer {
+	b.writerLock.RLock()
+	defer b.writerLock.RUnlock()
+
+	return b.Writer
+}
+
+func (b *Batch) setWriter(writer Writer) {
+	b.writerLock.Lock()
+	defer b.writerLock.Unlock()
+
+	b.Writer = writer
+}
@@ -1,17 +1,65 @@
 package producer
 
 import (
+	"errors"
 	"time"
 
 	"github.com/Trendyol/go-dcp-kafka/config"
 	gKafka "github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/logging"
 	"github.com/Trendyol/go-dcp/models"
 	"github.com/segmentio/kafka-go"
 )
 
 type Metric struct {
-	KafkaConnectorLatency int64
-	BatchProduceLatency   int64
+	BatchProduceLatency      int64
+	JSONComplexityExceeded   int64
+	OversizedDocuments       int64
+	ProcessingErrors         int64
+	MigrationCurrentRouted   int64
+	MigrationNewRouted       int64
+	EnrichmentErrors         int64
+	XattrErrors              int64
+	LatencyBudgetExceeded    int64
+	LatencyBudgetShed        int64
+	StaleFlushes             int64
+	BufferOverflows          int64
+	BufferDroppedMessages    int64
+	SpilledMessages          int64
+	OutageQueuedMessages     int64
+	OutageQueueBatches       int64
+	Rebalances               int64
+	RebalanceDuration        int64
+	RebalanceHeldMessages    int64
+	RebalanceDroppedMessages int64
+	BatchLimit               int64
+	BatchTickerDuration      int64
+	AtMostOnceDropped        int64
+	FilteredEvents           int64
+	SampledOutEvents         int64
+	SkippedBinaryDocuments   int64
+	SchemaRegistryFallbacks  int64
+	DedupSuppressed          int64
+	SeqNoDedupSkipped        int64
+	ChunkedMessages          int64
+	CompressedValues         int64
+	EncryptedValues          int64
+	ClaimCheckedMessages     int64
+	ProducedBytes            int64
+	EstimatedWireBytes       int64
+	ProduceErrors            int64
+	MirrorProduced           int64
+	MirrorErrors             int64
+	MirrorDeadLettered       int64
+	ChaosDelays              int64
+	ChaosErrors              int64
+	Failovers                int64
+	DisabledCollectionEvents int64
+	ProducedMessages         int64
+	CheckpointCommits        int64
+	RollbacksDetected        int64
+	Topics                   *TopicMetrics
+	EndToEndLatency          *LatencyHistogram
 }
 
 type Producer struct {
@@ -21,22 +69,154 @@ type Producer struct {
 func NewProducer(kafkaClient gKafka.Client,
 	config *config.Connector,
 	dcpCheckpointCommit func(),
+	wrapWriter WriterWrapper,
 ) (Producer, error) {
 	writer := kafkaClient.Producer()
 
+	batch := newBatch(
+		config.Kafka.ProducerBatchTickerDuration,
+		wrapWriter.wrap(writer),
+		config.Kafka.ProducerBatchSize,
+		config.Kafka.ProducerBatchBytes,
+		dcpCheckpointCommit,
+	)
+	if config.Kafka.WriterStats.Enabled {
+		batch.writerStats = NewWriterStats(config.Kafka.WriterStats.Interval)
+	}
+	batch.trackWriter("primary", batch.reporting(writer))
+
+	batch.flushParallelism = config.Kafka.ProducerFlushParallelism
+	batch.topicWriters = newTopicWriters(func() *kafka.Writer {
+		return batch.trackWriter("primary", batch.reporting(kafkaClient.Producer()))
+	}, config.Kafka.TopicOverrides, wrapWriter)
+	batch.deferAcks = isFlushAckMode(&config.Kafka)
+	if err := batch.setDeliverySemantics(&config.Kafka); err != nil {
+		return Producer{}, err
+	}
+	batch.flushTimeout = config.Kafka.ProducerFlushTimeout
+	batch.failureTimeout = config.Kafka.ProducerFailureTimeout
+	batch.shadow = config.Kafka.ShadowMode
+	batch.commitInterval = config.Kafka.CheckpointCommitInterval
+	batch.commitEveryFlushes = config.Kafka.CheckpointCommitEveryFlushes
+	if isRebalanceFlush(&config.Kafka) {
+		batch.rebalanceFlushTimeout = config.Kafka.RebalanceFlushTimeout
+	}
+
+	if config.Kafka.ErrorLogSampling.Enabled {
+		batch.errorLog = logging.NewSampler(config.Kafka.ErrorLogSampling.Interval, config.Kafka.ErrorLogSampling.Burst)
+	}
+	batch.metric.EndToEndLatency = NewLatencyHistogram(config.Kafka.EndToEndLatencyBuckets)
+
+	if err := batch.setFlushLimits(&config.Kafka); err != nil {
+		return Producer{}, err
+	}
+
+	if err := batch.setSecondaryWriters(kafkaClient, &config.Kafka, wrapWriter, writer); err != nil {
+		return Producer{}, err
+	}
+
 	return Producer{
-		ProducerBatch: newBatch(
-			config.Kafka.ProducerBatchTickerDuration,
-			writer,
-			config.Kafka.ProducerBatchSize,
-			config.Kafka.ProducerBatchBytes,
-			dcpCheckpointCommit,
-		),
+		ProducerBatch: batch,
 	}, nil
 }
 
+func (b *Batch) setFlushLimits(kafkaConfig *config.Kafka) error {
+	if kafkaConfig.SyncProduce.Enabled {
+		b.sync = &syncProduce{
+			retryInterval: kafkaConfig.SyncProduce.RetryInterval,
+			maxRetries:    kafkaConfig.SyncProduce.MaxRetries,
+		}
+	}
+
+	if kafkaConfig.ProducerMaxInFlightFlushes > 1 {
+		if !b.deferAcks {
+			return errors.New("producerMaxInFlightFlushes above 1 requires flush ack mode")
+		}
+		b.inFlight = make(chan struct{}, kafkaConfig.ProducerMaxInFlightFlushes)
+	}
+
+	rateLimiter, err := NewRateLimiter(kafkaConfig.RateLimit.MessagesPerSecond, kafkaConfig.RateLimit.BytesPerSecond)
+	if err != nil {
+		return err
+	}
+	b.rateLimiter = rateLimiter
+
+	if kafkaConfig.LatencyBudget.Budget > 0 {
+		b.latencyBudget = NewLatencyBudget(kafkaConfig.LatencyBudget.Budget, kafkaConfig.LatencyBudget.LateTopic)
+	}
+	if kafkaConfig.AdaptiveBatch.Enabled {
+		b.adaptive = newAdaptiveBatch(kafkaConfig.AdaptiveBatch)
+	}
+	if err = b.setBufferLimit(kafkaConfig.BufferLimit); err != nil {
+		return err
+	}
+	if err = b.setOutageQueue(kafkaConfig.OutageQueue); err != nil {
+		return err
+	}
+	if kafkaConfig.LatencyBudget.MaxStaleness > 0 {
+		b.startStalenessFlush(kafkaConfig.LatencyBudget.MaxStaleness)
+	}
+	return nil
+}
+
+// setSecondaryWriters sets the failover, mirror and migration writers next to the primary one, and the chaos and
+// compression stats of the primary one.
+func (b *Batch) setSecondaryWriters(
+	kafkaClient gKafka.Client, kafkaConfig *config.Kafka, wrapWriter WriterWrapper, writer *kafka.Writer,
+) error {
+	if kafkaConfig.CompressionStats.Enabled {
+		b.compressionStats = NewCompressionStats(writer.Compression, kafkaConfig.CompressionStats.SampleRate)
+	}
+
+	if kafkaConfig.Failover.Enabled {
+		standby := b.trackWriter("standby", b.reporting(kafkaClient.ClusterProducer(kafkaConfig.Failover.Brokers)))
+		b.failover = NewFailover(
+			b.Writer, kafkaConfig.Brokers,
+			wrapWriter.wrap(standby), kafkaConfig.Failover.Brokers,
+			kafkaConfig.Failover.Threshold,
+		)
+	}
+
+	if kafkaConfig.Chaos.Enabled {
+		chaos, err := NewChaos(kafkaConfig.Chaos)
+		if err != nil {
+			return err
+		}
+		b.chaos = chaos
+	}
+
+	if kafkaConfig.Mirror.Enabled {
+		mirrorWriter := kafkaClient.ClusterProducer(kafkaConfig.Mirror.Brokers)
+		if kafkaConfig.Mirror.MaxAttempts > 0 {
+			mirrorWriter.MaxAttempts = kafkaConfig.Mirror.MaxAttempts
+		}
+		b.mirror = NewMirror(wrapWriter.wrap(b.trackWriter("mirror", mirrorWriter)), kafkaConfig.Mirror.DeadLetterTopic)
+		b.mirror.errorLog = b.errorLog
+	}
+
+	if kafkaConfig.Migration.Enabled {
+		var migrationWriter Writer
+		if len(kafkaConfig.Migration.Brokers) > 0 {
+			migrationWriter = wrapWriter.wrap(
+				b.trackWriter("migration", b.reporting(kafkaClient.ClusterProducer(kafkaConfig.Migration.Brokers))),
+			)
+		}
+
+		migration, err := NewMigration(kafkaConfig.Migration, migrationWriter)
+		if err != nil {
+			return err
+		}
+		b.migration = migration
+	}
+	return nil
+}
+
 func (p *Producer) StartBatch() {
+	if p.ProducerBatch.writerStats != nil {
+		p.ProducerBatch.writerStats.Start()
+	}
 	p.ProducerBatch.StartBatchTicker()
+	p.ProducerBatch.startOutageReplay()
 }
 
 func (p *Producer) Produce(
@@ -44,14 +224,78 @@ func (p *Producer) Produce(
 	eventTime time.Time,
 	messages []kafka.Message,
 ) {
+	if p.ProducerBatch.sync != nil {
+		p.ProducerBatch.produceSync(ctx, messages, eventTime)
+		return
+	}
 	p.ProducerBatch.AddMessages(ctx, messages, eventTime)
 }
 
 func (p *Producer) Close() error {
 	p.ProducerBatch.Close()
+	if p.ProducerBatch.writerStats != nil {
+		p.ProducerBatch.writerStats.Close()
+	}
+	if err := p.ProducerBatch.closeTopicWriters(); err != nil {
+		return err
+	}
+	if migration := p.ProducerBatch.migration; migration != nil && migration.isCrossCluster() {
+		if err := migration.writer.Close(); err != nil {
+			return err
+		}
+	}
+	if failover := p.ProducerBatch.failover; failover != nil {
+		if err := failover.close(); err != nil {
+			return err
+		}
+	}
+	if mirror := p.ProducerBatch.mirror; mirror != nil {
+		if err := mirror.writer.Close(); err != nil {
+			return err
+		}
+	}
 	return p.ProducerBatch.Writer.Close()
 }
 
+// GetFailover returns nil when failover is not enabled.
+func (p *Producer) GetFailover() *Failover {
+	return p.ProducerBatch.failover
+}
+
+// GetChaos returns nil when chaos injection is not enabled.
+func (p *Producer) GetChaos() *Chaos {
+	return p.ProducerBatch.chaos
+}
+
+// GetMirror returns nil when mirroring is not enabled.
+func (p *Producer) GetMirror() *Mirror {
+	return p.ProducerBatch.mirror
+}
+
+func (p *Producer) GetRateLimiter() *RateLimiter {
+	return p.ProducerBatch.rateLimiter
+}
+
+// GetMigration returns nil when migration mode is not enabled.
+func (p *Producer) GetMigration() *Migration {
+	return p.ProducerBatch.migration
+}
+
+// IsShadow reports whether the writes are skipped and only logged, see kafka.shadowMode.
+func (p *Producer) IsShadow() bool {
+	return p.ProducerBatch.shadow
+}
+
+// GetWriterStats returns nil when writer stats are not enabled.
+func (p *Producer) GetWriterStats() *WriterStats {
+	return p.ProducerBatch.writerStats
+}
+
+// GetCompressionStats returns nil when compression stats are not enabled.
+func (p *Producer) GetCompressionStats() *CompressionStats {
+	return p.ProducerBatch.compressionStats
+}
+
 func (p *Producer) GetMetric() *Metric {
 	return p.ProducerBatch.metric
 }
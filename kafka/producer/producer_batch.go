@@ -2,37 +2,75 @@ package producer
 
 import (
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/Trendyol/go-dcp/logger"
-
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/logging"
 	"github.com/Trendyol/go-dcp/models"
 	"github.com/segmentio/kafka-go"
 )
 
 type Batch struct {
-	batchTicker         *time.Ticker
-	Writer              *kafka.Writer
-	dcpCheckpointCommit func()
-	metric              *Metric
-	messages            []kafka.Message
-	currentMessageBytes int64
-	batchTickerDuration time.Duration
-	batchLimit          int
-	batchBytes          int64
-	flushLock           sync.Mutex
-	isDcpRebalancing    bool
+	batchTicker           *time.Ticker
+	stalenessTimer        *time.Timer
+	Writer                Writer
+	dcpCheckpointCommit   func()
+	metric                *Metric
+	migration             *Migration
+	rateLimiter           *RateLimiter
+	latencyBudget         *LatencyBudget
+	adaptive              *adaptiveBatch
+	bufferLimit           *bufferLimit
+	outage                *outageQueue
+	flushHooks            FlushHooks
+	compressionStats      *CompressionStats
+	mirror                *Mirror
+	chaos                 *Chaos
+	failover              *Failover
+	onDelivery            func(report DeliveryReport)
+	writerStats           *WriterStats
+	topicWriters          map[string]Writer
+	flushParallelism      int
+	flushTimeout          time.Duration
+	failureTimeout        time.Duration
+	failingSince          time.Time
+	rebalanceFlushTimeout time.Duration
+	commitInterval        time.Duration
+	commitEveryFlushes    int
+	uncommittedFlushes    int
+	lastCommit            time.Time
+	oldestBufferedAt      time.Time
+	maxStaleness          time.Duration
+	sync                  *syncProduce
+	errorLog              *logging.Sampler
+	inFlight              chan struct{}
+	flights               []*flight
+	deferAcks             bool
+	acks                  []func()
+	messages              []kafka.Message
+	eventTimes            []time.Time
+	migrationMessages     []kafka.Message
+	migrationEventTimes   []time.Time
+	currentMessageBytes   int64
+	batchTickerDuration   time.Duration
+	batchLimit            int
+	batchBytes            int64
+	flushLock             sync.Mutex
+	writerLock            sync.RWMutex
+	isDcpRebalancing      bool
+	shadow                bool
+	atMostOnce            bool
 }
 
 func newBatch(
 	batchTime time.Duration,
-	writer *kafka.Writer,
+	writer Writer,
 	batchLimit int,
 	batchBytes int64,
 	dcpCheckpointCommit func(),
@@ -40,13 +78,15 @@ func newBatch(
 	batch := &Batch{
 		batchTickerDuration: batchTime,
 		batchTicker:         time.NewTicker(batchTime),
-		metric:              &Metric{},
+		metric:              &Metric{Topics: NewTopicMetrics()},
 		messages:            make([]kafka.Message, 0, batchLimit),
 		Writer:              writer,
 		batchLimit:          batchLimit,
 		dcpCheckpointCommit: dcpCheckpointCommit,
 		batchBytes:          batchBytes,
 	}
+	batch.metric.BatchLimit = int64(batchLimit)
+	batch.metric.BatchTickerDuration = batchTime.Milliseconds()
 	return batch
 }
 
@@ -61,16 +101,63 @@ func (b *Batch) StartBatchTicker() {
 
 func (b *Batch) Close() {
 	b.batchTicker.Stop()
+	if b.stalenessTimer != nil {
+		b.stalenessTimer.Stop()
+	}
 	b.FlushMessages()
+	b.waitFlights()
+	b.closeSpill()
+	b.closeOutageQueue()
+	b.commitSkippedCheckpoint()
+}
+
+// SetLimits changes the flush triggers of the batch at runtime, e.g. on a config reload.
+func (b *Batch) SetLimits(batchLimit int, batchBytes int64, batchTickerDuration time.Duration) {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	b.batchLimit = batchLimit
+	b.batchBytes = batchBytes
+	if batchTickerDuration != b.batchTickerDuration {
+		b.batchTickerDuration = batchTickerDuration
+		b.batchTicker.Reset(batchTickerDuration)
+	}
+	atomic.StoreInt64(&b.metric.BatchLimit, int64(batchLimit))
+	atomic.StoreInt64(&b.metric.BatchTickerDuration, batchTickerDuration.Milliseconds())
 }
 
+// PrepareStartRebalancing discards the buffer, its events are streamed again from the last checkpoint.
+// With a rebalance flush timeout the buffer is written first, see flushForRebalancing.
 func (b *Batch) PrepareStartRebalancing() {
+	b.flushLock.Lock()
+	atomic.AddInt64(&b.metric.RebalanceHeldMessages, int64(b.rebalancePending()))
+	b.flushLock.Unlock()
+
+	if b.rebalanceFlushTimeout > 0 {
+		b.flushForRebalancing()
+	}
+
 	b.flushLock.Lock()
 	defer b.flushLock.Unlock()
 
+	atomic.AddInt64(&b.metric.RebalanceDroppedMessages, int64(b.rebalancePending()))
 	b.isDcpRebalancing = true
 	b.messages = b.messages[:0]
+	b.eventTimes = b.eventTimes[:0]
+	b.migrationMessages = b.migrationMessages[:0]
+	b.migrationEventTimes = b.migrationEventTimes[:0]
+	if b.mirror != nil {
+		b.mirror.pending = b.mirror.pending[:0]
+	}
+	b.acks = b.acks[:0]
+	b.flights = nil
 	b.currentMessageBytes = 0
+	b.rewindSpill()
+}
+
+// rebalancePending is the messages a rebalance holds, the spilled ones are kept across it.
+func (b *Batch) rebalancePending() int {
+	return b.pending() - b.spilled()
 }
 
 func (b *Batch) PrepareEndRebalancing() {
@@ -81,53 +168,309 @@ func (b *Batch) PrepareEndRebalancing() {
 }
 
 func (b *Batch) AddMessages(ctx *models.ListenerContext, messages []kafka.Message, eventTime time.Time) {
+	size := messageBytes(messages)
+	if b.bufferLimit != nil && b.bufferLimit.policy == config.BufferOverflowBlock {
+		b.waitForBuffer(size)
+	}
+
 	b.flushLock.Lock()
 	if b.isDcpRebalancing {
-		logger.Log.Error("could not add new message to batch while rebalancing")
+		b.errorLog.Error("rebalancing", "could not add new message to batch while rebalancing")
+		atomic.AddInt64(&b.metric.RebalanceDroppedMessages, int64(len(messages)))
 		b.flushLock.Unlock()
 		return
 	}
-	b.messages = append(b.messages, messages...)
-	b.currentMessageBytes += int64(binary.Size(messages))
-	ctx.Ack()
+	if b.bufferLimit == nil || !b.limitBuffer(messages, size, eventTime) {
+		b.buffer(messages, eventTime)
+	}
+	if !b.deferAcks {
+		ctx.Ack()
+	}
+	full := len(b.messages)+len(b.migrationMessages) >= b.batchLimit || b.currentMessageBytes >= b.batchBytes
 	b.flushLock.Unlock()
 
-	b.metric.KafkaConnectorLatency = time.Since(eventTime).Milliseconds()
+	// in flush ack mode ctx.Ack is wrapped by DeferAck and takes the flush lock itself
+	if b.deferAcks {
+		ctx.Ack()
+	}
 
-	if len(b.messages) >= b.batchLimit || b.currentMessageBytes >= b.batchBytes {
+	if full {
 		b.FlushMessages()
 	}
 }
 
+// buffer runs under the flush lock.
+func (b *Batch) buffer(messages []kafka.Message, eventTime time.Time) {
+	if len(b.messages)+len(b.migrationMessages) == 0 {
+		b.bufferedFirst()
+	}
+	if b.migration != nil {
+		messages = b.routeMigration(messages, eventTime)
+	}
+	b.messages = append(b.messages, messages...)
+	for range messages {
+		b.eventTimes = append(b.eventTimes, eventTime)
+	}
+	b.currentMessageBytes += messageBytes(messages)
+}
+
 func (b *Batch) FlushMessages() {
+	if b.inFlight != nil {
+		b.startFlight()
+		return
+	}
+
 	b.flushLock.Lock()
 	defer b.flushLock.Unlock()
 	if b.isDcpRebalancing {
 		return
 	}
-	if len(b.messages) > 0 {
-		startedTime := time.Now()
-		err := b.Writer.WriteMessages(context.Background(), b.messages...)
-		if err != nil {
-			if isFatalError(err) {
-				panic(fmt.Errorf("permanent error on Kafka side %v", err))
+	b.refill()
+	if len(b.messages) > 0 || len(b.migrationMessages) > 0 {
+		b.commitBeforeWrite()
+	}
+	if len(b.messages) > 0 && !b.flushBuffered() {
+		return
+	}
+	if len(b.migrationMessages) > 0 && !b.flushMigration() {
+		return
+	}
+	b.failingSince = time.Time{}
+	if b.mirror != nil && !b.mirror.flush(b.metric) {
+		return
+	}
+	b.releaseAcks()
+	b.commitCheckpoint()
+	b.refill()
+}
+
+// flushBuffered runs under the flush lock, it returns false when the messages are kept for a retry.
+func (b *Batch) flushBuffered() bool {
+	if b.latencyBudget != nil {
+		b.latencyBudget.apply(b.messages, b.eventTimes, b.metric)
+	}
+	b.beforeFlush(b.messages)
+
+	startedTime := time.Now()
+	count := len(b.messages)
+	written, ok := b.writeOrQueue()
+	b.afterFlush(count, written, time.Since(startedTime))
+	atomic.AddInt64(&b.metric.ProducedMessages, int64(len(written)))
+
+	if b.compressionStats != nil {
+		b.compressionStats.record(written, b.metric)
+	}
+
+	if b.mirror != nil {
+		b.mirror.add(written)
+	}
+
+	if !ok && !b.atMostOnce {
+		b.checkFailing(&b.failingSince, len(b.messages))
+		return false
+	}
+	if !ok {
+		b.dropUnwritten(len(b.messages))
+	}
+	latency := time.Since(startedTime)
+	b.metric.BatchProduceLatency = latency.Milliseconds()
+	if b.adaptive != nil {
+		b.adaptive.observe(b, len(b.messages), latency)
+	}
+
+	b.messages = b.messages[:0]
+	b.eventTimes = b.eventTimes[:0]
+	b.currentMessageBytes = 0
+	b.batchTicker.Reset(b.batchTickerDuration)
+	b.bufferWritten()
+	return true
+}
+
+// flushMigration runs under the flush lock, it returns false when the migration messages are kept for a retry.
+func (b *Batch) flushMigration() bool {
+	if b.latencyBudget != nil {
+		b.latencyBudget.apply(b.migrationMessages, b.migrationEventTimes, b.metric)
+	}
+	if !b.writeMigration(b.migrationMessages) {
+		if !b.atMostOnce {
+			b.checkFailing(&b.failingSince, len(b.migrationMessages))
+			return false
+		}
+		b.dropUnwritten(len(b.migrationMessages))
+	}
+	b.migrationMessages = b.migrationMessages[:0]
+	b.migrationEventTimes = b.migrationEventTimes[:0]
+	return true
+}
+
+// checkFailing panics once the writes keep failing for kafka.producerFailureTimeout, so a misconfigured topic surfaces
+// instead of being retried forever. since is the first failure, zero while the writes succeed.
+func (b *Batch) checkFailing(since *time.Time, unwritten int) {
+	if b.failureTimeout <= 0 {
+		return
+	}
+	if since.IsZero() {
+		*since = time.Now()
+		return
+	}
+	if elapsed := time.Since(*since); elapsed >= b.failureTimeout {
+		panic(fmt.Errorf("batch producer could not write %d messages for %v", unwritten, elapsed.Round(time.Millisecond)))
+	}
+}
+
+// Pending returns the number of messages that are acknowledged but not written yet, including the mirror ones.
+func (b *Batch) Pending() int {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	return b.pending()
+}
+
+// BatchStats is the memory held by the batch, Capacity is the allocated length of the messages buffer.
+type BatchStats struct {
+	Messages          int   `json:"messages"`
+	Capacity          int   `json:"capacity"`
+	MigrationMessages int   `json:"migrationMessages"`
+	Bytes             int64 `json:"bytes"`
+	Flights           int   `json:"flights"`
+	DeferredAcks      int   `json:"deferredAcks"`
+	Pending           int   `json:"pending"`
+}
+
+func (b *Batch) Stats() BatchStats {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	return BatchStats{
+		Messages:          len(b.messages),
+		Capacity:          cap(b.messages),
+		MigrationMessages: len(b.migrationMessages),
+		Bytes:             b.currentMessageBytes,
+		Flights:           len(b.flights),
+		DeferredAcks:      len(b.acks),
+		Pending:           b.pending(),
+	}
+}
+
+func (b *Batch) pending() int {
+	pending := len(b.messages) + len(b.migrationMessages) + b.spilled()
+	if b.mirror != nil {
+		pending += len(b.mirror.pending)
+	}
+	for _, f := range b.flights {
+		if !f.done {
+			pending += len(f.messages) + len(f.migrationMessages)
+		}
+	}
+	return pending
+}
+
+func (b *Batch) write(writer Writer, messages []kafka.Message) bool {
+	return len(b.writeTracked(writer, messages)) == 0
+}
+
+// writeTracked returns the indexes of the messages to retry in order, see retryIndexes.
+func (b *Batch) writeTracked(writer Writer, messages []kafka.Message) []int {
+	if err := b.rateLimiter.Wait(context.Background(), messages); err != nil {
+		b.errorLog.Error("rateLimiter", "batch producer rate limiter error %v", err)
+		return allIndexes(len(messages))
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if b.flushTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.flushTimeout)
+	}
+	defer cancel()
+
+	watchFailover := b.failover != nil && writer == b.currentWriter() && !b.failover.IsActive()
+	if watchFailover {
+		var failoverCancel context.CancelFunc
+		ctx, failoverCancel = context.WithTimeout(ctx, b.failover.threshold)
+		defer failoverCancel()
+	}
+
+	started := time.Now()
+
+	var err error
+	if b.chaos != nil {
+		err = b.chaos.inject(b.metric)
+	}
+	if err == nil && b.shadow {
+		logShadowWrite(messages)
+	} else if err == nil {
+		err = writer.WriteMessages(ctx, messages...)
+	}
+	if err != nil {
+		atomic.AddInt64(&b.metric.ProduceErrors, 1)
+		b.metric.Topics.recordErrors(messages, err)
+		if watchFailover {
+			if handled, next := b.failover.observe(err, started, b.metric); handled {
+				b.setWriter(next)
+				return allIndexes(len(messages))
 			}
-			logger.Log.Error("batch producer flush error %v", err)
-			return
 		}
-		b.metric.BatchProduceLatency = time.Since(startedTime).Milliseconds()
+		if isFatalError(err) {
+			panic(fmt.Errorf("permanent error on Kafka side %v", err))
+		}
+		b.errorLog.Error("flush", "batch producer flush error %v", err)
 
-		b.messages = b.messages[:0]
-		b.currentMessageBytes = 0
-		b.batchTicker.Reset(b.batchTickerDuration)
+		retried := retryIndexes(messages, err)
+		b.metric.Topics.recordWritten(without(messages, retried))
+		return retried
+	}
+	if watchFailover {
+		b.failover.succeeded()
 	}
-	b.dcpCheckpointCommit()
+	b.metric.Topics.recordWritten(messages)
+	return nil
 }
 
+func (b *Batch) routeMigration(messages []kafka.Message, eventTime time.Time) []kafka.Message {
+	current, migrated := b.migration.Route(messages)
+
+	atomic.AddInt64(&b.metric.MigrationCurrentRouted, int64(len(current)))
+	atomic.AddInt64(&b.metric.MigrationNewRouted, int64(len(migrated)))
+
+	if b.migration.isCrossCluster() {
+		b.migrationMessages = append(b.migrationMessages, migrated...)
+		for range migrated {
+			b.migrationEventTimes = append(b.migrationEventTimes, eventTime)
+		}
+		b.currentMessageBytes += messageBytes(migrated)
+		return current
+	}
+	return append(current, migrated...)
+}
+
+// messageBytes is the size of the messages as buffered, without the framing of the Kafka protocol.
+func messageBytes(messages []kafka.Message) int64 {
+	var size int
+	for i := range messages {
+		size += len(messages[i].Topic) + len(messages[i].Key) + len(messages[i].Value)
+		for _, header := range messages[i].Headers {
+			size += len(header.Key) + len(header.Value)
+		}
+	}
+	return int64(size)
+}
+
+// isFatalError checks every error of a kafka.WriteErrors, a partial failure is only fatal for a fatal message error.
 func isFatalError(err error) bool {
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) {
+		for _, writeErr := range writeErrors {
+			if writeErr != nil && isFatalError(writeErr) {
+				return true
+			}
+		}
+		return false
+	}
+
 	e, ok := err.(kafka.Error)
 
 	if (ok && e.Temporary()) ||
+		errors.Is(err, ErrChaosInjected) ||
+		errors.Is(err, context.DeadlineExceeded) ||
 		errors.Is(err, io.ErrUnexpectedEOF) ||
 		errors.Is(err, syscall.ECONNREFUSED) ||
 		errors.Is(err, syscall.ECONNRESET) ||
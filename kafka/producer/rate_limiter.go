@@ -0,0 +1,99 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles flushes by message count and byte size, shared by all topics.
+// A zero limit means unlimited.
+type RateLimiter struct {
+	messages *rate.Limiter
+	bytes    *rate.Limiter
+}
+
+func NewRateLimiter(messagesPerSecond int, bytesPerSecond int) (*RateLimiter, error) {
+	r := &RateLimiter{
+		messages: rate.NewLimiter(rate.Inf, 0),
+		bytes:    rate.NewLimiter(rate.Inf, 0),
+	}
+
+	if err := r.SetLimits(messagesPerSecond, bytesPerSecond); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RateLimiter) SetLimits(messagesPerSecond int, bytesPerSecond int) error {
+	if messagesPerSecond < 0 || bytesPerSecond < 0 {
+		return fmt.Errorf("rate limits cannot be negative, messagesPerSecond: %d, bytesPerSecond: %d",
+			messagesPerSecond, bytesPerSecond)
+	}
+
+	setLimit(r.messages, messagesPerSecond)
+	setLimit(r.bytes, bytesPerSecond)
+	return nil
+}
+
+func (r *RateLimiter) GetLimits() (int, int) {
+	return getLimit(r.messages), getLimit(r.bytes)
+}
+
+func (r *RateLimiter) Wait(ctx context.Context, messages []kafka.Message) error {
+	var size int
+	for i := range messages {
+		size += messageSize(&messages[i])
+	}
+
+	if err := waitN(ctx, r.messages, len(messages)); err != nil {
+		return err
+	}
+	return waitN(ctx, r.bytes, size)
+}
+
+func setLimit(limiter *rate.Limiter, perSecond int) {
+	if perSecond == 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetLimit(rate.Limit(perSecond))
+	limiter.SetBurst(perSecond)
+}
+
+func getLimit(limiter *rate.Limiter) int {
+	if limiter.Limit() == rate.Inf {
+		return 0
+	}
+	return int(limiter.Limit())
+}
+
+// waitN waits in burst sized steps, since a single WaitN cannot exceed the burst.
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	if limiter.Limit() == rate.Inf {
+		return nil
+	}
+
+	for n > 0 {
+		step := n
+		if burst := limiter.Burst(); step > burst {
+			step = burst
+		}
+		if err := limiter.WaitN(ctx, step); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
+func messageSize(message *kafka.Message) int {
+	size := len(message.Key) + len(message.Value)
+	for _, header := range message.Headers {
+		size += len(header.Key) + len(header.Value)
+	}
+	return size
+}
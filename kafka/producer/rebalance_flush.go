@@ -0,0 +1,33 @@
+package producer
+
+import (
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func isRebalanceFlush(kafkaConfig *config.Kafka) bool {
+	return kafkaConfig.RebalanceBuffer == config.RebalanceBufferFlush
+}
+
+// flushForRebalancing writes the buffer before the streams stop, so events acknowledged by an automatic checkpoint
+// are not lost with it. Flushes are retried until nothing is pending or the timeout passes, then the rest is discarded.
+func (b *Batch) flushForRebalancing() {
+	deadline := time.Now().Add(b.rebalanceFlushTimeout)
+	for {
+		b.FlushMessages()
+		b.waitFlights()
+
+		pending := b.Pending()
+		if pending == 0 {
+			b.commitSkippedCheckpoint()
+			return
+		}
+		if time.Now().After(deadline) {
+			logger.Log.Error("rebalance flush timed out after %v, %d pending messages are discarded", b.rebalanceFlushTimeout, pending)
+			return
+		}
+		time.Sleep(b.batchTickerDuration)
+	}
+}
@@ -0,0 +1,29 @@
+package producer
+
+import (
+	"sort"
+
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+// logShadowWrite replaces a write in shadow mode with a summary per topic, the metrics are recorded as for a real
+// write so the throughput and the topic routing can be measured before going live.
+func logShadowWrite(messages []kafka.Message) {
+	counts := map[string]int{}
+	sizes := map[string]int{}
+	for i := range messages {
+		counts[messages[i].Topic]++
+		sizes[messages[i].Topic] += messageSize(&messages[i])
+	}
+
+	topics := make([]string, 0, len(counts))
+	for topic := range counts {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	for _, topic := range topics {
+		logger.Log.Info("shadow write skipped, topic: %s, messages: %d, bytes: %d", topic, counts[topic], sizes[topic])
+	}
+}
@@ -0,0 +1,61 @@
+package producer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// Sink receives the messages of an event. The Producer batches them to Kafka, a Recorder keeps them in memory.
+type Sink interface {
+	Produce(ctx *models.ListenerContext, eventTime time.Time, messages []kafka.Message)
+}
+
+// Recorder is an in-memory Sink acknowledging every event, e.g. to unit test a mapper and transforms.
+type Recorder struct {
+	messages []kafka.Message
+	lock     sync.Mutex
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Produce(ctx *models.ListenerContext, _ time.Time, messages []kafka.Message) {
+	r.lock.Lock()
+	r.messages = append(r.messages, messages...)
+	r.lock.Unlock()
+
+	ctx.Ack()
+}
+
+// Messages returns the recorded messages in produce order.
+func (r *Recorder) Messages() []kafka.Message {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]kafka.Message(nil), r.messages...)
+}
+
+// TopicMessages returns the recorded messages of the topic in produce order.
+func (r *Recorder) TopicMessages(topic string) []kafka.Message {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var messages []kafka.Message
+	for i := range r.messages {
+		if r.messages[i].Topic == topic {
+			messages = append(messages, r.messages[i])
+		}
+	}
+	return messages
+}
+
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.messages = nil
+}
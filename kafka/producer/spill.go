@@ -0,0 +1,166 @@
+package producer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+)
+
+const spillFileName = "buffer.spill"
+
+type spilledMessage struct {
+	Time      time.Time      `json:"time"`
+	EventTime time.Time      `json:"eventTime"`
+	Topic     string         `json:"topic"`
+	Key       []byte         `json:"key"`
+	Value     []byte         `json:"value"`
+	Headers   []kafka.Header `json:"headers"`
+}
+
+func (m *spilledMessage) message() kafka.Message {
+	return kafka.Message{Time: m.Time, Topic: m.Topic, Key: m.Key, Value: m.Value, Headers: m.Headers}
+}
+
+// spillQueue is a FIFO of the messages over the buffer limit in a JSON lines file. Every push is synced before the
+// events are acknowledged, so the spilled messages survive a restart and are produced first on the next start. The
+// file keeps the popped messages until it is truncated, count is the messages not popped yet.
+type spillQueue struct {
+	file     *os.File
+	readFile *os.File
+	reader   *bufio.Reader
+	next     *spilledMessage
+	count    int
+	lines    int
+}
+
+func newSpillQueue(directory string) (*spillQueue, error) {
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(directory, spillFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	readFile, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+
+	q := &spillQueue{file: file, readFile: readFile, reader: bufio.NewReader(readFile)}
+	// a partly written last line is from a crash during its push, its events were not acknowledged
+	var complete, partial int64
+	for {
+		var line []byte
+		line, err = q.reader.ReadSlice('\n')
+		partial += int64(len(line))
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			break
+		}
+		complete += partial
+		partial = 0
+		q.lines++
+	}
+	q.count = q.lines
+	if !errors.Is(err, io.EOF) {
+		return nil, errors.Join(err, q.close())
+	}
+	if partial > 0 {
+		if err = file.Truncate(complete); err != nil {
+			return nil, errors.Join(err, q.close())
+		}
+	}
+	if err = q.rewind(); err != nil {
+		return nil, errors.Join(err, q.close())
+	}
+	return q, nil
+}
+
+func (q *spillQueue) push(messages []kafka.Message, eventTime time.Time) error {
+	var data []byte
+	for i := range messages {
+		line, err := jsoniter.Marshal(spilledMessage{
+			Time:      messages[i].Time,
+			EventTime: eventTime,
+			Topic:     messages[i].Topic,
+			Key:       messages[i].Key,
+			Value:     messages[i].Value,
+			Headers:   messages[i].Headers,
+		})
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if _, err := q.file.Write(data); err != nil {
+		return err
+	}
+	q.count += len(messages)
+	q.lines += len(messages)
+	return q.file.Sync()
+}
+
+// peek returns the oldest message without removing it, nil when the queue is empty.
+func (q *spillQueue) peek() (*spilledMessage, error) {
+	if q.next != nil || q.count == 0 {
+		return q.next, nil
+	}
+
+	line, err := q.reader.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var message spilledMessage
+	if err = jsoniter.Unmarshal(line, &message); err != nil {
+		return nil, err
+	}
+	q.next = &message
+	return q.next, nil
+}
+
+func (q *spillQueue) pop() {
+	q.next = nil
+	q.count--
+}
+
+// truncate empties the file once every message is popped and written.
+func (q *spillQueue) truncate() error {
+	if q.count > 0 || q.lines == 0 {
+		return nil
+	}
+	if err := q.file.Truncate(0); err != nil {
+		return err
+	}
+	q.lines = 0
+	return q.rewind()
+}
+
+// rewindAll makes the popped messages pending again, for the ones discarded before they were written.
+func (q *spillQueue) rewindAll() error {
+	q.next = nil
+	q.count = q.lines
+	return q.rewind()
+}
+
+func (q *spillQueue) rewind() error {
+	if _, err := q.readFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	q.reader.Reset(q.readFile)
+	return nil
+}
+
+func (q *spillQueue) close() error {
+	return errors.Join(q.file.Close(), q.readFile.Close())
+}
@@ -0,0 +1,35 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// startStalenessFlush flushes the batch once its oldest message is buffered for maxStaleness, independent of the
+// ticker, so a trickle of events on a quiet bucket is not held for a whole ticker period.
+func (b *Batch) startStalenessFlush(maxStaleness time.Duration) {
+	b.maxStaleness = maxStaleness
+	b.stalenessTimer = time.AfterFunc(maxStaleness, b.flushStale)
+	b.stalenessTimer.Stop()
+}
+
+// bufferedFirst starts the staleness timer for the first message of an empty buffer, the flush lock is held.
+func (b *Batch) bufferedFirst() {
+	if b.stalenessTimer == nil {
+		return
+	}
+	b.oldestBufferedAt = time.Now()
+	b.stalenessTimer.Reset(b.maxStaleness)
+}
+
+// flushStale ignores the timers of buffers already flushed by the ticker or the limits.
+func (b *Batch) flushStale() {
+	b.flushLock.Lock()
+	stale := len(b.messages)+len(b.migrationMessages) > 0 && time.Since(b.oldestBufferedAt) >= b.maxStaleness
+	b.flushLock.Unlock()
+
+	if stale {
+		atomic.AddInt64(&b.metric.StaleFlushes, 1)
+		b.FlushMessages()
+	}
+}
@@ -0,0 +1,84 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/segmentio/kafka-go"
+)
+
+type syncProduce struct {
+	retryInterval time.Duration
+	maxRetries    int
+}
+
+// produceSync writes the messages of an event right away, bypassing the batch, and acks the event once they are written.
+// The batch ticker keeps committing checkpoints and flushing the mirror. In at most once delivery the event is acked
+// and committed before the write instead.
+func (b *Batch) produceSync(ctx *models.ListenerContext, messages []kafka.Message, eventTime time.Time) {
+	b.flushLock.Lock()
+	if b.isDcpRebalancing {
+		b.errorLog.Error("rebalancing", "could not produce message while rebalancing")
+		atomic.AddInt64(&b.metric.RebalanceDroppedMessages, int64(len(messages)))
+		b.flushLock.Unlock()
+		return
+	}
+	if b.atMostOnce {
+		ctx.Ack()
+		b.commitBeforeWrite()
+	}
+
+	var migrationMessages []kafka.Message
+	if b.migration != nil {
+		messages = b.routeMigration(messages, eventTime)
+		migrationMessages, b.migrationMessages = b.migrationMessages, b.migrationMessages[:0]
+		b.migrationEventTimes = b.migrationEventTimes[:0]
+	}
+
+	startedTime := time.Now()
+	for _, group := range b.groupByWriter(b.currentWriter(), messages) {
+		writer := b.currentWriter
+		if topicWriter, ok := b.topicWriters[messages[group.indexes[0]].Topic]; ok && topicWriter == group.writer {
+			writer = func() Writer { return topicWriter }
+		}
+		b.writeSync(writer, collect(messages, group.indexes))
+	}
+	for range messages {
+		b.metric.EndToEndLatency.observe(eventTime)
+	}
+	if len(migrationMessages) > 0 {
+		b.writeSync(func() Writer { return b.migration.writer }, migrationMessages)
+	}
+	b.metric.BatchProduceLatency = time.Since(startedTime).Milliseconds()
+
+	if b.compressionStats != nil {
+		b.compressionStats.record(messages, b.metric)
+	}
+	if b.mirror != nil {
+		b.mirror.add(messages)
+	}
+	b.flushLock.Unlock()
+
+	// in flush ack mode ctx.Ack is wrapped by DeferAck and takes the flush lock itself
+	if !b.atMostOnce {
+		ctx.Ack()
+	}
+}
+
+// writeSync takes the writer on every attempt, since a failover can replace it between retries.
+func (b *Batch) writeSync(writer func() Writer, messages []kafka.Message) {
+	var failingSince time.Time
+	for retry := 0; !b.write(writer(), messages); retry++ {
+		if b.atMostOnce {
+			b.dropUnwritten(len(messages))
+			return
+		}
+		if b.sync.maxRetries > 0 && retry >= b.sync.maxRetries {
+			panic("synchronous produce failed, retries are exhausted")
+		}
+		b.checkFailing(&failingSince, len(messages))
+		time.Sleep(b.sync.retryInterval)
+	}
+	atomic.AddInt64(&b.metric.ProducedMessages, int64(len(messages)))
+}
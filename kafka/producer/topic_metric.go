@@ -0,0 +1,80 @@
+package producer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type TopicMetric struct {
+	Messages int64
+	Bytes    int64
+	Errors   int64
+}
+
+// TopicMetrics counts written messages, bytes and failed messages per destination topic.
+type TopicMetrics struct {
+	topics map[string]*TopicMetric
+	lock   sync.RWMutex
+}
+
+func NewTopicMetrics() *TopicMetrics {
+	return &TopicMetrics{topics: map[string]*TopicMetric{}}
+}
+
+// Snapshot returns a copy of the counters of every topic written so far.
+func (t *TopicMetrics) Snapshot() map[string]TopicMetric {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	snapshot := make(map[string]TopicMetric, len(t.topics))
+	for topic, metric := range t.topics {
+		snapshot[topic] = TopicMetric{
+			Messages: atomic.LoadInt64(&metric.Messages),
+			Bytes:    atomic.LoadInt64(&metric.Bytes),
+			Errors:   atomic.LoadInt64(&metric.Errors),
+		}
+	}
+	return snapshot
+}
+
+func (t *TopicMetrics) get(topic string) *TopicMetric {
+	t.lock.RLock()
+	metric, ok := t.topics[topic]
+	t.lock.RUnlock()
+	if ok {
+		return metric
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if metric, ok = t.topics[topic]; !ok {
+		metric = &TopicMetric{}
+		t.topics[topic] = metric
+	}
+	return metric
+}
+
+func (t *TopicMetrics) recordWritten(messages []kafka.Message) {
+	for i := range messages {
+		metric := t.get(messages[i].Topic)
+		atomic.AddInt64(&metric.Messages, 1)
+		atomic.AddInt64(&metric.Bytes, int64(messageSize(&messages[i])))
+	}
+}
+
+// recordErrors counts only the failed messages when the writer reports errors per message.
+func (t *TopicMetrics) recordErrors(messages []kafka.Message, err error) {
+	var writeErrors kafka.WriteErrors
+	perMessage := errors.As(err, &writeErrors) && len(writeErrors) == len(messages)
+
+	for i := range messages {
+		if perMessage && writeErrors[i] == nil {
+			continue
+		}
+		atomic.AddInt64(&t.get(messages[i].Topic).Errors, 1)
+	}
+}
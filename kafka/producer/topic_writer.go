@@ -0,0 +1,85 @@
+package producer
+
+import (
+	"github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+// newTopicWriters creates a writer per overridden topic, newWriter returns a writer with the global settings.
+func newTopicWriters(
+	newWriter func() *kafka.Writer, overrides map[string]config.TopicOverride, wrap WriterWrapper,
+) map[string]Writer {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	writers := make(map[string]Writer, len(overrides))
+	for topic, override := range overrides {
+		writer := newWriter()
+		if override.RequiredAcks != nil {
+			writer.RequiredAcks = kafka.RequiredAcks(*override.RequiredAcks)
+		}
+		if override.Compression != nil {
+			writer.Compression = kafka.Compression(*override.Compression)
+		}
+		if override.ProducerBatchSize > 0 {
+			writer.BatchSize = override.ProducerBatchSize
+		}
+		if override.ProducerBatchTimeout > 0 {
+			writer.BatchTimeout = override.ProducerBatchTimeout
+		}
+		if override.ReadTimeout > 0 {
+			writer.ReadTimeout = override.ReadTimeout
+		}
+		if override.WriteTimeout > 0 {
+			writer.WriteTimeout = override.WriteTimeout
+		}
+		writers[topic] = wrap.wrap(writer)
+	}
+	return writers
+}
+
+type writerGroup struct {
+	writer  Writer
+	indexes []int
+}
+
+// groupByWriter keeps the order of the messages within a group. Overridden topics use the primary cluster only,
+// so they fall back to the standby writer while failed over.
+func (b *Batch) groupByWriter(writer Writer, messages []kafka.Message) []writerGroup {
+	if len(b.topicWriters) == 0 || (b.failover != nil && b.failover.IsActive()) {
+		indexes := make([]int, len(messages))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return []writerGroup{{writer: writer, indexes: indexes}}
+	}
+
+	var groups []writerGroup
+	positions := map[Writer]int{}
+	for i := range messages {
+		messageWriter, ok := b.topicWriters[messages[i].Topic]
+		if !ok {
+			messageWriter = writer
+		}
+
+		position, ok := positions[messageWriter]
+		if !ok {
+			position = len(groups)
+			positions[messageWriter] = position
+			groups = append(groups, writerGroup{writer: messageWriter})
+		}
+		groups[position].indexes = append(groups[position].indexes, i)
+	}
+	return groups
+}
+
+func (b *Batch) closeTopicWriters() error {
+	for _, writer := range b.topicWriters {
+		if err := writer.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package producer
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Writer writes messages to a Kafka cluster, *kafka.Writer implements it. Writers are compared by identity, so a
+// custom implementation should be a pointer.
+type Writer interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// WriterWrapper wraps every Kafka writer of the producer, e.g. for instrumentation, or replaces it with a test fake.
+type WriterWrapper func(writer Writer) Writer
+
+func (w WriterWrapper) wrap(writer *kafka.Writer) Writer {
+	if w == nil {
+		return writer
+	}
+	return w(writer)
+}
@@ -0,0 +1,137 @@
+package producer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// WriterStat is the kafka.Writer stats of a cluster. The counters are totals since the start, the averages and
+// maximums are the ones of the last interval.
+type WriterStat struct {
+	Writes            int64
+	Messages          int64
+	Bytes             int64
+	Errors            int64
+	Retries           int64
+	BatchSizeAvg      int64
+	BatchBytesAvg     int64
+	BatchTimeAvg      time.Duration
+	BatchQueueTimeAvg time.Duration
+	BatchQueueTimeMax time.Duration
+	WriteTimeAvg      time.Duration
+	WriteTimeMax      time.Duration
+	ThrottleTimeAvg   time.Duration
+}
+
+// WriterStats samples the writers every interval, since kafka.Writer.Stats resets the stats on every call. The
+// writers of a cluster, e.g. the topic override ones, are summed up.
+type WriterStats struct {
+	writers  map[string][]*kafka.Writer
+	stats    map[string]WriterStat
+	stop     chan struct{}
+	interval time.Duration
+	lock     sync.RWMutex
+}
+
+func NewWriterStats(interval time.Duration) *WriterStats {
+	return &WriterStats{
+		writers:  map[string][]*kafka.Writer{},
+		stats:    map[string]WriterStat{},
+		stop:     make(chan struct{}),
+		interval: interval,
+	}
+}
+
+// track is called before Start.
+func (s *WriterStats) track(cluster string, writer *kafka.Writer) {
+	s.writers[cluster] = append(s.writers[cluster], writer)
+}
+
+// Snapshot returns the stats of every cluster.
+func (s *WriterStats) Snapshot() map[string]WriterStat {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	snapshot := make(map[string]WriterStat, len(s.stats))
+	for cluster, stat := range s.stats {
+		snapshot[cluster] = stat
+	}
+	return snapshot
+}
+
+// Start samples every interval until Close.
+func (s *WriterStats) Start() {
+	ticker := time.NewTicker(s.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.sample()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *WriterStats) sample() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for cluster, writers := range s.writers {
+		stat := s.stats[cluster]
+		var batches, batchSize, batchBytes, writes, throttles int64
+		var batchTime, queueTime, writeTime, throttleTime time.Duration
+		stat.BatchQueueTimeMax, stat.WriteTimeMax = 0, 0
+
+		for _, writer := range writers {
+			writerStats := writer.Stats()
+			stat.Writes += writerStats.Writes
+			stat.Messages += writerStats.Messages
+			stat.Bytes += writerStats.Bytes
+			stat.Errors += writerStats.Errors
+			stat.Retries += writerStats.Retries
+
+			batches += writerStats.BatchSize.Count
+			batchSize += writerStats.BatchSize.Sum
+			batchBytes += writerStats.BatchBytes.Sum
+			batchTime += writerStats.BatchTime.Sum
+			queueTime += writerStats.BatchQueueTime.Sum
+			writes += writerStats.WriteTime.Count
+			writeTime += writerStats.WriteTime.Sum
+			throttles += writerStats.WaitTime.Count
+			throttleTime += writerStats.WaitTime.Sum
+			stat.BatchQueueTimeMax = max(stat.BatchQueueTimeMax, writerStats.BatchQueueTime.Max)
+			stat.WriteTimeMax = max(stat.WriteTimeMax, writerStats.WriteTime.Max)
+		}
+
+		stat.BatchSizeAvg, stat.BatchBytesAvg, stat.BatchTimeAvg, stat.BatchQueueTimeAvg = 0, 0, 0, 0
+		if batches > 0 {
+			stat.BatchSizeAvg, stat.BatchBytesAvg = batchSize/batches, batchBytes/batches
+			stat.BatchTimeAvg, stat.BatchQueueTimeAvg = batchTime/time.Duration(batches), queueTime/time.Duration(batches)
+		}
+		stat.WriteTimeAvg, stat.ThrottleTimeAvg = 0, 0
+		if writes > 0 {
+			stat.WriteTimeAvg = writeTime / time.Duration(writes)
+		}
+		if throttles > 0 {
+			stat.ThrottleTimeAvg = throttleTime / time.Duration(throttles)
+		}
+		s.stats[cluster] = stat
+	}
+}
+
+// trackWriter samples the writer as a writer of the cluster when the writer stats are enabled.
+func (b *Batch) trackWriter(cluster string, writer *kafka.Writer) *kafka.Writer {
+	if b.writerStats != nil {
+		b.writerStats.track(cluster, writer)
+	}
+	return writer
+}
+
+func (s *WriterStats) Close() {
+	close(s.stop)
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// newProxyDial connects through a socks5 or http CONNECT proxy URL, the proxy is dialed with forward. The broker host
+// names are resolved by the proxy.
+func newProxyDial(proxyURL string, forward *net.Dialer) (dialFunc, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", u.Host, auth, forward)
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(proxy.ContextDialer).DialContext, nil
+	case "http":
+		return (&httpConnect{proxy: u, forward: forward}).DialContext, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+type httpConnect struct {
+	proxy   *url.URL
+	forward *net.Dialer
+}
+
+func (h *httpConnect) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := h.forward.DialContext(ctx, network, h.proxy.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if h.proxy.User != nil {
+		password, _ := h.proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(h.proxy.User.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err = request.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT %s: %s", address, response.Status)
+	}
+	if reader.Buffered() > 0 {
+		conn.Close()
+		return nil, errors.New("proxy sent data before the broker")
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/secret"
+	"github.com/segmentio/kafka-go"
+)
+
+// rotatingTransport lets the writers keep their transport while the transport behind it is replaced.
+type rotatingTransport struct {
+	current atomic.Pointer[kafka.Transport]
+}
+
+func (t *rotatingTransport) RoundTrip(ctx context.Context, addr net.Addr, request kafka.Request) (kafka.Response, error) {
+	return t.current.Load().RoundTrip(ctx, addr, request)
+}
+
+// RotateCredentials swaps the transport, so new requests connect with the new credentials. Connections of the
+// previous transport are closed once the requests in progress on them, e.g. in-flight batches, are done.
+func (c *client) RotateCredentials() (bool, error) {
+	if !c.config.Kafka.SecureConnection {
+		return false, nil
+	}
+
+	fingerprint, err := credentialsFingerprint(&c.config.Kafka, c.secrets)
+	if err != nil {
+		return false, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if fingerprint == c.fingerprint {
+		return false, nil
+	}
+
+	transport, dialer, err := newTransport(c.config, c.secrets)
+	if err != nil {
+		return false, err
+	}
+
+	previous := c.transport.current.Swap(transport)
+	c.dialer = dialer
+	c.fingerprint = fingerprint
+	previous.CloseIdleConnections()
+	return true, nil
+}
+
+// credentialsFingerprint hashes the resolved secrets and the contents of the certificate files.
+func credentialsFingerprint(kafkaConfig *config.Kafka, secrets secret.Provider) (string, error) {
+	resolved, err := (&credentials{provider: secrets, config: kafkaConfig}).resolve()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, value := range []string{resolved.scramUsername, resolved.scramPassword, resolved.clientKeyPassphrase} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+
+	for _, path := range []string{
+		kafkaConfig.RootCAPath, kafkaConfig.InterCAPath, kafkaConfig.ClientCertPath, kafkaConfig.ClientKeyPath,
+	} {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(os.ExpandEnv(path))
+		if err != nil {
+			return "", err
+		}
+		hash.Write(content)
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
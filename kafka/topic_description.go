@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+)
+
+const (
+	// aclOperationWrite is the bit of the write operation in the authorized operations of a topic.
+	aclOperationWrite = 1 << 4
+	describeTimeout   = 30 * time.Second
+)
+
+// TopicDescription is a topic as seen by the authenticated principal. Err is the metadata error of the topic, e.g.
+// kafka.UnknownTopicOrPartition or kafka.TopicAuthorizationFailed.
+type TopicDescription struct {
+	Err        error
+	Name       string
+	Partitions int
+	Writable   bool
+}
+
+// DescribeTopics requests the metadata of the topics with their authorized operations. Writable is true when the
+// brokers do not report the authorized operations, i.e. before metadata v8 they are 0 and otherwise math.MinInt32.
+func (c *client) DescribeTopics(topics []string) ([]TopicDescription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	conn, err := c.dialBroker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(describeTimeout)); err != nil {
+		return nil, err
+	}
+	response, err := conn.RoundTrip(&metadata.Request{
+		TopicNames:                       topics,
+		IncludeTopicAuthorizedOperations: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadataResponse, ok := response.(*metadata.Response)
+	if !ok {
+		return nil, fmt.Errorf("unexpected metadata response: %T", response)
+	}
+
+	descriptions := make([]TopicDescription, 0, len(metadataResponse.Topics))
+	for _, topic := range metadataResponse.Topics {
+		description := TopicDescription{
+			Name:       topic.Name,
+			Partitions: len(topic.Partitions),
+			Writable:   topic.TopicAuthorizedOperations <= 0 || topic.TopicAuthorizedOperations&aclOperationWrite != 0,
+		}
+		if topic.ErrorCode != 0 {
+			description.Err = kafka.Error(topic.ErrorCode)
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions, nil
+}
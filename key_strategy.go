@@ -0,0 +1,60 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+)
+
+// keyStrategy overrides the key of every mapped message of an event, nil keeps the keys of the mapper.
+type keyStrategy func(e *couchbase.Event) []byte
+
+func newKeyStrategy(keyStrategyConfig config.KeyStrategy) (keyStrategy, error) {
+	switch keyStrategyConfig.Type {
+	case "", config.KeyStrategyID:
+		return nil, nil
+	case config.KeyStrategyNone:
+		return func(_ *couchbase.Event) []byte {
+			return nil
+		}, nil
+	case config.KeyStrategyCollectionID:
+		separator := keyStrategyConfig.Separator
+		return func(e *couchbase.Event) []byte {
+			return []byte(e.CollectionName + separator + string(e.Key))
+		}, nil
+	case config.KeyStrategyField:
+		if keyStrategyConfig.Field == "" {
+			return nil, fmt.Errorf("field must be set for %s key strategy", config.KeyStrategyField)
+		}
+		path := splitFieldPath(keyStrategyConfig.Field)
+		return func(e *couchbase.Event) []byte {
+			return fieldKey(e, path)
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid key strategy: %s", keyStrategyConfig.Type)
+	}
+}
+
+// fieldKey falls back to the document id when the field is missing, e.g. for deletions, so config validation does not
+// allow the field strategy with tombstones.
+func fieldKey(e *couchbase.Event, path []any) []byte {
+	if len(e.Value) == 0 {
+		return e.Key
+	}
+
+	if value, ok := jsonPathValue(e.Value, path); ok {
+		return []byte(value)
+	}
+	return e.Key
+}
+
+func splitFieldPath(field string) []any {
+	parts := strings.Split(field, ".")
+	path := make([]any, 0, len(parts))
+	for _, part := range parts {
+		path = append(path, part)
+	}
+	return path
+}
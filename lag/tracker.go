@@ -0,0 +1,131 @@
+package lag
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// maxVBuckets is the vBucket count limit of Couchbase, so seqnos fit in fixed arrays updated without locks.
+const maxVBuckets = 1024
+
+type VBucket struct {
+	VbID          uint16 `json:"vbId"`
+	ProducedSeqNo uint64 `json:"producedSeqNo"`
+	HighSeqNo     uint64 `json:"highSeqNo"`
+	Lag           uint64 `json:"lag"`
+}
+
+// Tracker compares the seqno of the last produced event of every vBucket to its high seqno. An event counts as
+// produced once a checkpoint commit covers its ack, since commits follow successful flushes.
+// Only vBuckets this member has produced from are reported, the member does not know its vBuckets otherwise.
+type Tracker struct {
+	client     couchbase.Client
+	highSeqNos map[uint16]uint64
+	stop       chan struct{}
+	acked      [maxVBuckets]uint64
+	produced   [maxVBuckets]uint64
+	interval   time.Duration
+	lock       sync.RWMutex
+}
+
+func NewTracker(client couchbase.Client, interval time.Duration) *Tracker {
+	return &Tracker{
+		client:     client,
+		interval:   interval,
+		highSeqNos: map[uint16]uint64{},
+		stop:       make(chan struct{}),
+	}
+}
+
+func (t *Tracker) Ack(vbID uint16, seqNo uint64) {
+	if int(vbID) < maxVBuckets {
+		atomic.StoreUint64(&t.acked[vbID], seqNo)
+	}
+}
+
+// Commit marks every acked seqno as produced, it is called right before the checkpoint commit.
+func (t *Tracker) Commit() {
+	for vbID := range t.acked {
+		if seqNo := atomic.LoadUint64(&t.acked[vbID]); seqNo > 0 {
+			atomic.StoreUint64(&t.produced[vbID], seqNo)
+		}
+	}
+}
+
+// Snapshot returns the lag of every produced vBucket and the produced share of the high seqnos as a percentage.
+func (t *Tracker) Snapshot() ([]VBucket, float64) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var vBuckets []VBucket
+	var produced, high uint64
+	for vbID, highSeqNo := range t.highSeqNos {
+		if int(vbID) >= maxVBuckets {
+			continue
+		}
+		producedSeqNo := atomic.LoadUint64(&t.produced[vbID])
+		if producedSeqNo == 0 {
+			continue
+		}
+
+		vBucket := VBucket{VbID: vbID, ProducedSeqNo: producedSeqNo, HighSeqNo: highSeqNo}
+		if highSeqNo > producedSeqNo {
+			vBucket.Lag = highSeqNo - producedSeqNo
+		} else {
+			producedSeqNo = highSeqNo
+		}
+		vBuckets = append(vBuckets, vBucket)
+
+		produced += producedSeqNo
+		high += highSeqNo
+	}
+
+	sort.Slice(vBuckets, func(i, j int) bool {
+		return vBuckets[i].VbID < vBuckets[j].VbID
+	})
+
+	catchUp := float64(100)
+	if high > 0 {
+		catchUp = float64(produced) / float64(high) * 100
+	}
+	return vBuckets, catchUp
+}
+
+// Start refreshes the high seqnos every interval until Close.
+func (t *Tracker) Start() {
+	ticker := time.NewTicker(t.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			t.refresh()
+			select {
+			case <-ticker.C:
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (t *Tracker) refresh() {
+	highSeqNos, err := t.client.GetVBucketSeqNos()
+	if err != nil {
+		logger.Log.Error("cannot get vbucket seqnos for lag metric, err: %v", err)
+		return
+	}
+
+	t.lock.Lock()
+	t.highSeqNos = highSeqNos
+	t.lock.Unlock()
+}
+
+func (t *Tracker) Close() {
+	close(t.stop)
+	t.client.DcpClose()
+	t.client.Close()
+}
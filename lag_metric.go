@@ -0,0 +1,45 @@
+package dcpkafka
+
+import (
+	"github.com/Trendyol/go-dcp-kafka/lag"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// newLagTracker opens its own connection, go-dcp does not expose the one of the stream.
+func newLagTracker(c *connector, conf *dcpConfig.Dcp) (*lag.Tracker, error) {
+	client := dcpCouchbase.NewClient(conf)
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := client.DcpConnect(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return lag.NewTracker(client, c.config.Kafka.LagMetric.Interval), nil
+}
+
+// trackAck records the seqno of the event when the listener ack runs, which is deferred in flush ack mode.
+func (c *connector) trackAck(ctx *models.ListenerContext) {
+	var vbID uint16
+	var seqNo uint64
+	switch event := ctx.Event.(type) {
+	case models.DcpMutation:
+		vbID, seqNo = event.VbID, event.SeqNo
+	case models.DcpDeletion:
+		vbID, seqNo = event.VbID, event.SeqNo
+	case models.DcpExpiration:
+		vbID, seqNo = event.VbID, event.SeqNo
+	default:
+		return
+	}
+
+	ack := ctx.Ack
+	ctx.Ack = func() {
+		ack()
+		c.lag.Ack(vbID, seqNo)
+	}
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+const (
+	FieldTopic   = "topic"
+	FieldVbID    = "vbId"
+	FieldSeqNo   = "seqNo"
+	FieldKey     = "key"
+	FieldError   = "error"
+	FieldCluster = "cluster"
+)
+
+type Field struct {
+	Value any
+	Key   string
+}
+
+func Topic(topic string) Field {
+	return Field{Key: FieldTopic, Value: topic}
+}
+
+func VbID(vbID uint16) Field {
+	return Field{Key: FieldVbID, Value: vbID}
+}
+
+func SeqNo(seqNo uint64) Field {
+	return Field{Key: FieldSeqNo, Value: seqNo}
+}
+
+func Key(key []byte) Field {
+	return Field{Key: FieldKey, Value: string(key)}
+}
+
+func Err(err error) Field {
+	return Field{Key: FieldError, Value: err}
+}
+
+// FieldLogger is implemented by the structured adapters, fields are attached to the record instead of the message.
+type FieldLogger interface {
+	logger.Logger
+	LogFields(level string, fields []Field, message string, args ...any)
+}
+
+// Log writes the fields as attributes when the connector logger is structured, otherwise they are appended to the
+// message as key=value pairs, so the default logrus logger keeps working.
+func Log(level string, fields []Field, message string, args ...any) {
+	if fieldLogger, ok := logger.Log.(FieldLogger); ok {
+		fieldLogger.LogFields(level, fields, message, args...)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(message, args...))
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf(", %s: %v", field.Key, field.Value))
+	}
+	logger.Log.Log(level, "%s", sb.String())
+}
+
+func Error(fields []Field, message string, args ...any) {
+	Log(logger.ERROR, fields, message, args...)
+}
+
+func Info(fields []Field, message string, args ...any) {
+	Log(logger.INFO, fields, message, args...)
+}
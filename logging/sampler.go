@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type sample struct {
+	windowStart time.Time
+	lastMessage string
+	logged      int
+	suppressed  int
+}
+
+// Sampler logs the first burst errors of a key per interval and suppresses the rest, a summary with the suppressed
+// count and the last message is logged when the interval ends. A nil Sampler logs every error.
+type Sampler struct {
+	samples  map[string]*sample
+	interval time.Duration
+	burst    int
+	lock     sync.Mutex
+}
+
+func NewSampler(interval time.Duration, burst int) *Sampler {
+	return &Sampler{
+		samples:  map[string]*sample{},
+		interval: interval,
+		burst:    burst,
+	}
+}
+
+func (s *Sampler) Error(key string, message string, args ...any) {
+	if s == nil {
+		logger.Log.Error(message, args...)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, ok := s.samples[key]
+	if !ok || (current.suppressed == 0 && time.Since(current.windowStart) >= s.interval) {
+		current = &sample{windowStart: time.Now()}
+		s.samples[key] = current
+	}
+
+	if current.logged < s.burst {
+		current.logged++
+		logger.Log.Error(message, args...)
+		return
+	}
+
+	if current.suppressed == 0 {
+		time.AfterFunc(time.Until(current.windowStart.Add(s.interval)), func() {
+			s.summarize(key)
+		})
+	}
+	current.suppressed++
+	current.lastMessage = fmt.Sprintf(message, args...)
+}
+
+func (s *Sampler) summarize(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, ok := s.samples[key]
+	if !ok {
+		return
+	}
+	delete(s.samples, key)
+
+	logger.Log.Error("%d more errors in last %v, last: %s", current.suppressed, s.interval, current.lastMessage)
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// LevelTrace is below slog.LevelDebug, slog has no trace level.
+const LevelTrace = slog.LevelDebug - 4
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger adapts a slog logger to the go-dcp logger interface used by the connector and go-dcp.
+func NewSlogLogger(l *slog.Logger) FieldLogger {
+	return &slogLogger{logger: l}
+}
+
+func (l *slogLogger) Trace(message string, args ...any) {
+	l.Log(logger.TRACE, message, args...)
+}
+
+func (l *slogLogger) Debug(message string, args ...any) {
+	l.Log(logger.DEBUG, message, args...)
+}
+
+func (l *slogLogger) Info(message string, args ...any) {
+	l.Log(logger.INFO, message, args...)
+}
+
+func (l *slogLogger) Warn(message string, args ...any) {
+	l.Log(logger.WARN, message, args...)
+}
+
+func (l *slogLogger) Error(message string, args ...any) {
+	l.Log(logger.ERROR, message, args...)
+}
+
+func (l *slogLogger) Log(level string, message string, args ...any) {
+	l.LogFields(level, nil, message, args...)
+}
+
+func (l *slogLogger) LogFields(level string, fields []Field, message string, args ...any) {
+	slogLevel := slogLevelOf(level)
+	if !l.logger.Enabled(context.Background(), slogLevel) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	l.logger.LogAttrs(context.Background(), slogLevel, fmt.Sprintf(message, args...), attrs...)
+}
+
+func slogLevelOf(level string) slog.Level {
+	switch level {
+	case logger.ERROR:
+		return slog.LevelError
+	case logger.WARN:
+		return slog.LevelWarn
+	case logger.DEBUG:
+		return slog.LevelDebug
+	case logger.TRACE:
+		return LevelTrace
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+// NewZapLogger adapts a zap logger to the go-dcp logger interface, trace logs are written at debug level.
+func NewZapLogger(l *zap.Logger) FieldLogger {
+	return &zapLogger{logger: l}
+}
+
+func (l *zapLogger) Trace(message string, args ...any) {
+	l.Log(logger.TRACE, message, args...)
+}
+
+func (l *zapLogger) Debug(message string, args ...any) {
+	l.Log(logger.DEBUG, message, args...)
+}
+
+func (l *zapLogger) Info(message string, args ...any) {
+	l.Log(logger.INFO, message, args...)
+}
+
+func (l *zapLogger) Warn(message string, args ...any) {
+	l.Log(logger.WARN, message, args...)
+}
+
+func (l *zapLogger) Error(message string, args ...any) {
+	l.Log(logger.ERROR, message, args...)
+}
+
+func (l *zapLogger) Log(level string, message string, args ...any) {
+	l.LogFields(level, nil, message, args...)
+}
+
+func (l *zapLogger) LogFields(level string, fields []Field, message string, args ...any) {
+	entry := l.logger.Check(zapLevelOf(level), fmt.Sprintf(message, args...))
+	if entry == nil {
+		return
+	}
+
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
+	entry.Write(zapFields...)
+}
+
+func zapLevelOf(level string) zapcore.Level {
+	switch level {
+	case logger.ERROR:
+		return zapcore.ErrorLevel
+	case logger.WARN:
+		return zapcore.WarnLevel
+	case logger.DEBUG, logger.TRACE:
+		return zapcore.DebugLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
@@ -1,8 +1,13 @@
 package dcpkafka
 
 import (
+	"fmt"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
 	"github.com/Trendyol/go-dcp-kafka/couchbase"
 	"github.com/Trendyol/go-dcp-kafka/kafka/message"
+	"github.com/Trendyol/go-dcp-kafka/mapping"
+	"github.com/Trendyol/go-dcp/models"
 )
 
 type Mapper func(event couchbase.Event) []message.KafkaMessage
@@ -18,3 +23,29 @@ func DefaultMapper(event couchbase.Event) []message.KafkaMessage {
 		},
 	}
 }
+
+// loadConfigMapper replaces the mapper of the builder with the kafka.mapper one.
+func (c *connector) loadConfigMapper(mapperConfig config.Mapper) error {
+	configMapper, err := mapping.New(mapperConfig)
+	if err != nil {
+		return err
+	}
+
+	c.configMapper = configMapper
+	return nil
+}
+
+// mapEvent returns false when the kafka.mapper one cannot map the event, it follows kafka.processingErrorPolicy like
+// a transform error.
+func (c *connector) mapEvent(ctx *models.ListenerContext, e *couchbase.Event) ([]message.KafkaMessage, bool) {
+	if c.configMapper == nil {
+		return c.mapper(*e), true
+	}
+
+	messages, err := c.configMapper.Map(*e)
+	if err != nil {
+		c.rejectEvent(ctx, e, fmt.Errorf("%s mapper error: %w", c.config.Kafka.Mapper.Type, err))
+		return nil, false
+	}
+	return messages, true
+}
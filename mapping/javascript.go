@@ -0,0 +1,182 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+	"github.com/Trendyol/go-dcp-kafka/kafka/message"
+)
+
+type scriptMessage struct {
+	Value   any               `json:"value"`
+	Headers map[string]string `json:"headers"`
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key"`
+}
+
+// JavaScript runs the map(event) function of a script. The event has key, value(decoded JSON document), rawValue,
+// scope, collection, eventType, cas, seqNo, revNo, expiry, flags, vbId and eventTime in Unix milliseconds. The
+// function returns an array of {topic, key, value, headers} messages, string values are produced as is and other
+// values as JSON. Calls are serialized, since a runtime is single threaded, and interrupted after the timeout.
+//
+// A JavaScript number holds integers up to 2^53 exactly, cas and the bigger integers of the value are decimal strings.
+type JavaScript struct {
+	runtime *goja.Runtime
+	mapFn   goja.Callable
+	timeout time.Duration
+	lock    sync.Mutex
+}
+
+func NewJavaScript(path string, timeout time.Duration) (*JavaScript, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := goja.New()
+	runtime.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+	if _, err = runtime.RunScript(path, string(source)); err != nil {
+		return nil, fmt.Errorf("javascript mapper %s: %w", path, err)
+	}
+
+	mapFn, ok := goja.AssertFunction(runtime.Get("map"))
+	if !ok {
+		return nil, fmt.Errorf("javascript mapper %s must define a map(event) function", path)
+	}
+	return &JavaScript{runtime: runtime, mapFn: mapFn, timeout: timeout}, nil
+}
+
+func (j *JavaScript) Map(event couchbase.Event) ([]message.KafkaMessage, error) {
+	env := map[string]any{
+		"key":        string(event.Key),
+		"rawValue":   string(event.Value),
+		"scope":      event.ScopeName,
+		"collection": event.CollectionName,
+		"eventType":  event.EventType(),
+		"cas":        strconv.FormatUint(event.Cas, 10),
+		"seqNo":      event.SeqNo,
+		"revNo":      event.RevNo,
+		"expiry":     event.Expiry,
+		"flags":      event.Flags,
+		"vbId":       event.VbID,
+		"eventTime":  event.EventTime.UnixMilli(),
+	}
+
+	if len(event.Value) > 0 {
+		if value, err := document.Decode(event.Value); err == nil {
+			env["value"] = safeIntegers(value)
+		}
+	}
+
+	scriptMessages, err := j.call(env)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]message.KafkaMessage, len(scriptMessages))
+	for i, m := range scriptMessages {
+		messages[i] = message.KafkaMessage{Topic: m.Topic, Key: []byte(m.Key)}
+		if messages[i].Value, err = scriptValue(m.Value); err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(m.Headers) {
+			messages[i].Headers = append(messages[i].Headers, kafka.Header{Key: key, Value: []byte(m.Headers[key])})
+		}
+	}
+	return messages, nil
+}
+
+func (j *JavaScript) call(env map[string]any) ([]scriptMessage, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	result, err := j.run(env)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(result) || goja.IsNull(result) {
+		return nil, nil
+	}
+
+	var messages []scriptMessage
+	if err = j.runtime.ExportTo(result, &messages); err != nil {
+		return nil, fmt.Errorf("map must return an array of messages: %w", err)
+	}
+	return messages, nil
+}
+
+// run interrupts a map call running longer than the timeout, e.g. an endless loop, so it fails instead of holding
+// the stream.
+func (j *JavaScript) run(env map[string]any) (goja.Value, error) {
+	if j.timeout <= 0 {
+		return j.mapFn(goja.Undefined(), j.runtime.ToValue(env))
+	}
+
+	interrupted := make(chan struct{})
+	timer := time.AfterFunc(j.timeout, func() {
+		j.runtime.Interrupt(fmt.Errorf("map exceeded the timeout of %s", j.timeout))
+		close(interrupted)
+	})
+	result, err := j.mapFn(goja.Undefined(), j.runtime.ToValue(env))
+	if !timer.Stop() {
+		<-interrupted
+	}
+	j.runtime.ClearInterrupt()
+	return result, err
+}
+
+// maxSafeInteger is Number.MAX_SAFE_INTEGER, goja turns the integers above it into floats.
+const maxSafeInteger = 1<<53 - 1
+
+func safeIntegers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = safeIntegers(field)
+		}
+	case []any:
+		for i := range v {
+			v[i] = safeIntegers(v[i])
+		}
+	case int64:
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			return strconv.FormatInt(v, 10)
+		}
+	case json.Number:
+		return v.String()
+	}
+	return value
+}
+
+func scriptValue(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return jsoniter.Marshal(v)
+	}
+}
+
+func sortedKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (j *JavaScript) Close() {}
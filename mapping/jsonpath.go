@@ -0,0 +1,179 @@
+package mapping
+
+import (
+	"fmt"
+	"sort"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ohler55/ojg/jp"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/document"
+	"github.com/Trendyol/go-dcp-kafka/kafka/message"
+)
+
+// sortedJSON sorts the object keys, so a projection is always produced the same way.
+var sortedJSON = jsoniter.ConfigCompatibleWithStandardLibrary
+
+type path struct {
+	expr     jp.Expr
+	name     string
+	definite bool
+}
+
+// JSONPath projects the mutated documents with JSONPath expressions, deletions and expirations are not mapped like
+// the DefaultMapper. A definite path selects the first match and is left out without one, other paths select an
+// array of every match. The key defaults to the document id, the topic to kafka.collectionTopicMapping and the value
+// to the whole document. Strings are produced as is, other key, topic and header values as JSON.
+type JSONPath struct {
+	key     *path
+	topic   *path
+	value   []path
+	headers []path
+}
+
+func NewJSONPath(mapperConfig config.Mapper) (*JSONPath, error) {
+	j := &JSONPath{}
+
+	var err error
+	if j.key, err = parsePath("key", mapperConfig.Key); err != nil {
+		return nil, err
+	}
+	if j.topic, err = parsePath("topic", mapperConfig.Topic); err != nil {
+		return nil, err
+	}
+	if j.value, err = parsePaths(mapperConfig.Value); err != nil {
+		return nil, err
+	}
+	if j.headers, err = parsePaths(mapperConfig.Headers); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func parsePath(name string, expression string) (*path, error) {
+	if expression == "" {
+		return nil, nil
+	}
+
+	expr, err := jp.ParseString(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s jsonpath %q: %w", name, expression, err)
+	}
+	return &path{expr: expr, name: name, definite: isDefinite(expr)}, nil
+}
+
+// parsePaths sorts the paths by name, so the headers are produced in a stable order.
+func parsePaths(expressions map[string]string) ([]path, error) {
+	paths := make([]path, 0, len(expressions))
+	for name, expression := range expressions {
+		p, err := parsePath(name, expression)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			paths = append(paths, *p)
+		}
+	}
+	sort.Slice(paths, func(i, k int) bool { return paths[i].name < paths[k].name })
+	return paths, nil
+}
+
+func isDefinite(expr jp.Expr) bool {
+	for _, frag := range expr {
+		switch frag.(type) {
+		case jp.Root, jp.At, jp.Child, jp.Nth, jp.Bracket:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// get returns false when a definite path has no match.
+func (p *path) get(document any) (any, bool) {
+	matches := p.expr.Get(document)
+	if !p.definite {
+		if matches == nil {
+			matches = []any{}
+		}
+		return matches, true
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
+
+func (j *JSONPath) Map(event couchbase.Event) ([]message.KafkaMessage, error) {
+	if event.IsExpired || event.IsDeleted {
+		return nil, nil
+	}
+
+	// the integers are kept exact for keys, topics and projections
+	decoded, err := document.Decode(event.Value)
+	if err != nil {
+		return nil, fmt.Errorf("document is not JSON: %w", err)
+	}
+
+	kafkaMessage := message.KafkaMessage{Key: event.Key, Value: event.Value}
+
+	if j.key != nil {
+		if kafkaMessage.Key, err = j.key.bytes(decoded); err != nil {
+			return nil, err
+		}
+	}
+	if j.topic != nil {
+		var topic []byte
+		if topic, err = j.topic.bytes(decoded); err != nil {
+			return nil, err
+		}
+		kafkaMessage.Topic = string(topic)
+	}
+
+	if len(j.value) > 0 {
+		value := make(map[string]any, len(j.value))
+		for i := range j.value {
+			if match, ok := j.value[i].get(decoded); ok {
+				value[j.value[i].name] = match
+			}
+		}
+		if kafkaMessage.Value, err = sortedJSON.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range j.headers {
+		match, ok := j.headers[i].get(decoded)
+		if !ok {
+			continue
+		}
+		header, err := encode(match)
+		if err != nil {
+			return nil, err
+		}
+		kafkaMessage.Headers = append(kafkaMessage.Headers, kafka.Header{Key: j.headers[i].name, Value: header})
+	}
+
+	return []message.KafkaMessage{kafkaMessage}, nil
+}
+
+// bytes returns nil when a definite path has no match.
+func (p *path) bytes(document any) ([]byte, error) {
+	match, ok := p.get(document)
+	if !ok {
+		return nil, nil
+	}
+	return encode(match)
+}
+
+func encode(match any) ([]byte, error) {
+	if s, ok := match.(string); ok {
+		return []byte(s), nil
+	}
+	return sortedJSON.Marshal(match)
+}
+
+func (j *JSONPath) Close() {}
@@ -0,0 +1,29 @@
+// Package mapping loads mappers defined outside the connector binary from the kafka.mapper config.
+package mapping
+
+import (
+	"fmt"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/kafka/message"
+)
+
+// Mapper maps an event to the messages to produce, an error means the event cannot be mapped.
+type Mapper interface {
+	Map(event couchbase.Event) ([]message.KafkaMessage, error)
+	Close()
+}
+
+func New(mapperConfig config.Mapper) (Mapper, error) {
+	switch mapperConfig.Type {
+	case config.MapperTypeWASM:
+		return NewWASM(mapperConfig.Path)
+	case config.MapperTypeJavaScript:
+		return NewJavaScript(mapperConfig.Path, mapperConfig.Timeout)
+	case config.MapperTypeJSONPath:
+		return NewJSONPath(mapperConfig)
+	default:
+		return nil, fmt.Errorf("invalid mapper type: %s", mapperConfig.Type)
+	}
+}
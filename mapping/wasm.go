@@ -0,0 +1,188 @@
+package mapping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/kafka/message"
+)
+
+// WASMEvent is the JSON the host passes to the map function of a WASM mapper, bytes are base64 encoded.
+type WASMEvent struct {
+	EventTime  time.Time `json:"eventTime"`
+	Scope      string    `json:"scope"`
+	Collection string    `json:"collection"`
+	EventType  string    `json:"eventType"`
+	Key        []byte    `json:"key"`
+	Value      []byte    `json:"value"`
+	Cas        uint64    `json:"cas"`
+	SeqNo      uint64    `json:"seqNo"`
+	RevNo      uint64    `json:"revNo"`
+	Expiry     uint32    `json:"expiry"`
+	Flags      uint32    `json:"flags"`
+	VbID       uint16    `json:"vbId"`
+}
+
+// WASMMessage is an element of the JSON array the map function of a WASM mapper returns, bytes are base64 encoded.
+type WASMMessage struct {
+	Topic   string       `json:"topic"`
+	Headers []WASMHeader `json:"headers"`
+	Key     []byte       `json:"key"`
+	Value   []byte       `json:"value"`
+}
+
+type WASMHeader struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// WASM runs the map function of a WebAssembly module. The module exports its memory and
+//
+//	alloc(size i32) i32          returns a buffer of size bytes for the event
+//	map(ptr i32, len i32) i64    maps the WASMEvent JSON in the buffer, returns ptr<<32|len of the WASMMessage array
+//	free(ptr i32, len i32)       optional, releases the event and the result buffers
+//
+// WASI modules are supported, _initialize is called for reactor modules. A trap fails the event. Calls are
+// serialized, since a module instance is single threaded.
+type WASM struct {
+	runtime wazero.Runtime
+	module  api.Module
+	alloc   api.Function
+	mapFn   api.Function
+	free    api.Function
+	lock    sync.Mutex
+}
+
+func NewWASM(path string) (*WASM, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	w := &WASM{runtime: wazero.NewRuntime(ctx)}
+	if err = w.instantiate(ctx, binary); err != nil {
+		_ = w.runtime.Close(ctx)
+		return nil, fmt.Errorf("wasm mapper %s: %w", path, err)
+	}
+	return w, nil
+}
+
+func (w *WASM) instantiate(ctx context.Context, binary []byte) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, w.runtime); err != nil {
+		return err
+	}
+
+	compiled, err := w.runtime.CompileModule(ctx, binary)
+	if err != nil {
+		return err
+	}
+
+	w.module, err = w.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().
+		WithStartFunctions("_initialize").
+		WithStdout(os.Stdout).
+		WithStderr(os.Stderr))
+	if err != nil {
+		return err
+	}
+
+	w.alloc, w.mapFn, w.free = w.module.ExportedFunction("alloc"), w.module.ExportedFunction("map"), w.module.ExportedFunction("free")
+	if w.alloc == nil || w.mapFn == nil || w.module.Memory() == nil {
+		return errors.New("module must export memory, alloc and map")
+	}
+	return nil
+}
+
+func (w *WASM) Map(event couchbase.Event) ([]message.KafkaMessage, error) {
+	input, err := jsoniter.Marshal(WASMEvent{
+		EventTime:  event.EventTime,
+		Scope:      event.ScopeName,
+		Collection: event.CollectionName,
+		EventType:  event.EventType(),
+		Key:        event.Key,
+		Value:      event.Value,
+		Cas:        event.Cas,
+		SeqNo:      event.SeqNo,
+		RevNo:      event.RevNo,
+		Expiry:     event.Expiry,
+		Flags:      event.Flags,
+		VbID:       event.VbID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := w.call(input)
+	if err != nil || len(output) == 0 {
+		return nil, err
+	}
+
+	var wasmMessages []WASMMessage
+	if err = jsoniter.Unmarshal(output, &wasmMessages); err != nil {
+		return nil, fmt.Errorf("invalid wasm mapper output: %w", err)
+	}
+
+	messages := make([]message.KafkaMessage, len(wasmMessages))
+	for i, m := range wasmMessages {
+		messages[i] = message.KafkaMessage{Topic: m.Topic, Key: m.Key, Value: m.Value}
+		for _, header := range m.Headers {
+			messages[i].Headers = append(messages[i].Headers, kafka.Header{Key: header.Key, Value: header.Value})
+		}
+	}
+	return messages, nil
+}
+
+// call returns a copy of the output, since the module memory may grow on the next call.
+func (w *WASM) call(input []byte) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ctx := context.Background()
+	allocated, err := w.alloc.Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	ptr := uint32(allocated[0])
+	if !w.module.Memory().Write(ptr, input) {
+		return nil, fmt.Errorf("alloc returned %d out of the memory", ptr)
+	}
+
+	result, err := w.mapFn.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	w.release(ctx, ptr, uint32(len(input)))
+
+	outputPtr, outputLen := uint32(result[0]>>32), uint32(result[0])
+	if outputLen == 0 {
+		return nil, nil
+	}
+	output, ok := w.module.Memory().Read(outputPtr, outputLen)
+	if !ok {
+		return nil, fmt.Errorf("map returned %d bytes at %d out of the memory", outputLen, outputPtr)
+	}
+	output = append([]byte(nil), output...)
+	w.release(ctx, outputPtr, outputLen)
+	return output, nil
+}
+
+func (w *WASM) release(ctx context.Context, ptr uint32, size uint32) {
+	if w.free != nil {
+		_, _ = w.free.Call(ctx, uint64(ptr), uint64(size))
+	}
+}
+
+func (w *WASM) Close() {
+	_ = w.runtime.Close(context.Background())
+}
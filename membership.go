@@ -0,0 +1,46 @@
+package dcpkafka
+
+import (
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/pause"
+)
+
+// Membership describes the group membership of the connector. MemberNumber and TotalMembers are the configured
+// values, since go-dcp does not expose the ones assigned at runtime by dynamic membership types.
+type Membership struct {
+	LastRebalance time.Time `json:"lastRebalance"`
+	Type          string    `json:"type"`
+	GroupName     string    `json:"groupName"`
+	MemberNumber  int       `json:"memberNumber"`
+	TotalMembers  int       `json:"totalMembers"`
+	Rebalancing   bool      `json:"rebalancing"`
+	Streaming     bool      `json:"streaming"`
+}
+
+func (c *connector) Membership() Membership {
+	group := c.dcp.GetConfig().Dcp.Group
+	rebalancing, streaming, lastRebalance := c.eventHandler.state()
+
+	return Membership{
+		Type:          group.Membership.Type,
+		GroupName:     group.Name,
+		MemberNumber:  group.Membership.MemberNumber,
+		TotalMembers:  group.Membership.TotalMembers,
+		Rebalancing:   rebalancing,
+		Streaming:     streaming,
+		LastRebalance: lastRebalance,
+	}
+}
+
+func (c *connector) Pause(s pause.State) error {
+	return c.pauser.Pause(s)
+}
+
+func (c *connector) Resume(s pause.State) error {
+	return c.pauser.Resume(s)
+}
+
+func (c *connector) PauseState() pause.State {
+	return c.pauser.State()
+}
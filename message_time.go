@@ -0,0 +1,57 @@
+package dcpkafka
+
+import (
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+)
+
+// TimestampExtractor derives the timestamp of the records of an event, e.g. from a business time field of the
+// document. The zero time falls back to kafka.messageTimestamp.
+type TimestampExtractor func(event couchbase.Event) time.Time
+
+// FieldTimestamp extracts the timestamp from a dot separated JSON field of mutated documents, either an RFC 3339
+// string or the milliseconds since the epoch.
+func FieldTimestamp(field string) TimestampExtractor {
+	path := splitFieldPath(field)
+	return func(event couchbase.Event) time.Time {
+		if !event.IsMutated {
+			return time.Time{}
+		}
+
+		value := jsoniter.Get(event.Value, path...)
+		switch value.ValueType() { //nolint:exhaustive
+		case jsoniter.StringValue:
+			if t, err := time.Parse(time.RFC3339Nano, value.ToString()); err == nil {
+				return t
+			}
+		case jsoniter.NumberValue:
+			if millis, err := strconv.ParseInt(value.ToString(), 10, 64); err == nil {
+				return time.UnixMilli(millis)
+			}
+		}
+		return time.Time{}
+	}
+}
+
+// messageTime is the time of the TimestampExtractor, or the mutation time for kafka.messageTimestamp event from the
+// CAS which holds the nanoseconds since the epoch. The zero time is replaced with the produce time by the writer.
+func (c *connector) messageTime(e *couchbase.Event) time.Time {
+	if c.timestampOf != nil {
+		if t := c.timestampOf(*e); !t.IsZero() {
+			return t
+		}
+	}
+
+	if c.config.Kafka.MessageTimestamp != config.MessageTimestampEvent {
+		return time.Time{}
+	}
+	if e.Cas == 0 {
+		return e.EventTime
+	}
+	return time.Unix(0, int64(e.Cas))
+}
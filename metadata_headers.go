@@ -0,0 +1,32 @@
+package dcpkafka
+
+import (
+	"strconv"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderCas        = "cb.cas"
+	HeaderSeqNo      = "cb.seqno"
+	HeaderVbID       = "cb.vbucket"
+	HeaderRevNo      = "cb.rev"
+	HeaderExpiry     = "cb.expiry"
+	HeaderEventType  = "cb.eventType"
+	HeaderCollection = "cb.collection"
+	HeaderScope      = "cb.scope"
+)
+
+func newDcpMetadataHeaders(e *couchbase.Event) []sKafka.Header {
+	return []sKafka.Header{
+		{Key: HeaderCas, Value: []byte(strconv.FormatUint(e.Cas, 10))},
+		{Key: HeaderSeqNo, Value: []byte(strconv.FormatUint(e.SeqNo, 10))},
+		{Key: HeaderVbID, Value: []byte(strconv.FormatUint(uint64(e.VbID), 10))},
+		{Key: HeaderRevNo, Value: []byte(strconv.FormatUint(e.RevNo, 10))},
+		{Key: HeaderExpiry, Value: []byte(strconv.FormatUint(uint64(e.Expiry), 10))},
+		{Key: HeaderEventType, Value: []byte(e.EventType())},
+		{Key: HeaderCollection, Value: []byte(e.CollectionName)},
+		{Key: HeaderScope, Value: []byte(e.ScopeName)},
+	}
+}
@@ -1,16 +1,86 @@
 package metric
 
 import (
+	"strconv"
+	"sync/atomic"
+
 	"github.com/Trendyol/go-dcp-kafka/kafka/producer"
+	"github.com/Trendyol/go-dcp-kafka/lag"
+	"github.com/Trendyol/go-dcp-kafka/pause"
 	"github.com/Trendyol/go-dcp/helpers"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Collector struct {
 	producer producer.Producer
+	lag      *lag.Tracker
+	pauser   *pause.Pauser
 
-	kafkaConnectorLatency *prometheus.Desc
-	batchProduceLatency   *prometheus.Desc
+	endToEndLatency          *prometheus.Desc
+	batchProduceLatency      *prometheus.Desc
+	jsonComplexityExceeded   *prometheus.Desc
+	oversizedDocuments       *prometheus.Desc
+	processingErrors         *prometheus.Desc
+	pauseHeldEvents          *prometheus.Desc
+	pauseBackPressures       *prometheus.Desc
+	migrationRouted          *prometheus.Desc
+	enrichmentErrors         *prometheus.Desc
+	xattrErrors              *prometheus.Desc
+	latencyBudgetExceeded    *prometheus.Desc
+	latencyBudgetShed        *prometheus.Desc
+	staleFlushes             *prometheus.Desc
+	bufferOverflows          *prometheus.Desc
+	bufferDroppedMessages    *prometheus.Desc
+	spilledMessages          *prometheus.Desc
+	outageQueuedMessages     *prometheus.Desc
+	outageQueueBatches       *prometheus.Desc
+	rebalances               *prometheus.Desc
+	rebalanceDuration        *prometheus.Desc
+	rebalanceHeldMessages    *prometheus.Desc
+	rebalanceDroppedMessages *prometheus.Desc
+	batchLimit               *prometheus.Desc
+	batchTickerDuration      *prometheus.Desc
+	atMostOnceDropped        *prometheus.Desc
+	filteredEvents           *prometheus.Desc
+	skippedBinaryDocuments   *prometheus.Desc
+	sampledOutEvents         *prometheus.Desc
+	disabledCollectionEvents *prometheus.Desc
+	rollbacksDetected        *prometheus.Desc
+	schemaRegistryFallbacks  *prometheus.Desc
+	dedupSuppressed          *prometheus.Desc
+	seqNoDedupSkipped        *prometheus.Desc
+	chunkedMessages          *prometheus.Desc
+	compressedValues         *prometheus.Desc
+	encryptedValues          *prometheus.Desc
+	claimCheckedMessages     *prometheus.Desc
+	producedBytes            *prometheus.Desc
+	estimatedWireBytes       *prometheus.Desc
+	compressionRatio         *prometheus.Desc
+	produceErrors            *prometheus.Desc
+	topicProducedMessages    *prometheus.Desc
+	topicProducedBytes       *prometheus.Desc
+	topicProduceErrors       *prometheus.Desc
+	mirrorProduced           *prometheus.Desc
+	mirrorDeadLettered       *prometheus.Desc
+	chaosInjected            *prometheus.Desc
+	failovers                *prometheus.Desc
+	failoverActive           *prometheus.Desc
+	shadowMode               *prometheus.Desc
+	vBucketLag               *prometheus.Desc
+	writerWrites             *prometheus.Desc
+	writerMessages           *prometheus.Desc
+	writerBytes              *prometheus.Desc
+	writerErrors             *prometheus.Desc
+	writerRetries            *prometheus.Desc
+	writerBatchSize          *prometheus.Desc
+	writerBatchBytes         *prometheus.Desc
+	writerBatchTime          *prometheus.Desc
+	writerBatchQueueTime     *prometheus.Desc
+	writerBatchQueueTimeMax  *prometheus.Desc
+	writerWriteTime          *prometheus.Desc
+	writerWriteTimeMax       *prometheus.Desc
+	writerThrottleTime       *prometheus.Desc
+	catchUpPercentage        *prometheus.Desc
 }
 
 func (s *Collector) Describe(ch chan<- *prometheus.Desc) {
@@ -20,10 +90,12 @@ func (s *Collector) Describe(ch chan<- *prometheus.Desc) {
 func (s *Collector) Collect(ch chan<- prometheus.Metric) {
 	producerMetric := s.producer.GetMetric()
 
-	ch <- prometheus.MustNewConstMetric(
-		s.kafkaConnectorLatency,
-		prometheus.GaugeValue,
-		float64(producerMetric.KafkaConnectorLatency),
+	count, sum, buckets := producerMetric.EndToEndLatency.Snapshot()
+	ch <- prometheus.MustNewConstHistogram(
+		s.endToEndLatency,
+		count,
+		sum,
+		buckets,
 		[]string{}...,
 	)
 
@@ -33,24 +105,756 @@ func (s *Collector) Collect(ch chan<- prometheus.Metric) {
 		float64(producerMetric.BatchProduceLatency),
 		[]string{}...,
 	)
+
+	s.collectBatchMetrics(ch, producerMetric)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.produceErrors,
+		prometheus.CounterValue,
+		float64(atomic.LoadInt64(&producerMetric.ProduceErrors)),
+		"primary",
+	)
+
+	for topic, topicMetric := range producerMetric.Topics.Snapshot() {
+		ch <- prometheus.MustNewConstMetric(
+			s.topicProducedMessages,
+			prometheus.CounterValue,
+			float64(topicMetric.Messages),
+			topic,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.topicProducedBytes,
+			prometheus.CounterValue,
+			float64(topicMetric.Bytes),
+			topic,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.topicProduceErrors,
+			prometheus.CounterValue,
+			float64(topicMetric.Errors),
+			topic,
+		)
+	}
+
+	s.collectWriters(ch, producerMetric)
+	s.collectRouting(ch, producerMetric)
+	s.collectStreamState(ch)
 }
 
-func NewMetricCollector(producer producer.Producer) *Collector {
-	return &Collector{
-		producer: producer,
+func (s *Collector) collectWriters(ch chan<- prometheus.Metric, producerMetric *producer.Metric) {
+	if failover := s.producer.GetFailover(); failover != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.failovers,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.Failovers)),
+			[]string{}...,
+		)
 
-		kafkaConnectorLatency: prometheus.NewDesc(
-			prometheus.BuildFQName(helpers.Name, "kafka_connector_latency_ms", "current"),
-			"Kafka connector latency ms at 10sec windows",
-			[]string{},
-			nil,
-		),
+		var active float64
+		if failover.IsActive() {
+			active = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			s.failoverActive,
+			prometheus.GaugeValue,
+			active,
+			[]string{}...,
+		)
+	}
+
+	if s.producer.IsShadow() {
+		ch <- prometheus.MustNewConstMetric(
+			s.shadowMode,
+			prometheus.GaugeValue,
+			1,
+			[]string{}...,
+		)
+	}
+
+	if s.producer.GetChaos() != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.chaosInjected,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.ChaosDelays)),
+			"delay",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.chaosInjected,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.ChaosErrors)),
+			"error",
+		)
+	}
 
-		batchProduceLatency: prometheus.NewDesc(
-			prometheus.BuildFQName(helpers.Name, "kafka_connector_batch_produce_latency_ms", "current"),
-			"Kafka connector batch produce latency ms",
-			[]string{},
-			nil,
-		),
+	if writerStats := s.producer.GetWriterStats(); writerStats != nil {
+		for cluster, stat := range writerStats.Snapshot() {
+			s.collectWriterStats(ch, cluster, stat)
+		}
+	}
+}
+
+func (s *Collector) collectRouting(ch chan<- prometheus.Metric, producerMetric *producer.Metric) {
+	if s.producer.GetMirror() != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.produceErrors,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.MirrorErrors)),
+			"mirror",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.mirrorProduced,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.MirrorProduced)),
+			[]string{}...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.mirrorDeadLettered,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.MirrorDeadLettered)),
+			[]string{}...,
+		)
+	}
+
+	if compressionStats := s.producer.GetCompressionStats(); compressionStats != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.producedBytes,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.ProducedBytes)),
+			[]string{}...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.estimatedWireBytes,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.EstimatedWireBytes)),
+			[]string{}...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.compressionRatio,
+			prometheus.GaugeValue,
+			compressionStats.Ratio(),
+			[]string{}...,
+		)
+	}
+
+	if s.producer.GetMigration() != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.migrationRouted,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.MigrationCurrentRouted)),
+			"current",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.migrationRouted,
+			prometheus.CounterValue,
+			float64(atomic.LoadInt64(&producerMetric.MigrationNewRouted)),
+			"new",
+		)
+	}
+}
+
+func (s *Collector) collectStreamState(ch chan<- prometheus.Metric) {
+	if s.pauser != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.pauseHeldEvents,
+			prometheus.GaugeValue,
+			float64(s.pauser.Held()),
+			[]string{}...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.pauseBackPressures,
+			prometheus.CounterValue,
+			float64(s.pauser.BackPressures()),
+			[]string{}...,
+		)
+	}
+
+	if s.lag != nil {
+		vBuckets, catchUp := s.lag.Snapshot()
+		for _, vBucket := range vBuckets {
+			ch <- prometheus.MustNewConstMetric(
+				s.vBucketLag,
+				prometheus.GaugeValue,
+				float64(vBucket.Lag),
+				strconv.Itoa(int(vBucket.VbID)),
+			)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			s.catchUpPercentage,
+			prometheus.GaugeValue,
+			catchUp,
+			[]string{}...,
+		)
+	}
+}
+
+func (s *Collector) collectBatchMetrics(ch chan<- prometheus.Metric, m *producer.Metric) {
+	counters := map[*prometheus.Desc]*int64{
+		s.jsonComplexityExceeded:   &m.JSONComplexityExceeded,
+		s.oversizedDocuments:       &m.OversizedDocuments,
+		s.processingErrors:         &m.ProcessingErrors,
+		s.enrichmentErrors:         &m.EnrichmentErrors,
+		s.xattrErrors:              &m.XattrErrors,
+		s.latencyBudgetExceeded:    &m.LatencyBudgetExceeded,
+		s.latencyBudgetShed:        &m.LatencyBudgetShed,
+		s.staleFlushes:             &m.StaleFlushes,
+		s.bufferOverflows:          &m.BufferOverflows,
+		s.bufferDroppedMessages:    &m.BufferDroppedMessages,
+		s.outageQueuedMessages:     &m.OutageQueuedMessages,
+		s.rebalances:               &m.Rebalances,
+		s.rebalanceHeldMessages:    &m.RebalanceHeldMessages,
+		s.rebalanceDroppedMessages: &m.RebalanceDroppedMessages,
+		s.atMostOnceDropped:        &m.AtMostOnceDropped,
+		s.filteredEvents:           &m.FilteredEvents,
+		s.skippedBinaryDocuments:   &m.SkippedBinaryDocuments,
+		s.sampledOutEvents:         &m.SampledOutEvents,
+		s.disabledCollectionEvents: &m.DisabledCollectionEvents,
+		s.rollbacksDetected:        &m.RollbacksDetected,
+		s.schemaRegistryFallbacks:  &m.SchemaRegistryFallbacks,
+		s.dedupSuppressed:          &m.DedupSuppressed,
+		s.seqNoDedupSkipped:        &m.SeqNoDedupSkipped,
+		s.chunkedMessages:          &m.ChunkedMessages,
+		s.compressedValues:         &m.CompressedValues,
+		s.encryptedValues:          &m.EncryptedValues,
+		s.claimCheckedMessages:     &m.ClaimCheckedMessages,
+	}
+	for desc, value := range counters {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(atomic.LoadInt64(value)))
+	}
+
+	gauges := map[*prometheus.Desc]*int64{
+		s.batchLimit:          &m.BatchLimit,
+		s.batchTickerDuration: &m.BatchTickerDuration,
+		s.spilledMessages:     &m.SpilledMessages,
+		s.outageQueueBatches:  &m.OutageQueueBatches,
+		s.rebalanceDuration:   &m.RebalanceDuration,
+	}
+	for desc, value := range gauges {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(atomic.LoadInt64(value)))
+	}
+}
+
+func (s *Collector) collectWriterStats(ch chan<- prometheus.Metric, cluster string, stat producer.WriterStat) {
+	counters := map[*prometheus.Desc]int64{
+		s.writerWrites:   stat.Writes,
+		s.writerMessages: stat.Messages,
+		s.writerBytes:    stat.Bytes,
+		s.writerErrors:   stat.Errors,
+		s.writerRetries:  stat.Retries,
+	}
+	for desc, value := range counters {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), cluster)
 	}
+
+	gauges := map[*prometheus.Desc]float64{
+		s.writerBatchSize:         float64(stat.BatchSizeAvg),
+		s.writerBatchBytes:        float64(stat.BatchBytesAvg),
+		s.writerBatchTime:         float64(stat.BatchTimeAvg.Milliseconds()),
+		s.writerBatchQueueTime:    float64(stat.BatchQueueTimeAvg.Milliseconds()),
+		s.writerBatchQueueTimeMax: float64(stat.BatchQueueTimeMax.Milliseconds()),
+		s.writerWriteTime:         float64(stat.WriteTimeAvg.Milliseconds()),
+		s.writerWriteTimeMax:      float64(stat.WriteTimeMax.Milliseconds()),
+		s.writerThrottleTime:      float64(stat.ThrottleTimeAvg.Milliseconds()),
+	}
+	for desc, value := range gauges {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, cluster)
+	}
+}
+
+// NewMetricCollector takes a nil lag tracker when the lag metric is not enabled.
+func NewMetricCollector(producer producer.Producer, lagTracker *lag.Tracker, pauser *pause.Pauser) *Collector {
+	s := &Collector{
+		producer: producer,
+		lag:      lagTracker,
+		pauser:   pauser,
+	}
+	s.describeBatch()
+	s.describeDocuments()
+	s.describeBuffers()
+	s.describeRebalances()
+	s.describeSkippedEvents()
+	s.describeTopics()
+	s.describeWriters()
+	s.describeWriterTimes()
+	return s
+}
+
+func (s *Collector) describeBatch() {
+	s.endToEndLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_end_to_end_latency", "ms"),
+		"Kafka connector milliseconds from the mutation to the Kafka write acknowledgement",
+		[]string{},
+		nil,
+	)
+
+	s.batchProduceLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_batch_produce_latency_ms", "current"),
+		"Kafka connector batch produce latency ms",
+		[]string{},
+		nil,
+	)
+
+	s.batchLimit = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_batch_limit", "current"),
+		"Kafka connector batch message limit",
+		[]string{},
+		nil,
+	)
+
+	s.batchTickerDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_batch_ticker_duration_ms", "current"),
+		"Kafka connector batch ticker duration ms",
+		[]string{},
+		nil,
+	)
+
+	s.latencyBudgetExceeded = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_latency_budget_exceeded", "total"),
+		"Kafka connector messages exceeding the latency budget at flush time",
+		[]string{},
+		nil,
+	)
+
+	s.latencyBudgetShed = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_latency_budget_shed", "total"),
+		"Kafka connector messages rerouted to the late topic",
+		[]string{},
+		nil,
+	)
+
+	s.staleFlushes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_stale_flushes", "total"),
+		"Kafka connector flushes triggered by the oldest buffered message reaching the max staleness",
+		[]string{},
+		nil,
+	)
+
+	s.migrationRouted = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_migration_routed", "total"),
+		"Kafka connector messages routed per migration destination",
+		[]string{"destination"},
+		nil,
+	)
+}
+
+func (s *Collector) describeDocuments() {
+	s.jsonComplexityExceeded = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_json_complexity_exceeded", "total"),
+		"Kafka connector documents exceeding the json complexity limit",
+		[]string{},
+		nil,
+	)
+
+	s.oversizedDocuments = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_oversized_documents", "total"),
+		"Kafka connector documents exceeding the document size limit",
+		[]string{},
+		nil,
+	)
+
+	s.processingErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_processing_errors", "total"),
+		"Kafka connector documents skipped or dead lettered after a transform, mapper, value template or chunking error",
+		[]string{},
+		nil,
+	)
+
+	s.enrichmentErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_enrichment_errors", "total"),
+		"Kafka connector documents produced without enrichment because of lookup errors",
+		[]string{},
+		nil,
+	)
+
+	s.xattrErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_xattr_errors", "total"),
+		"Kafka connector mutations produced without xattrs because they cannot be read",
+		[]string{},
+		nil,
+	)
+
+	s.chunkedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_chunked_messages", "total"),
+		"Kafka connector messages split into chunks",
+		[]string{},
+		nil,
+	)
+
+	s.compressedValues = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_compressed_values", "total"),
+		"Kafka connector message values compressed by kafka.valueCompression",
+		[]string{},
+		nil,
+	)
+
+	s.encryptedValues = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_encrypted_values", "total"),
+		"Kafka connector message values encrypted by kafka.encryption",
+		[]string{},
+		nil,
+	)
+
+	s.claimCheckedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_claim_checked_messages", "total"),
+		"Kafka connector messages produced as claim check references",
+		[]string{},
+		nil,
+	)
+}
+
+func (s *Collector) describeBuffers() {
+	s.pauseHeldEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_pause_held_events", "current"),
+		"Kafka connector events held unacknowledged by paused vBuckets and key prefixes",
+		[]string{},
+		nil,
+	)
+
+	s.pauseBackPressures = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_pause_back_pressures", "total"),
+		"Kafka connector listener waits on kafka.pause.maxHeldEvents held events",
+		[]string{},
+		nil,
+	)
+
+	s.bufferOverflows = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_buffer_overflows", "total"),
+		"Kafka connector events over the buffer limit",
+		[]string{},
+		nil,
+	)
+
+	s.bufferDroppedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_buffer_dropped_messages", "total"),
+		"Kafka connector buffered messages dropped by the dropOldest buffer overflow policy",
+		[]string{},
+		nil,
+	)
+
+	s.spilledMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_spilled_messages", "current"),
+		"Kafka connector messages in the spill queue",
+		[]string{},
+		nil,
+	)
+
+	s.outageQueuedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_outage_queued_messages", "total"),
+		"Kafka connector messages persisted to the outage queue",
+		[]string{},
+		nil,
+	)
+
+	s.outageQueueBatches = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_outage_queue_batches", "current"),
+		"Kafka connector batches in the outage queue waiting for replay",
+		[]string{},
+		nil,
+	)
+}
+
+func (s *Collector) describeRebalances() {
+	s.rebalances = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_rebalances", "total"),
+		"Kafka connector DCP rebalances",
+		[]string{},
+		nil,
+	)
+
+	s.rebalanceDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_rebalance_duration_ms", "current"),
+		"Kafka connector duration ms of the last DCP rebalance",
+		[]string{},
+		nil,
+	)
+
+	s.rebalanceHeldMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_rebalance_held_messages", "total"),
+		"Kafka connector messages pending in the batch when the streams stopped for a rebalance",
+		[]string{},
+		nil,
+	)
+
+	s.rebalanceDroppedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_rebalance_dropped_messages", "total"),
+		"Kafka connector messages discarded or refused while rebalancing, their events are streamed again",
+		[]string{},
+		nil,
+	)
+
+	s.rollbacksDetected = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_rollbacks_detected", "total"),
+		"Kafka connector vbucket rollbacks detected from seqnos going backwards",
+		[]string{},
+		nil,
+	)
+
+	s.vBucketLag = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_lag", "current"),
+		"Kafka connector seqnos between the last produced event and the high seqno per vBucket",
+		[]string{"vbId"},
+		nil,
+	)
+
+	s.catchUpPercentage = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_catch_up_percentage", "current"),
+		"Kafka connector produced share of the high seqnos of its vBuckets",
+		[]string{},
+		nil,
+	)
+}
+
+func (s *Collector) describeSkippedEvents() {
+	s.atMostOnceDropped = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_at_most_once_dropped", "total"),
+		"Kafka connector messages of failed writes dropped in at most once delivery",
+		[]string{},
+		nil,
+	)
+
+	s.filteredEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_filtered_events", "total"),
+		"Kafka connector events not matching the filter expression",
+		[]string{},
+		nil,
+	)
+
+	s.skippedBinaryDocuments = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_skipped_binary_documents", "total"),
+		"Kafka connector counter and binary documents acknowledged without producing",
+		[]string{},
+		nil,
+	)
+
+	s.sampledOutEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_sampled_out_events", "total"),
+		"Kafka connector mutations not produced by kafka.sampling",
+		[]string{},
+		nil,
+	)
+
+	s.disabledCollectionEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_disabled_collection_events", "total"),
+		"Kafka connector events acknowledged without producing because their collection is disabled",
+		[]string{},
+		nil,
+	)
+
+	s.schemaRegistryFallbacks = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_schema_registry_fallbacks", "total"),
+		"Kafka connector messages serialized with a schema registry fallback",
+		[]string{},
+		nil,
+	)
+
+	s.dedupSuppressed = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_dedup_suppressed", "total"),
+		"Kafka connector messages suppressed as duplicates within the dedup window",
+		[]string{},
+		nil,
+	)
+
+	s.seqNoDedupSkipped = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_seqno_dedup_skipped", "total"),
+		"Kafka connector events skipped as produced before the restart",
+		[]string{},
+		nil,
+	)
+}
+
+func (s *Collector) describeTopics() {
+	s.producedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_produced_bytes", "total"),
+		"Kafka connector uncompressed key, value and header bytes of flushed messages",
+		[]string{},
+		nil,
+	)
+
+	s.estimatedWireBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_estimated_wire_bytes", "total"),
+		"Kafka connector flushed bytes after compression, estimated with the sampled compression ratio",
+		[]string{},
+		nil,
+	)
+
+	s.compressionRatio = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_compression_ratio", "current"),
+		"Kafka connector last sampled compressed to uncompressed byte ratio",
+		[]string{},
+		nil,
+	)
+
+	s.produceErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_produce_errors", "total"),
+		"Kafka connector failed batch writes per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.topicProducedMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_topic_produced_messages", "total"),
+		"Kafka connector messages written per destination topic",
+		[]string{"topic"},
+		nil,
+	)
+
+	s.topicProducedBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_topic_produced_bytes", "total"),
+		"Kafka connector uncompressed key, value and header bytes written per destination topic",
+		[]string{"topic"},
+		nil,
+	)
+
+	s.topicProduceErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_topic_produce_errors", "total"),
+		"Kafka connector messages of failed writes per destination topic",
+		[]string{"topic"},
+		nil,
+	)
+
+	s.mirrorProduced = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_mirror_produced", "total"),
+		"Kafka connector messages produced to the mirror cluster",
+		[]string{},
+		nil,
+	)
+
+	s.mirrorDeadLettered = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_mirror_dead_lettered", "total"),
+		"Kafka connector messages produced to the mirror dead letter topic after permanent mirror errors",
+		[]string{},
+		nil,
+	)
+}
+
+func (s *Collector) describeWriters() {
+	s.chaosInjected = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_chaos_injected", "total"),
+		"Kafka connector batch writes delayed or failed by chaos injection",
+		[]string{"type"},
+		nil,
+	)
+
+	s.failovers = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_failovers", "total"),
+		"Kafka connector failovers to the standby cluster",
+		[]string{},
+		nil,
+	)
+
+	s.failoverActive = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_failover_active", "current"),
+		"Kafka connector produces to the standby cluster when 1",
+		[]string{},
+		nil,
+	)
+
+	s.shadowMode = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_shadow_mode", "current"),
+		"Kafka connector skips the writes and only logs them when 1",
+		[]string{},
+		nil,
+	)
+
+	s.writerWrites = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_writes", "total"),
+		"Kafka connector produce requests of the kafka writers per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerMessages = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_messages", "total"),
+		"Kafka connector messages written by the kafka writers per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_bytes", "total"),
+		"Kafka connector bytes written by the kafka writers per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_errors", "total"),
+		"Kafka connector errors of the kafka writers per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerRetries = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_retries", "total"),
+		"Kafka connector retried produce requests of the kafka writers per cluster",
+		[]string{"cluster"},
+		nil,
+	)
+}
+
+func (s *Collector) describeWriterTimes() {
+	s.writerBatchSize = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_batch_size", "current"),
+		"Kafka connector average messages per kafka writer batch in the last sample interval",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerBatchBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_batch_bytes", "current"),
+		"Kafka connector average bytes per kafka writer batch in the last sample interval",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerBatchTime = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_batch_time_ms", "current"),
+		"Kafka connector average ms from the first message of a kafka writer batch to its write",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerBatchQueueTime = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_batch_queue_time_ms", "current"),
+		"Kafka connector average ms kafka writer batches took to fill before their write was queued",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerBatchQueueTimeMax = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_batch_queue_time_max_ms", "current"),
+		"Kafka connector maximum ms a kafka writer batch took to fill before its write was queued",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerWriteTime = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_write_time_ms", "current"),
+		"Kafka connector average ms of the kafka writer produce requests",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerWriteTimeMax = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_write_time_max_ms", "current"),
+		"Kafka connector maximum ms of a kafka writer produce request",
+		[]string{"cluster"},
+		nil,
+	)
+
+	s.writerThrottleTime = prometheus.NewDesc(
+		prometheus.BuildFQName(helpers.Name, "kafka_connector_writer_throttle_time_ms", "current"),
+		"Kafka connector average ms Kafka throttled the kafka writer produce requests",
+		[]string{"cluster"},
+		nil,
+	)
 }
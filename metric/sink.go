@@ -0,0 +1,144 @@
+package metric
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Sample is a connector metric, the Value of a counter is its increase since the previous push.
+type Sample struct {
+	Tags    map[string]string
+	Name    string
+	Value   float64
+	Counter bool
+}
+
+// Sink receives the connector metrics pushed every interval, e.g. to a StatsD agent. Prometheus scrapes the
+// Collector from the go-dcp API instead.
+type Sink interface {
+	Emit(samples []Sample) error
+	Close() error
+}
+
+// Pusher gathers the Collector every interval and emits the samples to a Sink. Histograms are pushed as their
+// _count and _sum counters.
+type Pusher struct {
+	sink     Sink
+	gatherer prometheus.Gatherer
+	previous map[string]float64
+	stop     chan struct{}
+	stopped  chan struct{}
+	interval time.Duration
+}
+
+func NewPusher(collector *Collector, sink Sink, interval time.Duration) *Pusher {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	return &Pusher{
+		sink:     sink,
+		gatherer: registry,
+		previous: map[string]float64{},
+		stop:     make(chan struct{}),
+		interval: interval,
+	}
+}
+
+// Start pushes every interval until Close.
+func (p *Pusher) Start() {
+	p.stopped = make(chan struct{})
+	ticker := time.NewTicker(p.interval)
+	go func() {
+		defer close(p.stopped)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.push()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *Pusher) push() {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		logger.Log.Error("cannot gather metrics to push, err: %v", err)
+		return
+	}
+
+	if err = p.sink.Emit(p.samples(families)); err != nil {
+		logger.Log.Error("cannot push metrics, err: %v", err)
+	}
+}
+
+func (p *Pusher) samples(families []*dto.MetricFamily) []Sample {
+	var samples []Sample
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			tags := make(map[string]string, len(m.GetLabel()))
+			for _, label := range m.GetLabel() {
+				tags[label.GetName()] = label.GetValue()
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				samples = append(samples, p.counter(family.GetName(), tags, m.GetCounter().GetValue()))
+			case dto.MetricType_HISTOGRAM:
+				samples = append(samples,
+					p.counter(family.GetName()+"_count", tags, float64(m.GetHistogram().GetSampleCount())),
+					p.counter(family.GetName()+"_sum", tags, m.GetHistogram().GetSampleSum()),
+				)
+			case dto.MetricType_GAUGE:
+				samples = append(samples, Sample{Name: family.GetName(), Tags: tags, Value: m.GetGauge().GetValue()})
+			default:
+				samples = append(samples, Sample{Name: family.GetName(), Tags: tags, Value: m.GetUntyped().GetValue()})
+			}
+		}
+	}
+	return samples
+}
+
+// counter returns the increase since the previous push, the whole value after a reset.
+func (p *Pusher) counter(name string, tags map[string]string, value float64) Sample {
+	key := name + "{" + joinTags(tags, "=", ",") + "}"
+	increase := value - p.previous[key]
+	if increase < 0 {
+		increase = value
+	}
+	p.previous[key] = value
+	return Sample{Name: name, Tags: tags, Value: increase, Counter: true}
+}
+
+func joinTags(tags map[string]string, separator string, delimiter string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + separator + tags[key]
+	}
+	return strings.Join(pairs, delimiter)
+}
+
+// Close pushes the metrics of the drain once more and closes the sink.
+func (p *Pusher) Close() {
+	if p.stopped != nil {
+		close(p.stop)
+		<-p.stopped
+		p.push()
+	}
+	if err := p.sink.Close(); err != nil {
+		logger.Log.Error("cannot close metric sink, err: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package metric
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxPacketSize keeps the datagrams within the ethernet MTU, so the agent does not drop fragmented packets.
+const maxPacketSize = 1432
+
+// StatsD writes the samples to a StatsD agent over UDP. The DogStatsD format of the Datadog agent carries the tags,
+// the plain format appends the tag values to the metric name instead.
+type StatsD struct {
+	conn      net.Conn
+	tags      map[string]string
+	prefix    string
+	dogStatsD bool
+}
+
+// NewStatsD adds the tags to every sample, they are only sent in the DogStatsD format.
+func NewStatsD(address string, prefix string, tags map[string]string, dogStatsD bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{conn: conn, prefix: prefix, tags: tags, dogStatsD: dogStatsD}, nil
+}
+
+func (s *StatsD) Emit(samples []Sample) error {
+	var packet []byte
+	for i := range samples {
+		line := s.line(&samples[i])
+		if len(packet) > 0 && len(packet)+1+len(line) > maxPacketSize {
+			if _, err := s.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+
+	if len(packet) > 0 {
+		_, err := s.conn.Write(packet)
+		return err
+	}
+	return nil
+}
+
+func (s *StatsD) line(sample *Sample) string {
+	var sb strings.Builder
+	sb.WriteString(sanitize(s.prefix + sample.Name))
+	if !s.dogStatsD {
+		keys := make([]string, 0, len(sample.Tags))
+		for key := range sample.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			sb.WriteString(".")
+			sb.WriteString(sanitize(sample.Tags[key]))
+		}
+	}
+
+	sb.WriteString(":")
+	sb.WriteString(strconv.FormatFloat(sample.Value, 'f', -1, 64))
+	if sample.Counter {
+		sb.WriteString("|c")
+	} else {
+		sb.WriteString("|g")
+	}
+
+	if s.dogStatsD && len(s.tags)+len(sample.Tags) > 0 {
+		tags := make(map[string]string, len(s.tags)+len(sample.Tags))
+		for key, value := range s.tags {
+			tags[key] = value
+		}
+		for key, value := range sample.Tags {
+			tags[key] = value
+		}
+		sb.WriteString("|#")
+		sb.WriteString(joinTags(tags, ":", ","))
+	}
+	return sb.String()
+}
+
+var sanitizer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_", " ", "_")
+
+func sanitize(name string) string {
+	return sanitizer.Replace(name)
+}
+
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,319 @@
+package pause
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/state"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+const stateName = "pause.json"
+
+type State struct {
+	VbIDs       []uint16 `json:"vbIds"`
+	KeyPrefixes []string `json:"keyPrefixes"`
+}
+
+// Pauser holds events of paused vBuckets and keys without acknowledging them, and replays them in order on resume.
+//
+// Once an event of a vBucket is held, every following event of the same vBucket is held behind it until the
+// queue is drained. This keeps the per vBucket order and prevents checkpoints from passing a held event,
+// so a key prefix pause behaves like a vBucket pause from its first matching event on.
+//
+// At most maxHeld events are held, the listener blocks on the next one until a drain or a reset makes room, which
+// back-pressures the DCP stream instead of growing the memory of a long pause. Zero holds without a limit.
+type Pauser struct {
+	store         state.Store
+	replay        func(ctx *models.ListenerContext)
+	vbIDs         map[uint16]struct{}
+	held          map[uint16][]*models.ListenerContext
+	draining      map[uint16]bool
+	released      *sync.Cond
+	keyPrefixes   []string
+	lock          sync.Mutex
+	generation    int
+	maxHeld       int
+	heldCount     int
+	backPressures int64
+	active        int32
+	closed        bool
+}
+
+func NewPauser(store state.Store, replay func(ctx *models.ListenerContext), maxHeld int) (*Pauser, error) {
+	p := &Pauser{
+		store:    store,
+		replay:   replay,
+		vbIDs:    map[uint16]struct{}{},
+		held:     map[uint16][]*models.ListenerContext{},
+		draining: map[uint16]bool{},
+		maxHeld:  maxHeld,
+	}
+	p.released = sync.NewCond(&p.lock)
+
+	data, err := store.Load(stateName)
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		var s State
+		if err := jsoniter.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		p.add(s)
+		logger.Log.Info("restored pause state, vbIds: %v, keyPrefixes: %v", s.VbIDs, s.KeyPrefixes)
+	}
+
+	p.updateActive()
+	return p, nil
+}
+
+// Hold returns true when the event is taken over by the pauser and must not be processed by the caller.
+func (p *Pauser) Hold(ctx *models.ListenerContext) bool {
+	if atomic.LoadInt32(&p.active) == 0 {
+		return false
+	}
+
+	vbID, key, ok := eventVbIDAndKey(ctx.Event)
+	if !ok {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for waited := false; ; waited = true {
+		if len(p.held[vbID]) == 0 && !p.draining[vbID] && !p.isPaused(vbID, key) {
+			return false
+		}
+		if p.maxHeld == 0 || p.heldCount < p.maxHeld {
+			break
+		}
+		// the event is not acknowledged, it is streamed again on the next start
+		if p.closed {
+			return true
+		}
+		if !waited {
+			atomic.AddInt64(&p.backPressures, 1)
+		}
+		p.released.Wait()
+	}
+
+	p.held[vbID] = append(p.held[vbID], ctx)
+	p.heldCount++
+	p.updateActive()
+	return true
+}
+
+func (p *Pauser) Pause(s State) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.add(s)
+	p.updateActive()
+	return p.save()
+}
+
+func (p *Pauser) Resume(s State) error {
+	p.lock.Lock()
+
+	for _, vbID := range s.VbIDs {
+		delete(p.vbIDs, vbID)
+	}
+
+	keyPrefixes := p.keyPrefixes[:0]
+	for _, prefix := range p.keyPrefixes {
+		if !contains(s.KeyPrefixes, prefix) {
+			keyPrefixes = append(keyPrefixes, prefix)
+		}
+	}
+	p.keyPrefixes = keyPrefixes
+
+	err := p.save()
+
+	var drain []uint16
+	generation := p.generation
+	for vbID := range p.held {
+		if !p.draining[vbID] {
+			p.draining[vbID] = true
+			drain = append(drain, vbID)
+		}
+	}
+	p.lock.Unlock()
+
+	for _, vbID := range drain {
+		go p.drain(vbID, generation)
+	}
+
+	return err
+}
+
+// Reset drops the held events when the streams stop, they are streamed again from the checkpoint and may belong to
+// vBuckets this member no longer owns. The paused vBuckets and key prefixes are kept, a running drain stops at its
+// next event.
+func (p *Pauser) Reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.held = map[uint16][]*models.ListenerContext{}
+	p.draining = map[uint16]bool{}
+	p.heldCount = 0
+	p.generation++
+	p.updateActive()
+	p.released.Broadcast()
+}
+
+// Close releases a listener blocked on the held events limit.
+func (p *Pauser) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+	p.released.Broadcast()
+}
+
+func (p *Pauser) State() State {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return State{
+		VbIDs:       p.stateVbIDs(),
+		KeyPrefixes: append([]string{}, p.keyPrefixes...),
+	}
+}
+
+// HeldCounts returns the number of held events per vBucket.
+func (p *Pauser) HeldCounts() map[uint16]int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	counts := make(map[uint16]int, len(p.held))
+	for vbID, held := range p.held {
+		counts[vbID] = len(held)
+	}
+	return counts
+}
+
+// Held returns the number of held events of every vBucket.
+func (p *Pauser) Held() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.heldCount
+}
+
+// BackPressures returns how many times the listener waited on the held events limit.
+func (p *Pauser) BackPressures() int64 {
+	return atomic.LoadInt64(&p.backPressures)
+}
+
+// drain stops once a Reset starts a new generation, the events held since then belong to the new streams.
+func (p *Pauser) drain(vbID uint16, generation int) {
+	for {
+		p.lock.Lock()
+		if p.generation != generation {
+			p.lock.Unlock()
+			return
+		}
+		held := p.held[vbID]
+
+		if len(held) == 0 {
+			delete(p.held, vbID)
+			delete(p.draining, vbID)
+			p.updateActive()
+			p.lock.Unlock()
+			return
+		}
+
+		if _, key, _ := eventVbIDAndKey(held[0].Event); p.isPaused(vbID, key) {
+			delete(p.draining, vbID)
+			p.lock.Unlock()
+			return
+		}
+
+		ctx := held[0]
+		p.held[vbID] = held[1:]
+		p.heldCount--
+		p.released.Broadcast()
+		p.lock.Unlock()
+
+		p.replay(ctx)
+	}
+}
+
+func (p *Pauser) add(s State) {
+	for _, vbID := range s.VbIDs {
+		p.vbIDs[vbID] = struct{}{}
+	}
+	for _, prefix := range s.KeyPrefixes {
+		if !contains(p.keyPrefixes, prefix) {
+			p.keyPrefixes = append(p.keyPrefixes, prefix)
+		}
+	}
+}
+
+func (p *Pauser) isPaused(vbID uint16, key []byte) bool {
+	if _, ok := p.vbIDs[vbID]; ok {
+		return true
+	}
+	for _, prefix := range p.keyPrefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pauser) updateActive() {
+	var active int32
+	if len(p.vbIDs) > 0 || len(p.keyPrefixes) > 0 || len(p.held) > 0 {
+		active = 1
+	}
+	atomic.StoreInt32(&p.active, active)
+}
+
+func (p *Pauser) save() error {
+	data, err := jsoniter.Marshal(State{
+		VbIDs:       p.stateVbIDs(),
+		KeyPrefixes: p.keyPrefixes,
+	})
+	if err != nil {
+		return err
+	}
+	return p.store.Save(stateName, data)
+}
+
+func (p *Pauser) stateVbIDs() []uint16 {
+	vbIDs := make([]uint16, 0, len(p.vbIDs))
+	for vbID := range p.vbIDs {
+		vbIDs = append(vbIDs, vbID)
+	}
+	return vbIDs
+}
+
+func eventVbIDAndKey(event interface{}) (uint16, []byte, bool) {
+	switch e := event.(type) {
+	case models.DcpMutation:
+		return e.VbID, e.Key, true
+	case models.DcpDeletion:
+		return e.VbID, e.Key, true
+	case models.DcpExpiration:
+		return e.VbID, e.Key, true
+	default:
+		return 0, nil, false
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
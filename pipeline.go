@@ -0,0 +1,64 @@
+package dcpkafka
+
+import (
+	"github.com/Trendyol/go-dcp/models"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/kafka/producer"
+	"github.com/Trendyol/go-dcp-kafka/state"
+	"github.com/Trendyol/go-dcp-kafka/toggle"
+)
+
+// Pipeline runs the event processing of a connector without Couchbase and Kafka, e.g. to unit test a mapper with its
+// filters, headers, key strategy and transforms against a producer.Recorder. Enrichment, schema registry and claim
+// check need their connections and are not applied.
+type Pipeline struct {
+	connector *connector
+}
+
+// NewPipeline builds the pipeline of the builder, its config is optional and not validated, so hosts and brokers are
+// not needed.
+func NewPipeline(builder ConnectorBuilder, sink producer.Sink) (*Pipeline, error) {
+	c := &config.Connector{}
+	if builder.config != nil {
+		cf, err := newConfig(builder.config)
+		if err != nil {
+			return nil, err
+		}
+		cc := *cf
+		c = &cc
+	}
+	c.Kafka.SecureConnection = false
+	c.ApplyDefaults()
+
+	connector, err := newPipeline(builder, c)
+	if err != nil {
+		return nil, err
+	}
+
+	connector.collections, err = toggle.NewCollections(state.NewMemoryStore())
+	if err != nil {
+		return nil, err
+	}
+
+	// the batch holds the metrics, its writer is never used
+	connector.producer, err = producer.NewProducer(kafka.NewClient(c, nil), c, func() {}, nil)
+	if err != nil {
+		return nil, err
+	}
+	connector.sink = sink
+
+	return &Pipeline{connector: connector}, nil
+}
+
+// Process runs the event through the pipeline, the messages go to the sink.
+func (p *Pipeline) Process(e couchbase.Event) {
+	p.connector.processEvent(&models.ListenerContext{Ack: func() {}}, &e)
+}
+
+// Metric returns the counters of the pipeline, e.g. FilteredEvents.
+func (p *Pipeline) Metric() *producer.Metric {
+	return p.connector.producer.GetMetric()
+}
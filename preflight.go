@@ -0,0 +1,64 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+)
+
+// Preflight validates the config, connects to the bucket and the brokers, and checks the configured topics with
+// validateTopics, e.g. to sanity check a deployment in CI. Topics set by the mapper cannot be known and are not checked.
+func Preflight(c *config.Connector) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	c = withDcpDefaults(c)
+
+	client := dcpCouchbase.NewClient(&c.Dcp)
+	if err := client.Connect(); err != nil {
+		return fmt.Errorf("couchbase: %w", err)
+	}
+	client.Close()
+
+	secretProvider, err := newSecretProvider(&c.Kafka.Secrets, nil)
+	if err != nil {
+		return err
+	}
+
+	kafkaClient := kafka.NewClient(c, secretProvider)
+	// without topics the metadata request only checks the brokers are reachable
+	if err := kafkaClient.CheckTopics(nil); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	if err := validateTopics(kafkaClient, &c.Kafka, c.Kafka.AllowAutoTopicCreation || c.Kafka.TopicCreation.Enabled); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	return nil
+}
+
+// configuredTopics returns the topics of the primary cluster named in the config.
+func configuredTopics(k *config.Kafka) []string {
+	seen := map[string]struct{}{}
+	for _, topic := range k.CollectionTopicMapping {
+		seen[topic] = struct{}{}
+	}
+	rollbackTopic := ""
+	if k.RollbackMarker.Enabled {
+		rollbackTopic = k.RollbackMarker.Topic
+	}
+	for _, topic := range []string{k.DeadLetterTopic, k.LatencyBudget.LateTopic, rollbackTopic} {
+		if topic != "" {
+			seen[topic] = struct{}{}
+		}
+	}
+
+	topics := make([]string, 0, len(seen))
+	for topic := range seen {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
@@ -0,0 +1,23 @@
+package dcpkafka
+
+import (
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// rejectEvent skips or dead letters an event whose messages cannot be built with kafka.processingErrorPolicy, the
+// error depends on the document, so stopping the connector would stream the same event again on the restart.
+func (c *connector) rejectEvent(ctx *models.ListenerContext, e *couchbase.Event, err error) {
+	atomic.AddInt64(&c.producer.GetMetric().ProcessingErrors, 1)
+
+	if c.config.Kafka.ProcessingErrorPolicy == config.ProcessingErrorPolicyDeadLetter {
+		c.produceDeadLetter(ctx, e, err)
+		return
+	}
+	logging.Error(append(eventFields(e), logging.Err(err)), "skipping document")
+	ctx.Ack()
+}
@@ -0,0 +1,231 @@
+package dcpkafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+var errReplayRunning = errors.New("a replay is already running")
+
+// ReplayRange is an inclusive seqno window of a vBucket.
+type ReplayRange struct {
+	VbID uint16 `json:"vbId"`
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// replayWindow acks the events after the window of their vBucket and closes done once every window is passed.
+type replayWindow struct {
+	to   map[uint16]uint64
+	done chan struct{}
+	lock sync.Mutex
+}
+
+func (w *replayWindow) pass(vbID uint16) {
+	delete(w.to, vbID)
+	if len(w.to) == 0 {
+		close(w.done)
+	}
+}
+
+func (c *connector) skipAfterReplay(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.replay == nil {
+		return false
+	}
+
+	c.replay.lock.Lock()
+	defer c.replay.lock.Unlock()
+
+	to, ok := c.replay.to[e.VbID]
+	if !ok || e.SeqNo > to {
+		if ok {
+			c.replay.pass(e.VbID)
+		}
+		ctx.Ack()
+		return true
+	}
+	if e.SeqNo == to {
+		c.replay.pass(e.VbID)
+	}
+	return false
+}
+
+// newReplayWindow streams the vBuckets of the ranges only, each from the start of its range.
+func newReplayWindow(c *config.Connector, ranges []ReplayRange) (*replayWindow, error) {
+	c.Kafka.StartFrom.SeqNos = map[uint16]uint64{}
+	c.Kafka.VBuckets = nil
+
+	window := &replayWindow{to: map[uint16]uint64{}, done: make(chan struct{})}
+	for _, r := range ranges {
+		if r.From > r.To {
+			return nil, fmt.Errorf("replay range of vBucket %d is invalid: %d-%d", r.VbID, r.From, r.To)
+		}
+		if _, ok := window.to[r.VbID]; ok {
+			return nil, fmt.Errorf("replay range of vBucket %d is duplicated", r.VbID)
+		}
+		window.to[r.VbID] = r.To
+
+		// startFrom resumes after the seqno
+		if r.From > 0 {
+			c.Kafka.StartFrom.SeqNos[r.VbID] = r.From - 1
+		} else {
+			c.Kafka.StartFrom.SeqNos[r.VbID] = 0
+		}
+		c.Kafka.VBuckets = append(c.Kafka.VBuckets, strconv.Itoa(int(r.VbID)))
+	}
+	return window, nil
+}
+
+// Replay produces the events of the seqno ranges through the mapping and the produce path of the builder with a
+// replay=true header, e.g. to repair downstream data without a full re-stream. It returns once every range is
+// produced or ctx is done, the checkpoints of the group are only read, see standaloneConfig.
+func Replay(ctx context.Context, builder ConnectorBuilder, ranges []ReplayRange) error {
+	if len(ranges) == 0 {
+		return errors.New("replay ranges must not be empty")
+	}
+
+	c, err := standaloneConfig(builder)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"replay": "true"}
+	for key, value := range c.Kafka.Headers {
+		headers[key] = value
+	}
+	c.Kafka.Headers = headers
+	window, err := newReplayWindow(c, ranges)
+	if err != nil {
+		return err
+	}
+	builder.config = c
+	builder.metricSink = nil
+
+	built, err := newConnector(builder)
+	if err != nil {
+		return err
+	}
+	connector := built.(*connector)
+
+	// a window ending after the high seqno would wait for new mutations
+	highSeqNos, err := connector.metadataClient.GetVBucketSeqNos()
+	if err != nil {
+		connector.closeMetadataClient()
+		return err
+	}
+	for _, r := range ranges {
+		if highSeqNo := highSeqNos[r.VbID]; r.From > highSeqNo {
+			window.pass(r.VbID)
+		} else if r.To > highSeqNo {
+			window.to[r.VbID] = highSeqNo
+		}
+	}
+	connector.replay = window
+
+	logger.Log.Info("replaying %d seqno ranges", len(ranges))
+	runStandalone(ctx, connector, window.done)
+
+	select {
+	case <-window.done:
+		return nil
+	default:
+		return ctx.Err()
+	}
+}
+
+// ParseReplayRanges parses comma separated vbId=from-to windows, e.g. 5=100-200,6=0-50.
+func ParseReplayRanges(value string) ([]ReplayRange, error) {
+	var ranges []ReplayRange
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		vbID, window, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid replay range: %s", item)
+		}
+		from, to, ok := strings.Cut(window, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid replay range: %s", item)
+		}
+
+		parsedVbID, err := strconv.ParseUint(vbID, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vbId: %s", vbID)
+		}
+		parsedFrom, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seqno: %s", from)
+		}
+		parsedTo, err := strconv.ParseUint(to, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seqno: %s", to)
+		}
+		ranges = append(ranges, ReplayRange{VbID: uint16(parsedVbID), From: parsedFrom, To: parsedTo})
+	}
+	return ranges, nil
+}
+
+// replayRunner runs a single replay at a time in the background for the /replay endpoint.
+type replayRunner struct {
+	cancel  context.CancelFunc
+	err     error
+	ranges  []ReplayRange
+	lock    sync.Mutex
+	running bool
+}
+
+func (r *replayRunner) start(builder ConnectorBuilder, ranges []ReplayRange) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.running {
+		return errReplayRunning
+	}
+
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.ranges, r.err, r.running = ranges, nil, true
+
+	go func() {
+		err := Replay(ctx, builder, ranges)
+		if err != nil {
+			logger.Log.Error("replay error: %v", err)
+		}
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.err, r.running = err, false
+	}()
+	return nil
+}
+
+func (r *replayRunner) state() map[string]any {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state := map[string]any{"running": r.running, "ranges": r.ranges}
+	if r.err != nil {
+		state["error"] = r.err.Error()
+	}
+	return state
+}
+
+func (r *replayRunner) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
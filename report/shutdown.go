@@ -0,0 +1,48 @@
+package report
+
+import "time"
+
+const (
+	ShutdownStatusClean   = "clean"
+	ShutdownStatusPartial = "partial"
+	ShutdownStatusFatal   = "fatal"
+)
+
+// Exit codes for orchestration systems, 2 is left out since it is used by the go runtime for unrecovered panics.
+const (
+	ExitCodeClean   = 0
+	ExitCodeFatal   = 1
+	ExitCodePartial = 3
+)
+
+// Shutdown describes the final drain of the connector. Produced messages and checkpoints are counted from the start.
+type Shutdown struct {
+	StoppedAt            time.Time `json:"stoppedAt"`
+	Status               string    `json:"status"`
+	Error                string    `json:"error,omitempty"`
+	DrainDuration        string    `json:"drainDuration"`
+	MessagesProduced     int64     `json:"messagesProduced"`
+	PendingLost          int       `json:"pendingLost"`
+	CheckpointsCommitted int64     `json:"checkpointsCommitted"`
+	ExitCode             int       `json:"exitCode"`
+}
+
+func NewShutdown(started time.Time, messagesProduced int64, checkpointsCommitted int64, pendingLost int, err error) *Shutdown {
+	s := &Shutdown{
+		StoppedAt:            time.Now(),
+		DrainDuration:        time.Since(started).String(),
+		MessagesProduced:     messagesProduced,
+		PendingLost:          pendingLost,
+		CheckpointsCommitted: checkpointsCommitted,
+		Status:               ShutdownStatusClean,
+		ExitCode:             ExitCodeClean,
+	}
+
+	switch {
+	case err != nil:
+		s.Status, s.ExitCode, s.Error = ShutdownStatusFatal, ExitCodeFatal, err.Error()
+	case pendingLost > 0:
+		s.Status, s.ExitCode = ShutdownStatusPartial, ExitCodePartial
+	}
+	return s
+}
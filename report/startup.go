@@ -0,0 +1,258 @@
+package report
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+
+	gKafka "github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp/config"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/metadata"
+)
+
+const (
+	StartPositionCheckpoint = "checkpoint"
+	StartPositionBeginning  = "beginning"
+	StartPositionLatest     = "latest"
+)
+
+type VBucket struct {
+	StartPosition   string `json:"startPosition"`
+	CheckpointAge   string `json:"checkpointAge,omitempty"`
+	CheckpointSeqNo uint64 `json:"checkpointSeqNo"`
+	HighSeqNo       uint64 `json:"highSeqNo"`
+	ExpectedReplay  uint64 `json:"expectedReplay"`
+	VbID            uint16 `json:"vbId"`
+}
+
+type Topic struct {
+	EndOffsets     map[int]int64 `json:"endOffsets"`
+	Topic          string        `json:"topic"`
+	TotalEndOffset int64         `json:"totalEndOffset"`
+}
+
+// Startup describes the restored state before streaming begins, so operators know how much
+// duplication or backfill to expect. It covers every vBucket of the bucket, not only the ones of this member.
+type Startup struct {
+	CreatedAt        time.Time `json:"createdAt"`
+	VBuckets         []VBucket `json:"vBuckets"`
+	Topics           []Topic   `json:"topics"`
+	ExpectedReplay   uint64    `json:"expectedReplay"`
+	CheckpointExists bool      `json:"checkpointExists"`
+}
+
+// NewStartup opens a short-lived connection to read high seqnos and checkpoints.
+// A nil checkpointMetadata means the Couchbase or file metadata of the dcp config is used.
+func NewStartup(
+	dcpConfig *config.Dcp,
+	checkpointMetadata metadata.Metadata,
+	kafkaClient gKafka.Client,
+	topics []string,
+) (*Startup, error) {
+	client := dcpCouchbase.NewClient(dcpConfig)
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	if err := client.DcpConnect(); err != nil {
+		return nil, err
+	}
+	defer client.DcpClose()
+
+	snapshot, err := client.GetConfigSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	highSeqNos, err := client.GetVBucketSeqNos()
+	if err != nil {
+		return nil, err
+	}
+
+	vbIDs := make([]uint16, 0, client.GetNumVBuckets())
+	for vbID := 0; vbID < client.GetNumVBuckets(); vbID++ {
+		vbIDs = append(vbIDs, uint16(vbID))
+	}
+
+	if checkpointMetadata == nil {
+		checkpointMetadata = defaultMetadata(dcpConfig, client)
+	}
+
+	checkpoints, exist, err := checkpointMetadata.Load(vbIDs, snapshot.BucketUUID())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Startup{
+		CreatedAt:        time.Now(),
+		CheckpointExists: exist,
+	}
+
+	ages := checkpointAges(dcpConfig, client, vbIDs)
+	latest := !exist && dcpConfig.Checkpoint.AutoReset == StartPositionLatest
+
+	for _, vbID := range vbIDs {
+		var checkpointSeqNo uint64
+		if doc, ok := checkpoints.Load(vbID); ok && doc.Checkpoint != nil {
+			checkpointSeqNo = doc.Checkpoint.SeqNo
+		}
+
+		vBucket := newVBucket(vbID, highSeqNos[vbID], checkpointSeqNo, latest)
+		vBucket.CheckpointAge = ages[vbID]
+
+		report.ExpectedReplay += vBucket.ExpectedReplay
+		report.VBuckets = append(report.VBuckets, vBucket)
+	}
+
+	report.Topics, err = topicReports(kafkaClient, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func defaultMetadata(dcpConfig *config.Dcp, client dcpCouchbase.Client) metadata.Metadata {
+	if dcpConfig.IsCouchbaseMetadata() {
+		return dcpCouchbase.NewCBMetadata(client, dcpConfig)
+	}
+	return metadata.NewFSMetadata(dcpConfig)
+}
+
+func newVBucket(vbID uint16, highSeqNo uint64, checkpointSeqNo uint64, latest bool) VBucket {
+	vBucket := VBucket{
+		VbID:            vbID,
+		HighSeqNo:       highSeqNo,
+		CheckpointSeqNo: checkpointSeqNo,
+		StartPosition:   StartPositionCheckpoint,
+	}
+
+	switch {
+	case latest:
+		vBucket.StartPosition = StartPositionLatest
+	case checkpointSeqNo == 0:
+		vBucket.StartPosition = StartPositionBeginning
+		vBucket.ExpectedReplay = highSeqNo
+	case highSeqNo > checkpointSeqNo:
+		vBucket.ExpectedReplay = highSeqNo - checkpointSeqNo
+	}
+	return vBucket
+}
+
+func topicReports(kafkaClient gKafka.Client, topics []string) ([]Topic, error) {
+	sort.Strings(topics)
+
+	reports := make([]Topic, 0, len(topics))
+	for _, topic := range topics {
+		partitions, err := kafkaClient.GetPartitions(topic)
+		if err != nil {
+			return nil, err
+		}
+
+		offsets, err := kafkaClient.GetEndOffsets(topic, partitions)
+		if err != nil {
+			return nil, err
+		}
+
+		report := Topic{Topic: topic, EndOffsets: make(map[int]int64, len(offsets))}
+		for _, offset := range offsets {
+			report.EndOffsets[offset.Partition] = offset.LastOffset
+			report.TotalEndOffset += offset.LastOffset
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// checkpointAges uses the CAS of the Couchbase checkpoint documents or the modification time of the metadata file.
+func checkpointAges(dcpConfig *config.Dcp, client dcpCouchbase.Client, vbIDs []uint16) map[uint16]string {
+	ages := make(map[uint16]string, len(vbIDs))
+
+	if dcpConfig.IsFileMetadata() {
+		if info, err := os.Stat(dcpConfig.GetFileMetadata()); err == nil {
+			age := time.Since(info.ModTime()).Round(time.Second).String()
+			for _, vbID := range vbIDs {
+				ages[vbID] = age
+			}
+		}
+		return ages
+	}
+
+	if !dcpConfig.IsCouchbaseMetadata() {
+		return ages
+	}
+
+	couchbaseMetadata := dcpConfig.GetCouchbaseMetadata()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(vbIDs))
+
+	for _, vbID := range vbIDs {
+		go func(vbID uint16) {
+			defer wg.Done()
+
+			cas, err := getCas(
+				client.GetMetaAgent(), couchbaseMetadata.Scope, couchbaseMetadata.Collection,
+				[]byte(helpers.Prefix+dcpConfig.Dcp.Group.Name+":checkpoint:"+strconv.Itoa(int(vbID))),
+			)
+			if err != nil || cas == 0 {
+				return
+			}
+
+			lock.Lock()
+			ages[vbID] = time.Since(time.Unix(0, int64(cas))).Round(time.Second).String()
+			lock.Unlock()
+		}(vbID)
+	}
+
+	wg.Wait()
+	return ages
+}
+
+func getCas(agent *gocbcore.Agent, scopeName string, collectionName string, id []byte) (gocbcore.Cas, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opm := dcpCouchbase.NewAsyncOp(ctx)
+	deadline, _ := ctx.Deadline()
+
+	ch := make(chan struct {
+		err error
+		cas gocbcore.Cas
+	}, 1)
+
+	op, err := agent.Get(gocbcore.GetOptions{
+		Key:            id,
+		Deadline:       deadline,
+		ScopeName:      scopeName,
+		CollectionName: collectionName,
+	}, func(result *gocbcore.GetResult, err error) {
+		opm.Resolve()
+
+		var cas gocbcore.Cas
+		if err == nil {
+			cas = result.Cas
+		}
+		ch <- struct {
+			err error
+			cas gocbcore.Cas
+		}{err: err, cas: cas}
+	})
+
+	if err = opm.Wait(op, err); err != nil {
+		return 0, err
+	}
+
+	result := <-ch
+	return result.cas, result.err
+}
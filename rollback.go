@@ -0,0 +1,93 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	ControlHeader        = "dcp-kafka-control"
+	ControlTypeRollback  = "rollback"
+	maxVBuckets          = 1024
+	rollbackMarkerPrefix = "vb-"
+)
+
+// RollbackEvent covers the seqnos of a vbucket that were produced before a rollback and may be replayed or lost,
+// FromSeqNo is the first seqno streamed again and ToSeqNo the last one produced before the rollback.
+type RollbackEvent struct {
+	DetectedAt time.Time `json:"detectedAt"`
+	Type       string    `json:"type"`
+	FromSeqNo  uint64    `json:"fromSeqNo"`
+	ToSeqNo    uint64    `json:"toSeqNo"`
+	VbID       uint16    `json:"vbId"`
+}
+
+// rollbackDetector notices rollbacks from a vbucket seqno going backwards, since go-dcp rolls back streams internally.
+// It is reset when the streams stop, so vbuckets resuming from their checkpoint after a rebalance are not reported.
+type rollbackDetector struct {
+	callback  func(event RollbackEvent)
+	topic     string
+	lastSeqNo [maxVBuckets]uint64
+}
+
+func (d *rollbackDetector) observe(vbID uint16, seqNo uint64) (RollbackEvent, bool) {
+	last := atomic.SwapUint64(&d.lastSeqNo[vbID], seqNo)
+	if last == 0 || seqNo > last {
+		return RollbackEvent{}, false
+	}
+
+	return RollbackEvent{
+		DetectedAt: time.Now(),
+		Type:       ControlTypeRollback,
+		FromSeqNo:  seqNo,
+		ToSeqNo:    last,
+		VbID:       vbID,
+	}, true
+}
+
+func (d *rollbackDetector) reset() {
+	for i := range d.lastSeqNo {
+		atomic.StoreUint64(&d.lastSeqNo[i], 0)
+	}
+}
+
+// detectRollback produces the rollback marker ahead of the event, the marker has no ack of its own.
+func (c *connector) detectRollback(e *couchbase.Event) {
+	event, ok := c.rollback.observe(e.VbID, e.SeqNo)
+	if !ok {
+		return
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().RollbacksDetected, 1)
+	logger.Log.Warn("rollback detected, vbID: %d, seqNo range: %d-%d", event.VbID, event.FromSeqNo, event.ToSeqNo)
+
+	if c.rollback.callback != nil {
+		c.rollback.callback(event)
+	}
+
+	if c.rollback.topic == "" {
+		return
+	}
+
+	value, err := jsoniter.Marshal(event)
+	if err != nil {
+		panic(fmt.Errorf("rollback marker error, vbID: %d, err: %w", event.VbID, err))
+	}
+
+	marker := sKafka.Message{
+		Topic:   c.rollback.topic,
+		Key:     []byte(rollbackMarkerPrefix + strconv.Itoa(int(event.VbID))),
+		Value:   value,
+		Headers: []sKafka.Header{{Key: ControlHeader, Value: []byte(ControlTypeRollback)}},
+	}
+	c.sink.Produce(&models.ListenerContext{Ack: func() {}}, event.DetectedAt, []sKafka.Message{marker})
+}
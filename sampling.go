@@ -0,0 +1,32 @@
+package dcpkafka
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// skipUnsampled acks the mutations left out by kafka.sampling. Keys are sampled by their hash, so every mutation of a
+// sampled key is produced and its history stays complete.
+func (c *connector) skipUnsampled(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	sampling := &c.config.Kafka.Sampling
+	if !sampling.Enabled || !e.IsMutated {
+		return false
+	}
+
+	if sampling.KeyOneIn > 0 {
+		if xxhash.Sum64(e.Key)%uint64(sampling.KeyOneIn) == 0 {
+			return false
+		}
+	} else if rand.Float64()*100 < sampling.Percentage { //nolint:gosec
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().SampledOutEvents, 1)
+	ctx.Ack()
+	return true
+}
@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	FormatJSONSchema = "jsonschema"
+	FormatAvro       = "avro"
+)
+
+var invalidAvroName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Export returns the schema of the collection in the given format.
+func (i *Inferrer) Export(collection string, format string) ([]byte, error) {
+	root := i.root(collection)
+	if root == nil {
+		return nil, fmt.Errorf("no documents observed for collection: %s", collection)
+	}
+
+	var schema any
+	switch format {
+	case FormatJSONSchema:
+		document := jsonSchema(root)
+		document["$schema"] = "http://json-schema.org/draft-07/schema#"
+		document["title"] = collection
+		schema = document
+	case FormatAvro:
+		name := avroName(collection)
+		if !root.types[TypeObject] || len(root.typeNames()) > 1 {
+			return nil, fmt.Errorf("avro export requires object documents, collection: %s", collection)
+		}
+		schema = avroRecord(root, name, name)
+	default:
+		return nil, fmt.Errorf("invalid schema format: %s", format)
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func jsonSchema(n *node) map[string]any {
+	schema := map[string]any{}
+
+	types := n.typeNames()
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+
+	if n.types[TypeArray] && n.items != nil && len(n.items.types) > 0 {
+		schema["items"] = jsonSchema(n.items)
+	}
+
+	if n.types[TypeObject] {
+		properties := map[string]any{}
+		var required []string
+		for _, name := range n.propertyNames() {
+			properties[name] = jsonSchema(n.properties[name])
+			if n.isRequired(name) {
+				required = append(required, name)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	return schema
+}
+
+func avroRecord(n *node, name string, namespace string) map[string]any {
+	fields := make([]map[string]any, 0, len(n.properties))
+
+	for _, fieldName := range n.propertyNames() {
+		property := n.properties[fieldName]
+		field := map[string]any{
+			"name": avroName(fieldName),
+			"type": avroType(property, name+"_"+avroName(fieldName), namespace),
+		}
+
+		if !n.isRequired(fieldName) {
+			field["type"] = nullable(field["type"])
+			field["default"] = nil
+		}
+
+		fields = append(fields, field)
+	}
+
+	return map[string]any{
+		"type":      "record",
+		"name":      name,
+		"namespace": namespace,
+		"fields":    fields,
+	}
+}
+
+// avroType returns a union for mixed types, every record gets a unique name derived from its path.
+func avroType(n *node, name string, namespace string) any {
+	var union []any
+	for _, t := range n.typeNames() {
+		switch t {
+		case TypeNull:
+			union = append(union, "null")
+		case TypeBoolean:
+			union = append(union, "boolean")
+		case TypeInteger:
+			union = append(union, "long")
+		case TypeNumber:
+			union = append(union, "double")
+		case TypeString:
+			union = append(union, "string")
+		case TypeArray:
+			items := any("null")
+			if n.items != nil && len(n.items.types) > 0 {
+				items = avroType(n.items, name+"_item", namespace)
+			}
+			union = append(union, map[string]any{"type": "array", "items": items})
+		case TypeObject:
+			union = append(union, avroRecord(n, name, namespace))
+		}
+	}
+
+	switch len(union) {
+	case 0:
+		return "null"
+	case 1:
+		return union[0]
+	default:
+		return union
+	}
+}
+
+// nullable puts null first in the union, as required for a null default.
+func nullable(t any) any {
+	union, ok := t.([]any)
+	if !ok {
+		if t == "null" {
+			return t
+		}
+		return []any{"null", t}
+	}
+
+	result := []any{"null"}
+	for _, member := range union {
+		if member != "null" {
+			result = append(result, member)
+		}
+	}
+	return result
+}
+
+func avroName(name string) string {
+	name = invalidAvroName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+func bytesReader(value []byte) io.Reader {
+	return bytes.NewReader(bytes.TrimSpace(value))
+}
+
+// FileName returns the export file name of the collection.
+func FileName(collection string, format string) string {
+	extension := ".schema.json"
+	if format == FormatAvro {
+		extension = ".avsc"
+	}
+	return strings.ReplaceAll(collection, "/", "_") + extension
+}
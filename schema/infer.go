@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// node is the observed shape of a value. A field is required when it was present and not null in every observed object.
+type node struct {
+	types      map[string]bool
+	properties map[string]*node
+	presence   map[string]int
+	items      *node
+	objects    int
+}
+
+func newNode() *node {
+	return &node{types: map[string]bool{}}
+}
+
+// Inferrer builds field schemas per collection from observed JSON documents. It is safe for concurrent use.
+type Inferrer struct {
+	collections map[string]*node
+	samples     map[string]int
+	maxSamples  int
+	lock        sync.Mutex
+}
+
+// NewInferrer observes at most maxSamples documents per collection, 0 means unlimited.
+func NewInferrer(maxSamples int) *Inferrer {
+	return &Inferrer{
+		collections: map[string]*node{},
+		samples:     map[string]int{},
+		maxSamples:  maxSamples,
+	}
+}
+
+// Observe merges the document into the schema of the collection. Values that are not JSON are ignored.
+func (i *Inferrer) Observe(collection string, value []byte) {
+	var document any
+	decoder := json.NewDecoder(bytesReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.maxSamples > 0 && i.samples[collection] >= i.maxSamples {
+		return
+	}
+	i.samples[collection]++
+
+	root, ok := i.collections[collection]
+	if !ok {
+		root = newNode()
+		i.collections[collection] = root
+	}
+	root.observe(document)
+}
+
+// Collections returns the observed collections with their sample counts.
+func (i *Inferrer) Collections() map[string]int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	collections := make(map[string]int, len(i.samples))
+	for collection, samples := range i.samples {
+		collections[collection] = samples
+	}
+	return collections
+}
+
+func (i *Inferrer) root(collection string) *node {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	return i.collections[collection]
+}
+
+func (n *node) observe(value any) {
+	switch v := value.(type) {
+	case nil:
+		n.types[TypeNull] = true
+	case bool:
+		n.types[TypeBoolean] = true
+	case string:
+		n.types[TypeString] = true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			n.types[TypeInteger] = true
+		} else {
+			n.types[TypeNumber] = true
+		}
+	case []any:
+		n.types[TypeArray] = true
+		if n.items == nil {
+			n.items = newNode()
+		}
+		for _, item := range v {
+			n.items.observe(item)
+		}
+	case map[string]any:
+		n.types[TypeObject] = true
+		if n.properties == nil {
+			n.properties = map[string]*node{}
+			n.presence = map[string]int{}
+		}
+		n.objects++
+		for name, field := range v {
+			property, ok := n.properties[name]
+			if !ok {
+				property = newNode()
+				n.properties[name] = property
+			}
+			property.observe(field)
+			if field != nil {
+				n.presence[name]++
+			}
+		}
+	}
+}
+
+// typeNames returns the observed types in a stable order, integer is folded into number when both were seen.
+func (n *node) typeNames() []string {
+	var names []string
+	for _, name := range []string{TypeNull, TypeBoolean, TypeInteger, TypeNumber, TypeString, TypeArray, TypeObject} {
+		if !n.types[name] || (name == TypeInteger && n.types[TypeNumber]) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (n *node) propertyNames() []string {
+	names := make([]string, 0, len(n.properties))
+	for name := range n.properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (n *node) isRequired(name string) bool {
+	return n.presence[name] == n.objects
+}
@@ -0,0 +1,99 @@
+package dcpkafka
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	"github.com/Trendyol/go-dcp-kafka/schemaregistry"
+	"github.com/Trendyol/go-dcp/logger"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+const SchemaFallbackHeader = "dcp-kafka-schema-fallback"
+
+func newSchemaSerializer(schemaRegistry *config.SchemaRegistry) (*schemaregistry.Serializer, error) {
+	client, err := schemaregistry.NewClient(schemaRegistry.URL, schemaregistry.Options{
+		Username:    schemaRegistry.Username,
+		Password:    schemaRegistry.Password,
+		BearerToken: schemaRegistry.BearerToken,
+		CAPath:      schemaRegistry.CAPath,
+		Timeout:     schemaRegistry.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var compatibility *schemaregistry.Compatibility
+	if len(schemaRegistry.Schemas) > 0 {
+		compatibility = schemaregistry.NewCompatibility(client, schemaRegistry.Schemas, schemaRegistry.SchemaType)
+	}
+	serializer := schemaregistry.NewSerializer(client, schemaRegistry.CacheTTL, compatibility)
+
+	// only an incompatible schema refuses to start, an unavailable registry is handled by the fallback
+	var incompatible *schemaregistry.IncompatibleError
+	if err = serializer.CheckCompatibility(); errors.As(err, &incompatible) {
+		return nil, err
+	} else if err != nil {
+		logger.Log.Error("schema compatibility check error: %v", err)
+	}
+	return serializer, nil
+}
+
+// serialize frames message values with their registry schema id, applying the configured fallback
+// while the registry is unavailable. The pause fallback blocks the listener until the registry answers again, it
+// returns false when the connector is closed meanwhile and the event is left unacknowledged.
+func (c *connector) serialize(messages []sKafka.Message) bool {
+	fallback := c.config.Kafka.GetSchemaRegistryFallback()
+
+	for i := range messages {
+		if messages[i].Value == nil {
+			continue
+		}
+
+		for attempt := 0; ; attempt++ {
+			framed, stale, err := c.serializer.Serialize(
+				messages[i].Topic, messages[i].Value, fallback == config.SchemaRegistryFallbackCache,
+			)
+			if err == nil {
+				if stale || attempt > 0 {
+					atomic.AddInt64(&c.producer.GetMetric().SchemaRegistryFallbacks, 1)
+				}
+				messages[i].Value = framed
+				break
+			}
+
+			// a changed subject the local schema is incompatible with fails every message, whatever the fallback
+			var incompatible *schemaregistry.IncompatibleError
+			var unsupported *schemaregistry.UnsupportedSchemaTypeError
+			if errors.As(err, &incompatible) || errors.As(err, &unsupported) {
+				panic(err)
+			}
+
+			switch fallback {
+			case config.SchemaRegistryFallbackRawJSON:
+				atomic.AddInt64(&c.producer.GetMetric().SchemaRegistryFallbacks, 1)
+				messages[i].Headers = append(messages[i].Headers, sKafka.Header{
+					Key:   SchemaFallbackHeader,
+					Value: []byte(config.SchemaRegistryFallbackRawJSON),
+				})
+			case config.SchemaRegistryFallbackPause:
+				logging.Error([]logging.Field{logging.Topic(messages[i].Topic), logging.Err(err)},
+					"schema registry error, retrying in %v", c.config.Kafka.SchemaRegistry.RetryInterval)
+				select {
+				case <-time.After(c.config.Kafka.SchemaRegistry.RetryInterval):
+				case <-c.closing:
+					return false
+				}
+				continue
+			default:
+				panic(fmt.Errorf("schema registry error, topic: %s, err: %w", messages[i].Topic, err))
+			}
+			break
+		}
+	}
+	return true
+}
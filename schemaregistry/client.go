@@ -0,0 +1,167 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Schema is a registered schema, SchemaType is empty for AVRO schemas.
+type Schema struct {
+	Subject    string `json:"subject"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+	ID         int    `json:"id"`
+	Version    int    `json:"version"`
+}
+
+type Client interface {
+	GetLatestSchema(subject string) (*Schema, error)
+	// TestCompatibility checks a schema against the latest version of the subject, a missing subject is compatible.
+	TestCompatibility(subject string, schema string, schemaType string) (compatible bool, messages []string, err error)
+}
+
+// Options configure the registry connection separately from the kafka transport,
+// e.g. for a registry behind a different proxy.
+type Options struct {
+	Username    string
+	Password    string
+	BearerToken string
+	// CAPath is a pem bundle trusted in addition to the system pool.
+	CAPath  string
+	Timeout time.Duration
+}
+
+type client struct {
+	httpClient  *http.Client
+	url         string
+	username    string
+	password    string
+	bearerToken string
+}
+
+func NewClient(registryURL string, options Options) (Client, error) {
+	httpClient := &http.Client{Timeout: options.Timeout}
+
+	if options.CAPath != "" {
+		bundle, err := os.ReadFile(os.ExpandEnv(options.CAPath))
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CAPath)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+		httpClient.Transport = transport
+	}
+
+	return &client{
+		httpClient:  httpClient,
+		url:         strings.TrimSuffix(registryURL, "/"),
+		username:    options.Username,
+		password:    options.Password,
+		bearerToken: options.BearerToken,
+	}, nil
+}
+
+func (c *client) GetLatestSchema(subject string) (*Schema, error) {
+	req, err := c.newRequest(http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry returned %d for subject %s", res.StatusCode, subject)
+	}
+
+	var schema Schema
+	if err := jsoniter.NewDecoder(res.Body).Decode(&schema); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+type compatibilityRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type compatibilityResponse struct {
+	Messages     []string `json:"messages"`
+	IsCompatible bool     `json:"is_compatible"`
+}
+
+func (c *client) TestCompatibility(subject string, schema string, schemaType string) (bool, []string, error) {
+	body, err := jsoniter.Marshal(compatibilityRequest{Schema: schema, SchemaType: schemaType})
+	if err != nil {
+		return false, nil, err
+	}
+
+	req, err := c.newRequest(
+		http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest?verbose=true", bytes.NewReader(body),
+	)
+	if err != nil {
+		return false, nil, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return true, nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return false, nil, fmt.Errorf("schema registry returned %d for the compatibility of subject %s", res.StatusCode, subject)
+	}
+
+	var compatibility compatibilityResponse
+	if err := jsoniter.NewDecoder(res.Body).Decode(&compatibility); err != nil {
+		return false, nil, err
+	}
+	return compatibility.IsCompatible, compatibility.Messages, nil
+}
+
+func (c *client) newRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
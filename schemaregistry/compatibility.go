@@ -0,0 +1,70 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// IncompatibleError is returned when a local schema is incompatible with the latest version of its subject.
+type IncompatibleError struct {
+	Subject  string
+	Messages []string
+}
+
+func (e *IncompatibleError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("local schema is incompatible with subject %s", e.Subject)
+	}
+	return fmt.Sprintf("local schema is incompatible with subject %s: %s", e.Subject, strings.Join(e.Messages, "; "))
+}
+
+// Compatibility checks the local schema files of the topics against the latest versions of their `<topic>-value`
+// subjects. The files are read on every check, so an edited schema is checked as it is.
+type Compatibility struct {
+	client     Client
+	schemas    map[string]string
+	schemaType string
+}
+
+func NewCompatibility(client Client, topicSchemas map[string]string, schemaType string) *Compatibility {
+	schemas := make(map[string]string, len(topicSchemas))
+	for topic, path := range topicSchemas {
+		schemas[topic+"-value"] = path
+	}
+	return &Compatibility{client: client, schemas: schemas, schemaType: schemaType}
+}
+
+// Check returns an *IncompatibleError when the local schema of the subject is incompatible, subjects without a local
+// schema are not checked.
+func (c *Compatibility) Check(subject string) error {
+	path, ok := c.schemas[subject]
+	if !ok {
+		return nil
+	}
+
+	schema, err := os.ReadFile(os.ExpandEnv(path))
+	if err != nil {
+		return err
+	}
+
+	compatible, messages, err := c.client.TestCompatibility(subject, string(schema), c.schemaType)
+	if err != nil {
+		return err
+	}
+	if !compatible {
+		return &IncompatibleError{Subject: subject, Messages: messages}
+	}
+	return nil
+}
+
+// Subjects returns the subjects with a local schema.
+func (c *Compatibility) Subjects() []string {
+	subjects := make([]string, 0, len(c.schemas))
+	for subject := range c.schemas {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
@@ -0,0 +1,136 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	magicByte      = 0
+	schemaTypeJSON = "JSON"
+	schemaTypeAvro = "AVRO"
+)
+
+// UnsupportedSchemaTypeError is returned for a subject whose latest schema is not a JSON schema. The values are framed
+// as they are, so an AVRO or PROTOBUF schema id would frame records no consumer can decode.
+type UnsupportedSchemaTypeError struct {
+	Subject    string
+	SchemaType string
+}
+
+func (e *UnsupportedSchemaTypeError) Error() string {
+	return fmt.Sprintf("schema type %s of subject %s is not supported, only JSON schemas are", e.SchemaType, e.Subject)
+}
+
+type cachedSchema struct {
+	fetchedAt time.Time
+	id        int
+}
+
+// Serializer frames JSON values in the Confluent wire format with the latest schema id of the `<topic>-value` subject.
+// Schema ids are cached for the given ttl, expired entries are refreshed from the registry. With a compatibility,
+// the local schema is checked again whenever the latest schema id of its subject changes.
+type Serializer struct {
+	client        Client
+	compatibility *Compatibility
+	cache         map[string]cachedSchema
+	checked       map[string]int
+	ttl           time.Duration
+	lock          sync.RWMutex
+}
+
+// NewSerializer does not check the compatibility, which may be nil.
+func NewSerializer(client Client, ttl time.Duration, compatibility *Compatibility) *Serializer {
+	return &Serializer{
+		client:        client,
+		compatibility: compatibility,
+		cache:         map[string]cachedSchema{},
+		checked:       map[string]int{},
+		ttl:           ttl,
+	}
+}
+
+// Serialize returns the framed value. When allowStale is set and the registry cannot be reached,
+// an expired schema id is used and stale is returned as true.
+func (s *Serializer) Serialize(topic string, value []byte, allowStale bool) (framed []byte, stale bool, err error) {
+	subject := topic + "-value"
+
+	s.lock.RLock()
+	cached, ok := s.cache[subject]
+	s.lock.RUnlock()
+
+	if !ok || time.Since(cached.fetchedAt) >= s.ttl {
+		schema, err := s.client.GetLatestSchema(subject)
+		if err != nil {
+			if !ok || !allowStale {
+				return nil, false, err
+			}
+			return frame(cached.id, value), true, nil
+		}
+
+		if schema.SchemaType != schemaTypeJSON {
+			schemaType := schema.SchemaType
+			if schemaType == "" {
+				schemaType = schemaTypeAvro
+			}
+			return nil, false, &UnsupportedSchemaTypeError{Subject: subject, SchemaType: schemaType}
+		}
+
+		if err = s.check(subject, schema.ID); err != nil {
+			return nil, false, err
+		}
+
+		cached = cachedSchema{id: schema.ID, fetchedAt: time.Now()}
+		s.lock.Lock()
+		s.cache[subject] = cached
+		s.lock.Unlock()
+	}
+
+	return frame(cached.id, value), false, nil
+}
+
+// check tests the local schema of the subject once per schema id.
+func (s *Serializer) check(subject string, id int) error {
+	if s.compatibility == nil {
+		return nil
+	}
+
+	s.lock.RLock()
+	checkedID, ok := s.checked[subject]
+	s.lock.RUnlock()
+	if ok && checkedID == id {
+		return nil
+	}
+
+	if err := s.compatibility.Check(subject); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	s.checked[subject] = id
+	s.lock.Unlock()
+	return nil
+}
+
+// CheckCompatibility checks every local schema against the latest schema of its subject, e.g. on startup.
+func (s *Serializer) CheckCompatibility() error {
+	if s.compatibility == nil {
+		return nil
+	}
+
+	for _, subject := range s.compatibility.Subjects() {
+		if err := s.compatibility.Check(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func frame(id int, value []byte) []byte {
+	framed := make([]byte, 5+len(value))
+	framed[0] = magicByte
+	binary.BigEndian.PutUint32(framed[1:5], uint32(id))
+	copy(framed[5:], value)
+	return framed
+}
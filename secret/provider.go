@@ -0,0 +1,43 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ProviderTypeFile  = "file"
+	ProviderTypeVault = "vault"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+// Provider resolves credentials, e.g. scram passwords, so they never live in config files.
+// Get is called again on rotation, so an implementation must return the current value of the secret.
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+type fileProvider struct {
+	directory string
+}
+
+// NewFileProvider reads every secret from the file of its name under directory, e.g. a mounted kubernetes secret.
+func NewFileProvider(directory string) Provider {
+	return &fileProvider{directory: directory}
+}
+
+func (p *fileProvider) Get(_ context.Context, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(p.directory, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+type vaultProvider struct {
+	client  *http.Client
+	address string
+	token   string
+	path    string
+}
+
+type vaultResponse struct {
+	Data map[string]any `json:"data"`
+}
+
+// NewVaultProvider reads every secret as a key of the vault secret at path, e.g. secret/data/kafka.
+// Both the kv v1 and the kv v2 secrets engines are supported.
+func NewVaultProvider(address, token, path string, timeout time.Duration) Provider {
+	return &vaultProvider{
+		client:  &http.Client{Timeout: timeout},
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		path:    strings.Trim(path, "/"),
+	}
+}
+
+func (p *vaultProvider) Get(ctx context.Context, name string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+p.path, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", p.token)
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, p.path)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", response.StatusCode, p.path)
+	}
+
+	var body vaultResponse
+	if err = jsoniter.NewDecoder(response.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	data := body.Data
+	// kv v2 nests the secret under data.data
+	if nested, ok := data["data"].(map[string]any); ok {
+		data = nested
+	}
+
+	value, ok := data[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", ErrNotFound, name, p.path)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s is not a string", name)
+	}
+	return text, nil
+}
@@ -0,0 +1,28 @@
+package dcpkafka
+
+import (
+	"fmt"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/secret"
+)
+
+// newSecretProvider returns nil when no provider is configured, then the credentials of the config file are used.
+func newSecretProvider(secretsConfig *config.Secrets, provider secret.Provider) (secret.Provider, error) {
+	if provider != nil {
+		return provider, nil
+	}
+
+	switch secretsConfig.Type {
+	case "":
+		return nil, nil
+	case secret.ProviderTypeFile:
+		return secret.NewFileProvider(secretsConfig.Directory), nil
+	case secret.ProviderTypeVault:
+		return secret.NewVaultProvider(
+			secretsConfig.VaultAddress, secretsConfig.VaultToken, secretsConfig.VaultPath, secretsConfig.Timeout,
+		), nil
+	default:
+		return nil, fmt.Errorf("invalid secret provider type: %s", secretsConfig.Type)
+	}
+}
@@ -0,0 +1,206 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/state"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+type seqNoPosition struct {
+	VbUUID uint64 `json:"vbUUID"`
+	SeqNo  uint64 `json:"seqNo"`
+}
+
+// seqNoDedup records the last written seqno of every vbucket and skips the events up to it when they are streamed
+// again, e.g. after a crash-restart resumed from an older checkpoint. A vbucket is skipped only while its vbuuid
+// matches the recorded one, so events of a new history after a failover are produced.
+type seqNoDedup struct {
+	store    state.Store
+	produced map[uint16]seqNoPosition
+	skipped  map[uint16]seqNoPosition
+	loaded   map[uint16]bool
+	dirty    map[uint16]bool
+	stop     chan struct{}
+	interval time.Duration
+	lock     sync.Mutex
+	saveLock sync.Mutex
+}
+
+func newSeqNoDedup(store state.Store, interval time.Duration) *seqNoDedup {
+	return &seqNoDedup{
+		store:    store,
+		produced: map[uint16]seqNoPosition{},
+		skipped:  map[uint16]seqNoPosition{},
+		loaded:   map[uint16]bool{},
+		dirty:    map[uint16]bool{},
+		stop:     make(chan struct{}),
+		interval: interval,
+	}
+}
+
+// vbuckets are saved one by one, so the state of a vbucket moving to another member is not overwritten.
+func seqNoStateName(vbID uint16) string {
+	return fmt.Sprintf("seqno-%d.json", vbID)
+}
+
+// skip reports whether the event was produced before, the first event streamed past the recorded seqno ends the
+// skipping of its vbucket.
+func (d *seqNoDedup) skip(e *couchbase.Event) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.loaded[e.VbID] {
+		d.loaded[e.VbID] = true
+		if position, ok := d.restore(e.VbID); ok {
+			d.skipped[e.VbID] = position
+		}
+	}
+
+	position, ok := d.skipped[e.VbID]
+	if !ok {
+		return false
+	}
+	if position.VbUUID != e.VbUUID || e.SeqNo > position.SeqNo {
+		delete(d.skipped, e.VbID)
+		return false
+	}
+	return true
+}
+
+// restore runs under the lock, a position recorded since the start is newer than the saved one.
+func (d *seqNoDedup) restore(vbID uint16) (seqNoPosition, bool) {
+	if position, ok := d.produced[vbID]; ok {
+		return position, true
+	}
+
+	data, err := d.store.Load(seqNoStateName(vbID))
+	if err != nil || data == nil {
+		if err != nil {
+			logger.Log.Error("seqno dedup state error, vbID: %d, err: %v", vbID, err)
+		}
+		return seqNoPosition{}, false
+	}
+
+	var position seqNoPosition
+	if err = jsoniter.Unmarshal(data, &position); err != nil {
+		logger.Log.Error("seqno dedup state error, vbID: %d, err: %v", vbID, err)
+		return seqNoPosition{}, false
+	}
+	return position, true
+}
+
+func (d *seqNoDedup) record(vbID uint16, position seqNoPosition) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if current, ok := d.produced[vbID]; ok && current.VbUUID == position.VbUUID && current.SeqNo >= position.SeqNo {
+		return
+	}
+	d.produced[vbID] = position
+	d.dirty[vbID] = true
+}
+
+// reset is called when the streams stop, the vbuckets streamed again resume from their checkpoints.
+func (d *seqNoDedup) reset() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.skipped = map[uint16]seqNoPosition{}
+	d.loaded = map[uint16]bool{}
+}
+
+func (d *seqNoDedup) start() {
+	ticker := time.NewTicker(d.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.save()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (d *seqNoDedup) save() {
+	d.saveLock.Lock()
+	defer d.saveLock.Unlock()
+
+	d.lock.Lock()
+	dirty := make(map[uint16]seqNoPosition, len(d.dirty))
+	for vbID := range d.dirty {
+		dirty[vbID] = d.produced[vbID]
+	}
+	d.dirty = map[uint16]bool{}
+	d.lock.Unlock()
+
+	for vbID, position := range dirty {
+		data, err := jsoniter.Marshal(position)
+		if err == nil {
+			err = d.store.Save(seqNoStateName(vbID), data)
+		}
+		if err != nil {
+			logger.Log.Error("seqno dedup save error, vbID: %d, err: %v", vbID, err)
+			d.lock.Lock()
+			d.dirty[vbID] = true
+			d.lock.Unlock()
+		}
+	}
+}
+
+// close saves the positions recorded since the last save, it runs after the drain released the last acks.
+func (d *seqNoDedup) close() {
+	close(d.stop)
+	d.save()
+}
+
+// trackWritten records the position of the event when the listener ack runs, which is deferred until the messages
+// are written in flush ack mode.
+func (c *connector) trackWritten(ctx *models.ListenerContext) {
+	var vbID uint16
+	var position seqNoPosition
+	switch event := ctx.Event.(type) {
+	case models.DcpMutation:
+		vbID, position = event.VbID, seqNoPosition{VbUUID: offsetVbUUID(event.Offset), SeqNo: event.SeqNo}
+	case models.DcpDeletion:
+		vbID, position = event.VbID, seqNoPosition{VbUUID: offsetVbUUID(event.Offset), SeqNo: event.SeqNo}
+	case models.DcpExpiration:
+		vbID, position = event.VbID, seqNoPosition{VbUUID: offsetVbUUID(event.Offset), SeqNo: event.SeqNo}
+	default:
+		return
+	}
+
+	ack := ctx.Ack
+	ctx.Ack = func() {
+		ack()
+		c.seqNoDedup.record(vbID, position)
+	}
+}
+
+func offsetVbUUID(offset *models.Offset) uint64 {
+	if offset == nil {
+		return 0
+	}
+	return uint64(offset.VbUUID)
+}
+
+// skipProducedSeqNo acks the events written before the restart by kafka.seqNoDedup.
+func (c *connector) skipProducedSeqNo(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.seqNoDedup == nil || !c.seqNoDedup.skip(e) {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().SeqNoDedupSkipped, 1)
+	ctx.Ack()
+	return true
+}
@@ -0,0 +1,54 @@
+package dcpkafka
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/report"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// drain flushes the remaining messages and closes the writers. A fatal produce error panics
+// during the final flush, it is recovered here so the shutdown report still gets written.
+func (c *connector) drain() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("final flush failed: %v", r)
+		}
+	}()
+	return c.producer.Close()
+}
+
+func (c *connector) ShutdownReport() *report.Shutdown {
+	return c.shutdownReport
+}
+
+// writeShutdownReport logs the report and writes it to kafka.shutdownReportPath, e.g. /dev/termination-log on kubernetes.
+func (c *connector) writeShutdownReport(started time.Time, err error) {
+	metric := c.producer.GetMetric()
+	c.shutdownReport = report.NewShutdown(
+		started,
+		atomic.LoadInt64(&metric.ProducedMessages),
+		atomic.LoadInt64(&metric.CheckpointCommits),
+		c.producer.ProducerBatch.Pending(),
+		err,
+	)
+
+	data, marshalErr := jsoniter.Marshal(c.shutdownReport)
+	if marshalErr != nil {
+		logger.Log.Error("shutdown report error: %v", marshalErr)
+		return
+	}
+
+	logger.Log.Info("shutdown report: %s", data)
+
+	if path := c.config.Kafka.ShutdownReportPath; path != "" {
+		if writeErr := os.WriteFile(path, data, 0o600); writeErr != nil {
+			logger.Log.Error("shutdown report cannot be written to %s, err: %v", path, writeErr)
+		}
+	}
+}
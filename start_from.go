@@ -0,0 +1,68 @@
+package dcpkafka
+
+import (
+	"sync/atomic"
+	"time"
+
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	dcpMetadata "github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/wrapper"
+
+	"github.com/Trendyol/go-dcp-kafka/checkpoint"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+)
+
+// startFromMetadata overrides the checkpoints of the first load only, the loads after a rebalance resume from the
+// checkpoints saved since.
+type startFromMetadata struct {
+	dcpMetadata.Metadata
+	client    dcpCouchbase.Client
+	timestamp time.Time
+	seqNos    map[uint16]uint64
+	loaded    atomic.Bool
+}
+
+func (m *startFromMetadata) Load(
+	vbIDs []uint16,
+	bucketUUID string,
+) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) {
+	state, exist, err := m.Metadata.Load(vbIDs, bucketUUID)
+	if err != nil || !m.loaded.CompareAndSwap(false, true) {
+		return state, exist, err
+	}
+
+	overridden := 0
+	for _, vbID := range vbIDs {
+		var seqNo uint64
+		if m.timestamp.IsZero() {
+			var ok bool
+			if seqNo, ok = m.seqNos[vbID]; !ok {
+				continue
+			}
+		}
+
+		at, err := checkpoint.At(m.client, vbID, seqNo)
+		if err != nil {
+			return nil, false, err
+		}
+		state.Store(vbID, &models.CheckpointDocument{Checkpoint: at, BucketUUID: bucketUUID})
+		overridden++
+	}
+
+	logger.Log.Info("kafka.startFrom overrode the checkpoints of %d vBuckets", overridden)
+	return state, true, nil
+}
+
+// skipBeforeStartFrom acks events older than kafka.startFrom.timestamp, the cas is the hybrid logical clock of the
+// mutation in nanoseconds.
+func (c *connector) skipBeforeStartFrom(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.startFrom == nil || c.startFrom.timestamp.IsZero() || e.Cas >= uint64(c.startFrom.timestamp.UnixNano()) {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+	ctx.Ack()
+	return true
+}
@@ -0,0 +1,60 @@
+package dcpkafka
+
+import (
+	"errors"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/api"
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+	"github.com/Trendyol/go-dcp-kafka/kafka/metadata"
+	"github.com/Trendyol/go-dcp-kafka/report"
+	dcpConfig "github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	dcpMetadata "github.com/Trendyol/go-dcp/metadata"
+)
+
+// newStartupReport never fails the startup, the report is informational only.
+func newStartupReport(c *config.Connector, conf *dcpConfig.Dcp, kafkaClient kafka.Client) *report.Startup {
+	var checkpointMetadata dcpMetadata.Metadata
+	if conf.Metadata.Type == MetadataTypeKafka {
+		checkpointMetadata = metadata.NewKafkaMetadata(kafkaClient, conf.Metadata.Config)
+	}
+
+	topics := make([]string, 0, len(c.Kafka.CollectionTopicMapping))
+	for _, topic := range c.Kafka.CollectionTopicMapping {
+		topics = append(topics, topic)
+	}
+
+	startupReport, err := report.NewStartup(conf, checkpointMetadata, kafkaClient, topics)
+	if err != nil {
+		logger.Log.Error("startup report error: %v", err)
+		return nil
+	}
+
+	data, err := jsoniter.Marshal(startupReport)
+	if err != nil {
+		logger.Log.Error("startup report error: %v", err)
+		return startupReport
+	}
+
+	logger.Log.Info("startup report, checkpoint exists: %v, expected replay: %v, report: %s",
+		startupReport.CheckpointExists, startupReport.ExpectedReplay, data)
+	return startupReport
+}
+
+func (c *connector) startupReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if c.startupReport == nil {
+		api.WriteError(w, http.StatusNotFound, errors.New("startup report is not available"))
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, c.startupReport)
+}
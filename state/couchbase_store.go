@@ -0,0 +1,50 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+)
+
+const StoreTypeCouchbase = "couchbase"
+
+type couchbaseStore struct {
+	agent          *gocbcore.Agent
+	scopeName      string
+	collectionName string
+	keyPrefix      string
+	timeout        time.Duration
+}
+
+func (s *couchbaseStore) Load(name string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	data, err := dcpCouchbase.Get(ctx, s.agent, s.scopeName, s.collectionName, []byte(s.keyPrefix+name))
+	if errors.Is(err, gocbcore.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *couchbaseStore) Save(name string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return dcpCouchbase.CreateDocument(ctx, s.agent, s.scopeName, s.collectionName, []byte(s.keyPrefix+name), data, 0, 0)
+}
+
+// NewCouchbaseStore keeps every state as a document named keyPrefix + name, e.g. next to the checkpoints in the metadata collection.
+func NewCouchbaseStore(agent *gocbcore.Agent, scopeName string, collectionName string, keyPrefix string, timeout time.Duration) Store {
+	return &couchbaseStore{
+		agent:          agent,
+		scopeName:      scopeName,
+		collectionName: collectionName,
+		keyPrefix:      keyPrefix,
+		timeout:        timeout,
+	}
+}
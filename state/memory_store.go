@@ -0,0 +1,25 @@
+package state
+
+import "sync"
+
+type memoryStore struct {
+	data map[string][]byte
+	lock sync.RWMutex
+}
+
+func (s *memoryStore) Load(name string) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.data[name], nil
+}
+
+func (s *memoryStore) Save(name string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data[name] = data
+	return nil
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{data: map[string][]byte{}}
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const (
+	StoreTypeFile   = "file"
+	StoreTypeMemory = "memory"
+)
+
+// Store keeps small pieces of connector state, such as pause or toggle states, across restarts.
+type Store interface {
+	// Load returns nil without an error when there is no saved state for the name.
+	Load(name string) ([]byte, error)
+	Save(name string, data []byte) error
+}
+
+type fileStore struct {
+	directory string
+}
+
+func (s *fileStore) Load(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.directory, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *fileStore) Save(name string, data []byte) error {
+	if err := os.MkdirAll(s.directory, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.directory, name)
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func NewFileStore(directory string) Store {
+	return &fileStore{directory: directory}
+}
@@ -0,0 +1,101 @@
+package toggle
+
+import (
+	"sort"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/Trendyol/go-dcp-kafka/state"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+const stateName = "collections.json"
+
+type State struct {
+	Disabled []string `json:"disabled"`
+}
+
+// Collections keeps the collections whose events are acknowledged without producing, persisted in the state store.
+type Collections struct {
+	store    state.Store
+	disabled map[string]struct{}
+	lock     sync.RWMutex
+}
+
+func NewCollections(store state.Store) (*Collections, error) {
+	c := &Collections{
+		store:    store,
+		disabled: map[string]struct{}{},
+	}
+
+	data, err := store.Load(stateName)
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		var s State
+		if err := jsoniter.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		for _, name := range s.Disabled {
+			c.disabled[name] = struct{}{}
+		}
+		logger.Log.Info("restored disabled collections: %v", s.Disabled)
+	}
+
+	return c, nil
+}
+
+func (c *Collections) IsDisabled(name string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, ok := c.disabled[name]
+	return ok
+}
+
+func (c *Collections) Disable(names []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, name := range names {
+		c.disabled[name] = struct{}{}
+	}
+	return c.save()
+}
+
+func (c *Collections) Enable(names []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, name := range names {
+		delete(c.disabled, name)
+	}
+	return c.save()
+}
+
+func (c *Collections) State() State {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return State{Disabled: c.names()}
+}
+
+func (c *Collections) save() error {
+	data, err := jsoniter.Marshal(State{Disabled: c.names()})
+	if err != nil {
+		return err
+	}
+	return c.store.Save(stateName, data)
+}
+
+func (c *Collections) names() []string {
+	names := make([]string, 0, len(c.disabled))
+	for name := range c.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
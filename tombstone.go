@@ -0,0 +1,226 @@
+package dcpkafka
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+	sKafka "github.com/segmentio/kafka-go"
+)
+
+// produceTombstone produces a null value record keyed by the document id, so compacted topics drop the document.
+func (c *connector) produceTombstone(ctx *models.ListenerContext, e *couchbase.Event) {
+	c.sink.Produce(ctx, e.EventTime, []sKafka.Message{c.tombstone(e)})
+}
+
+func (c *connector) tombstone(e *couchbase.Event) sKafka.Message {
+	tombstone := sKafka.Message{
+		Topic: c.getTopicName(e.CollectionName, ""),
+		Key:   e.Key,
+		Time:  c.messageTime(e),
+	}
+
+	if c.keyOf != nil {
+		tombstone.Key = c.keyOf(e)
+	}
+
+	if c.config.Kafka.DcpMetadataHeaders {
+		tombstone.Headers = newDcpMetadataHeaders(e)
+	}
+	return tombstone
+}
+
+type delayedTombstone struct {
+	dueAt      time.Time
+	eventTime  time.Time
+	message    sKafka.Message
+	generation int
+	vbID       uint16
+	written    bool
+}
+
+// heldAck is an ack held behind the tombstones of its vBucket, or one of the tombstones when tombstone is set.
+type heldAck struct {
+	ack       func()
+	tombstone *delayedTombstone
+}
+
+// tombstoneDelayer produces the tombstones kafka.tombstoneDelay after the delete markers mapped from their events.
+// The delay is the same for every tombstone, so they are due in the order they are scheduled. The ack of the deleted
+// event and of every later event of its vBucket are held until the tombstone is written, so the checkpoint does not
+// pass a tombstone only kept in memory and a restart or a rebalance streams the deletion again. The tombstones still
+// pending on Close are produced at once.
+type tombstoneDelayer struct {
+	produce    func(eventTime time.Time, message sKafka.Message, written func())
+	wake       chan struct{}
+	closed     chan struct{}
+	stopped    chan struct{}
+	held       map[uint16][]heldAck
+	pending    []*delayedTombstone
+	delay      time.Duration
+	generation int
+	lock       sync.Mutex
+}
+
+func newTombstoneDelayer(
+	delay time.Duration,
+	produce func(eventTime time.Time, message sKafka.Message, written func()),
+) *tombstoneDelayer {
+	d := &tombstoneDelayer{
+		produce: produce,
+		wake:    make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+		stopped: make(chan struct{}),
+		held:    map[uint16][]heldAck{},
+		delay:   delay,
+	}
+	go d.run()
+	return d
+}
+
+// holdAck wraps the listener ack, so it runs only after the tombstones scheduled before it in its vBucket are written.
+// It wraps the ack before DeferAck, whose release runs under the flush lock like the tombstone writes.
+func (c *connector) holdAck(ctx *models.ListenerContext) {
+	var vbID uint16
+	switch event := ctx.Event.(type) {
+	case models.DcpMutation:
+		vbID = event.VbID
+	case models.DcpDeletion:
+		vbID = event.VbID
+	case models.DcpExpiration:
+		vbID = event.VbID
+	default:
+		return
+	}
+
+	ack := ctx.Ack
+	ctx.Ack = func() {
+		c.tombstones.ack(vbID, ack)
+	}
+}
+
+func (d *tombstoneDelayer) ack(vbID uint16, ack func()) {
+	d.lock.Lock()
+	if len(d.held[vbID]) > 0 {
+		d.held[vbID] = append(d.held[vbID], heldAck{ack: ack})
+		d.lock.Unlock()
+		return
+	}
+	d.lock.Unlock()
+	ack()
+}
+
+func (d *tombstoneDelayer) schedule(e *couchbase.Event, message sKafka.Message) {
+	d.lock.Lock()
+	tombstone := &delayedTombstone{
+		dueAt:      time.Now().Add(d.delay),
+		eventTime:  e.EventTime,
+		message:    message,
+		generation: d.generation,
+		vbID:       e.VbID,
+	}
+	d.pending = append(d.pending, tombstone)
+	d.held[e.VbID] = append(d.held[e.VbID], heldAck{tombstone: tombstone})
+	d.lock.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// written releases the acks held behind the tombstone up to the next tombstone not written yet of the vBucket.
+func (d *tombstoneDelayer) written(tombstone *delayedTombstone) {
+	d.lock.Lock()
+	if tombstone.generation != d.generation {
+		d.lock.Unlock()
+		return
+	}
+
+	tombstone.written = true
+	held := d.held[tombstone.vbID]
+	var acks []func()
+	for len(held) > 0 && (held[0].tombstone == nil || held[0].tombstone.written) {
+		if held[0].ack != nil {
+			acks = append(acks, held[0].ack)
+		}
+		held = held[1:]
+	}
+	if len(held) == 0 {
+		delete(d.held, tombstone.vbID)
+	} else {
+		d.held[tombstone.vbID] = held
+	}
+	d.lock.Unlock()
+
+	for _, ack := range acks {
+		ack()
+	}
+}
+
+// reset drops the pending tombstones and the held acks when the streams stop, their events are streamed again from
+// the checkpoint. Tombstones already taken by run are written without releasing anything.
+func (d *tombstoneDelayer) reset() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.generation++
+	d.pending = nil
+	d.held = map[uint16][]heldAck{}
+}
+
+func (d *tombstoneDelayer) run() {
+	defer close(d.stopped)
+	for {
+		due, next := d.takeDue(time.Now())
+		d.produceAll(due)
+
+		var timeout <-chan time.Time
+		if next > 0 {
+			timeout = time.After(next)
+		}
+		select {
+		case <-timeout:
+		case <-d.wake:
+		case <-d.closed:
+			due, _ = d.takeDue(time.Time{})
+			d.produceAll(due)
+			return
+		}
+	}
+}
+
+func (d *tombstoneDelayer) produceAll(tombstones []*delayedTombstone) {
+	for _, tombstone := range tombstones {
+		tombstone := tombstone
+		d.produce(tombstone.eventTime, tombstone.message, func() { d.written(tombstone) })
+	}
+}
+
+// takeDue removes the tombstones due at now, every one when now is zero, and returns them with the wait for the next.
+func (d *tombstoneDelayer) takeDue(now time.Time) ([]*delayedTombstone, time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	i := len(d.pending)
+	if !now.IsZero() {
+		i = 0
+		for i < len(d.pending) && !d.pending[i].dueAt.After(now) {
+			i++
+		}
+	}
+	due := append([]*delayedTombstone(nil), d.pending[:i]...)
+	d.pending = d.pending[i:]
+
+	if len(d.pending) == 0 {
+		return due, 0
+	}
+	return due, max(time.Until(d.pending[0].dueAt), time.Nanosecond)
+}
+
+// Close produces the pending tombstones, the connector calls it after the stream is closed and before the final flush.
+func (d *tombstoneDelayer) Close() {
+	close(d.closed)
+	<-d.stopped
+}
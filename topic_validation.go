@@ -0,0 +1,53 @@
+package dcpkafka
+
+import (
+	"errors"
+	"fmt"
+
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/kafka"
+)
+
+// validateTopics checks the configured topics exist, have kafka.keyStrategy.minPartitions partitions, are writable
+// and compacted for kafka.compactedTopics, and reports every invalid topic at once. Missing topics are allowed when
+// the brokers create them.
+func validateTopics(kafkaClient kafka.Client, k *config.Kafka, allowMissing bool) error {
+	topics := configuredTopics(k)
+	if len(topics) == 0 {
+		return nil
+	}
+
+	descriptions, err := kafkaClient.DescribeTopics(topics)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, topic := range descriptions {
+		switch {
+		case errors.Is(topic.Err, sKafka.UnknownTopicOrPartition):
+			if !allowMissing {
+				errs = append(errs, fmt.Errorf("topic=%s is missing", topic.Name))
+			}
+		case topic.Err != nil:
+			errs = append(errs, fmt.Errorf("topic=%s, err=%v", topic.Name, topic.Err))
+		case !topic.Writable:
+			errs = append(errs, fmt.Errorf("topic=%s is not writable by the principal", topic.Name))
+		case k.KeyStrategy.Type != config.KeyStrategyNone && topic.Partitions < k.KeyStrategy.MinPartitions:
+			errs = append(errs, fmt.Errorf(
+				"topic=%s has %d partitions, kafka.keyStrategy.minPartitions is %d", topic.Name, topic.Partitions, k.KeyStrategy.MinPartitions,
+			))
+		}
+		if topic.Err == nil && k.CompactedTopics {
+			if err = kafkaClient.CheckTopicIsCompacted(topic.Name); err != nil {
+				errs = append(errs, fmt.Errorf("topic=%s, err=%v", topic.Name, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid topics:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package transform
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	EncryptModeRandomized    = "randomized"
+	EncryptModeDeterministic = "deterministic"
+)
+
+// fieldCipher encrypts JSON encoded values with AES-256-GCM into base64 strings of the nonce and the ciphertext.
+// The deterministic nonce is an HMAC of the value, so equal values have equal ciphertexts and can still be joined.
+type fieldCipher struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// newFieldCipher takes a base64 encoded 32 byte key, environment variables in it are expanded.
+func newFieldCipher(key string) (*fieldCipher, error) {
+	decoded, err := base64.StdEncoding.DecodeString(os.ExpandEnv(key))
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %w", err)
+	}
+	if len(decoded) != 32 {
+		return nil, errors.New("key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(decoded)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, decoded)
+	mac.Write([]byte("dcp-kafka-field-nonce"))
+	return &fieldCipher{aead: aead, nonceKey: mac.Sum(nil)}, nil
+}
+
+func (c *fieldCipher) encrypt(plaintext []byte, deterministic bool) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if deterministic {
+		mac := hmac.New(sha256.New, c.nonceKey)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// FieldDecryptor restores the fields encrypted by an encryptFields transform, for consumers.
+type FieldDecryptor struct {
+	cipher *fieldCipher
+}
+
+// NewFieldDecryptor takes the key of the transform.
+func NewFieldDecryptor(key string) (*FieldDecryptor, error) {
+	fieldCipher, err := newFieldCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &FieldDecryptor{cipher: fieldCipher}, nil
+}
+
+// Decrypt returns the JSON encoded value of an encrypted field.
+func (d *FieldDecryptor) Decrypt(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := d.cipher.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("encrypted field is shorter than the nonce")
+	}
+	return d.cipher.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+}
+
+// encryptFields replaces the values at the given paths with their ciphertext, the values are JSON encoded first so
+// their types are restored on decryption. Arrays on the path are descended into, null values are kept.
+type encryptFields struct {
+	cipher        *fieldCipher
+	paths         [][]string
+	deterministic bool
+}
+
+func newEncryptFields(fields []string, mode string, key string) (*encryptFields, error) {
+	if len(fields) == 0 || key == "" {
+		return nil, fmt.Errorf("fields and key must be set for %s transform", TypeEncryptFields)
+	}
+
+	t := &encryptFields{}
+	switch mode {
+	case "", EncryptModeRandomized:
+	case EncryptModeDeterministic:
+		t.deterministic = true
+	default:
+		return nil, fmt.Errorf("invalid %s transform mode: %s", TypeEncryptFields, mode)
+	}
+
+	var err error
+	if t.cipher, err = newFieldCipher(key); err != nil {
+		return nil, fmt.Errorf("%s transform: %w", TypeEncryptFields, err)
+	}
+	for _, field := range fields {
+		t.paths = append(t.paths, splitPath(field))
+	}
+	return t, nil
+}
+
+func (t *encryptFields) Apply(message *kafka.Message) error {
+	var err error
+	updateErr := updateDocument(message, func(document map[string]any) {
+		for _, path := range t.paths {
+			if err == nil {
+				err = t.encrypt(document, path)
+			}
+		}
+	})
+	if updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+func (t *encryptFields) encrypt(value any, path []string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		field, ok := v[path[0]]
+		if !ok || (len(path) == 1 && field == nil) {
+			return nil
+		}
+		if len(path) > 1 {
+			return t.encrypt(field, path[1:])
+		}
+
+		plaintext, err := jsoniter.Marshal(field)
+		if err != nil {
+			return err
+		}
+		v[path[0]], err = t.cipher.encrypt(plaintext, t.deterministic)
+		return err
+	case []any:
+		for _, element := range v {
+			if err := t.encrypt(element, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
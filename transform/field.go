@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"bytes"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+)
+
+// renameField renames the last element of path within its parent object.
+type renameField struct {
+	to   string
+	path []string
+}
+
+func (t *renameField) Apply(message *kafka.Message) error {
+	return updateDocument(message, func(document map[string]any) {
+		parent, name, ok := lookupParent(document, t.path)
+		if !ok {
+			return
+		}
+		if value, exists := parent[name]; exists {
+			delete(parent, name)
+			parent[t.to] = value
+		}
+	})
+}
+
+// maskField replaces the value at path with the replacement, null when it is not set.
+type maskField struct {
+	replacement any
+	path        []string
+}
+
+func (t *maskField) Apply(message *kafka.Message) error {
+	return updateDocument(message, func(document map[string]any) {
+		parent, name, ok := lookupParent(document, t.path)
+		if !ok {
+			return
+		}
+		if _, exists := parent[name]; exists {
+			parent[name] = t.replacement
+		}
+	})
+}
+
+// updateDocument decodes the value as a JSON object, applies update and encodes it again.
+// Values that are not JSON objects, such as tombstones or binary documents, are left untouched.
+func updateDocument(message *kafka.Message, update func(document map[string]any)) error {
+	trimmed := bytes.TrimSpace(message.Value)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil
+	}
+
+	decoder := jsoniter.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+
+	update(document)
+
+	value, err := jsoniter.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	message.Value = value
+	return nil
+}
+
+func lookupParent(document map[string]any, path []string) (map[string]any, string, bool) {
+	parent := document
+	for _, name := range path[:len(path)-1] {
+		child, ok := parent[name].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+		parent = child
+	}
+	return parent, path[len(path)-1], true
+}
+
+func splitPath(field string) []string {
+	return strings.Split(field, ".")
+}
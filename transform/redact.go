@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	RedactModeRedact = "redact"
+	RedactModeHash   = "hash"
+
+	defaultRedactReplacement = "[REDACTED]"
+)
+
+// redactFields replaces the values at the given paths with a fixed replacement or with their hex SHA-256 hash.
+// Arrays on the path are descended into, so `addresses.street` covers every address.
+type redactFields struct {
+	newHash     func() hash.Hash
+	replacement string
+	paths       [][]string
+	hash        bool
+}
+
+func newRedactFields(fields []string, mode string, replacement string, salt string) (*redactFields, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must be set for %s transform", TypeRedactFields)
+	}
+
+	t := &redactFields{replacement: replacement, newHash: sha256.New}
+	if t.replacement == "" {
+		t.replacement = defaultRedactReplacement
+	}
+
+	switch mode {
+	case "", RedactModeRedact:
+	case RedactModeHash:
+		t.hash = true
+		if salt != "" {
+			t.newHash = func() hash.Hash {
+				return hmac.New(sha256.New, []byte(salt))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s transform mode: %s", TypeRedactFields, mode)
+	}
+
+	for _, field := range fields {
+		t.paths = append(t.paths, splitPath(field))
+	}
+
+	return t, nil
+}
+
+func (t *redactFields) Apply(message *kafka.Message) error {
+	return updateDocument(message, func(document map[string]any) {
+		for _, path := range t.paths {
+			t.redact(document, path)
+		}
+	})
+}
+
+func (t *redactFields) redact(value any, path []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		field, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = t.replace(field)
+			return
+		}
+		t.redact(field, path[1:])
+	case []any:
+		for _, element := range v {
+			t.redact(element, path)
+		}
+	}
+}
+
+// replace keeps null values, a hashed null would look like real data.
+func (t *redactFields) replace(value any) any {
+	if value == nil {
+		return nil
+	}
+	if !t.hash {
+		return t.replacement
+	}
+
+	var data []byte
+	if s, ok := value.(string); ok {
+		data = []byte(s)
+	} else {
+		data, _ = jsoniter.Marshal(value)
+	}
+
+	h := t.newHash()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,142 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+const (
+	TypeAddHeader     = "addHeader"
+	TypeRenameField   = "renameField"
+	TypeMaskField     = "maskField"
+	TypeRouteTopic    = "routeTopic"
+	TypeRedactFields  = "redactFields"
+	TypeEncryptFields = "encryptFields"
+)
+
+// Transform changes a single message after the mapper, before it is added to the batch.
+type Transform interface {
+	Apply(message *kafka.Message) error
+}
+
+// Chain applies transforms in order.
+type Chain []Transform
+
+func (c Chain) Apply(message *kafka.Message) error {
+	for _, t := range c {
+		if err := t.Apply(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyCollection applies the transforms in order, skipping the ones configured for other collections.
+func (c Chain) ApplyCollection(collection string, message *kafka.Message) error {
+	for _, t := range c {
+		if scoped, ok := t.(*collectionScoped); ok {
+			if _, in := scoped.collections[collection]; !in {
+				continue
+			}
+		}
+		if err := t.Apply(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewChain(transformConfigs []config.Transform) (Chain, error) {
+	chain := make(Chain, 0, len(transformConfigs))
+
+	for _, transformConfig := range transformConfigs {
+		t, err := newTransform(transformConfig)
+		if err != nil {
+			return nil, err
+		}
+		if len(transformConfig.Collections) > 0 {
+			t = newCollectionScoped(t, transformConfig.Collections)
+		}
+		chain = append(chain, t)
+	}
+
+	return chain, nil
+}
+
+// collectionScoped is a transform applied by ApplyCollection to the messages of the given collections only.
+type collectionScoped struct {
+	Transform
+	collections map[string]struct{}
+}
+
+func newCollectionScoped(t Transform, collections []string) *collectionScoped {
+	scoped := &collectionScoped{Transform: t, collections: make(map[string]struct{}, len(collections))}
+	for _, collection := range collections {
+		scoped.collections[collection] = struct{}{}
+	}
+	return scoped
+}
+
+func newTransform(transformConfig config.Transform) (Transform, error) {
+	switch transformConfig.Type {
+	case TypeAddHeader:
+		if transformConfig.Header == "" {
+			return nil, fmt.Errorf("header must be set for %s transform", TypeAddHeader)
+		}
+		return &addHeader{header: kafka.Header{Key: transformConfig.Header, Value: []byte(transformConfig.Value)}}, nil
+	case TypeRenameField:
+		if transformConfig.Field == "" || transformConfig.To == "" {
+			return nil, fmt.Errorf("field and to must be set for %s transform", TypeRenameField)
+		}
+		return &renameField{path: splitPath(transformConfig.Field), to: transformConfig.To}, nil
+	case TypeMaskField:
+		if transformConfig.Field == "" {
+			return nil, fmt.Errorf("field must be set for %s transform", TypeMaskField)
+		}
+		m := &maskField{path: splitPath(transformConfig.Field)}
+		if transformConfig.Value != "" {
+			m.replacement = transformConfig.Value
+		}
+		return m, nil
+	case TypeRouteTopic:
+		regex, err := regexp.Compile("^(?:" + transformConfig.Regex + ")$")
+		if err != nil {
+			return nil, err
+		}
+		return &routeTopic{regex: regex, replacement: transformConfig.Replacement}, nil
+	case TypeRedactFields:
+		return newRedactFields(transformConfig.Fields, transformConfig.Mode, transformConfig.Value, transformConfig.Salt)
+	case TypeEncryptFields:
+		return newEncryptFields(transformConfig.Fields, transformConfig.Mode, transformConfig.Key)
+	default:
+		return nil, fmt.Errorf("invalid transform type: %s", transformConfig.Type)
+	}
+}
+
+type addHeader struct {
+	header kafka.Header
+}
+
+func (t *addHeader) Apply(message *kafka.Message) error {
+	headers := make([]kafka.Header, 0, len(message.Headers)+1)
+	message.Headers = append(append(headers, message.Headers...), t.header)
+	return nil
+}
+
+// routeTopic replaces the topic like the Kafka Connect RegexRouter, the regex is anchored to match the whole topic and
+// topics not matching it are kept.
+type routeTopic struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+func (t *routeTopic) Apply(message *kafka.Message) error {
+	if t.regex.MatchString(message.Topic) {
+		message.Topic = t.regex.ReplaceAllString(message.Topic, t.replacement)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package dcpkafka
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+)
+
+// ContentEncodingHeader names the codec of a value compressed by kafka.valueCompression, e.g. gzip.
+const ContentEncodingHeader = "content-encoding"
+
+func newValueCodec(valueCompression *config.ValueCompression) sKafka.CompressionCodec {
+	switch valueCompression.Codec {
+	case config.ValueCompressionZstd:
+		return sKafka.Zstd.Codec()
+	default:
+		return sKafka.Gzip.Codec()
+	}
+}
+
+// compressValues runs after the serializer, so consumers decompress the values before reading the schema id.
+func (c *connector) compressValues(messages []sKafka.Message) {
+	minSize := c.config.Kafka.ValueCompression.MinSize
+
+	for i := range messages {
+		if messages[i].Value == nil || len(messages[i].Value) < minSize {
+			continue
+		}
+
+		var compressed bytes.Buffer
+		writer := c.valueCodec.NewWriter(&compressed)
+		_, err := writer.Write(messages[i].Value)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			panic(fmt.Errorf("value compression error, key: %s, err: %w", messages[i].Key, err))
+		}
+
+		messages[i].Value = compressed.Bytes()
+		messages[i].Headers = append(messages[i].Headers, sKafka.Header{
+			Key:   ContentEncodingHeader,
+			Value: []byte(c.valueCodec.Name()),
+		})
+		atomic.AddInt64(&c.producer.GetMetric().CompressedValues, 1)
+	}
+}
@@ -0,0 +1,37 @@
+package dcpkafka
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// valueTemplate renders the value of every mapped message from the event like a header template, e.g. a fixed
+// envelope around the document. The json function encodes a value, e.g. {"data": {{ json .value }}}.
+type valueTemplate struct {
+	template *template.Template
+}
+
+func newValueTemplate(text string) (*valueTemplate, error) {
+	parsed, err := parseEventTemplate("valueTemplate", text, template.FuncMap{"json": templateJSON})
+	if err != nil {
+		return nil, err
+	}
+	return &valueTemplate{template: parsed}, nil
+}
+
+// render fails like a transform, since a value that does not follow the envelope would break the consumers.
+func (t *valueTemplate) render(data map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("value template error: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func templateJSON(v any) (string, error) {
+	data, err := jsoniter.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,96 @@
+package dcpkafka
+
+import (
+	"sync"
+	"sync/atomic"
+
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	dcpMetadata "github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/wrapper"
+
+	"github.com/Trendyol/go-dcp-kafka/checkpoint"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+)
+
+// vBucketSubsetMetadata streams the vBuckets outside kafka.vBuckets from their high seqno, since go-dcp opens a
+// stream for every vBucket of the member. Their checkpoints are saved as they were loaded, so they are not touched.
+type vBucketSubsetMetadata struct {
+	dcpMetadata.Metadata
+	client    dcpCouchbase.Client
+	vBuckets  map[uint16]struct{}
+	originals map[uint16]*models.CheckpointDocument
+	lock      sync.Mutex
+}
+
+func (m *vBucketSubsetMetadata) Load(
+	vbIDs []uint16,
+	bucketUUID string,
+) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) {
+	state, exist, err := m.Metadata.Load(vbIDs, bucketUUID)
+	if err != nil {
+		return state, exist, err
+	}
+
+	highSeqNos, err := m.client.GetVBucketSeqNos()
+	if err != nil {
+		return nil, false, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, vbID := range vbIDs {
+		if _, ok := m.vBuckets[vbID]; ok {
+			continue
+		}
+
+		original, _ := state.Load(vbID)
+		if original == nil {
+			original = models.NewEmptyCheckpointDocument(bucketUUID)
+		}
+		m.originals[vbID] = original
+
+		at, err := checkpoint.At(m.client, vbID, highSeqNos[vbID])
+		if err != nil {
+			return nil, false, err
+		}
+		state.Store(vbID, &models.CheckpointDocument{Checkpoint: at, BucketUUID: bucketUUID})
+	}
+	return state, true, nil
+}
+
+func (m *vBucketSubsetMetadata) Save(
+	state map[uint16]*models.CheckpointDocument,
+	dirtyOffsets map[uint16]bool,
+	bucketUUID string,
+) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	subsetState := make(map[uint16]*models.CheckpointDocument, len(state))
+	subsetDirtyOffsets := make(map[uint16]bool, len(dirtyOffsets))
+	for vbID, document := range state {
+		if _, ok := m.vBuckets[vbID]; ok {
+			subsetState[vbID] = document
+			subsetDirtyOffsets[vbID] = dirtyOffsets[vbID]
+		} else if original, ok := m.originals[vbID]; ok {
+			subsetState[vbID] = original
+		}
+	}
+	return m.Metadata.Save(subsetState, subsetDirtyOffsets, bucketUUID)
+}
+
+// skipOutsideVBuckets acks events of the vBuckets outside kafka.vBuckets and returns true for them.
+func (c *connector) skipOutsideVBuckets(ctx *models.ListenerContext, e *couchbase.Event) bool {
+	if c.vBuckets == nil {
+		return false
+	}
+	if _, ok := c.vBuckets[e.VbID]; ok {
+		return false
+	}
+
+	atomic.AddInt64(&c.producer.GetMetric().FilteredEvents, 1)
+	ctx.Ack()
+	return true
+}
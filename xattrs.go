@@ -0,0 +1,135 @@
+package dcpkafka
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	sKafka "github.com/segmentio/kafka-go"
+
+	"github.com/Trendyol/go-dcp-kafka/config"
+	"github.com/Trendyol/go-dcp-kafka/couchbase"
+	"github.com/Trendyol/go-dcp-kafka/logging"
+	dcpCouchbase "github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// HeaderXattrPrefix prefixes the names of the xattrs copied into headers.
+const HeaderXattrPrefix = "cb.xattr."
+
+// xattrReader reads the xattrs of mutations. go-dcp opens the stream without xattrs, so they are looked up from the
+// document unless the value carries them, and reflect the document at the lookup rather than at the mutation.
+type xattrReader struct {
+	agent   *gocbcore.Agent
+	names   []string
+	headers []string
+	timeout time.Duration
+}
+
+func newXattrReader(c *config.Connector) (*xattrReader, error) {
+	agent, err := dcpCouchbase.CreateAgent(
+		c.Dcp.Hosts, c.Dcp.BucketName, c.Dcp.Username, c.Dcp.Password, c.Dcp.SecureConnection, c.Dcp.RootCAPath,
+		uint(helpers.ResolveUnionIntOrStringValue(c.Dcp.ConnectionBufferSize)), c.Dcp.ConnectionTimeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xattrReader{agent: agent, names: c.Kafka.Xattrs.Names, headers: c.Kafka.Xattrs.Headers, timeout: c.Kafka.Xattrs.Timeout}, nil
+}
+
+// lookup returns the existing xattrs of the names, none when the document is gone.
+func (r *xattrReader) lookup(e *couchbase.Event) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	ops := make([]gocbcore.SubDocOp, len(r.names))
+	for i, name := range r.names {
+		ops[i] = gocbcore.SubDocOp{Op: memd.SubDocOpGet, Flags: memd.SubdocFlagXattrPath, Path: name}
+	}
+
+	opm := dcpCouchbase.NewAsyncOp(ctx)
+	resultCh := make(chan *gocbcore.LookupInResult, 1)
+	errorCh := make(chan error, 1)
+
+	op, err := r.agent.LookupIn(gocbcore.LookupInOptions{
+		Key:            e.Key,
+		Ops:            ops,
+		ScopeName:      e.ScopeName,
+		CollectionName: e.CollectionName,
+	}, func(result *gocbcore.LookupInResult, err error) {
+		opm.Resolve()
+		resultCh <- result
+		errorCh <- err
+	})
+	if err = opm.Wait(op, err); err != nil {
+		return nil, err
+	}
+
+	result, err := <-resultCh, <-errorCh
+	if errors.Is(err, gocbcore.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	xattrs := make(map[string][]byte, len(r.names))
+	for i, name := range r.names {
+		if result.Ops[i].Err == nil {
+			xattrs[name] = result.Ops[i].Value
+		} else if !errors.Is(result.Ops[i].Err, gocbcore.ErrPathNotFound) {
+			return nil, result.Ops[i].Err
+		}
+	}
+	return xattrs, nil
+}
+
+func (r *xattrReader) Close() {
+	if err := r.agent.Close(); err != nil {
+		logger.Log.Error("error while closing xattr agent: %v", err)
+	}
+}
+
+// readXattrs sets the xattrs of a mutation, the mutation is produced without them when they cannot be read and the
+// failure is counted in the metrics.
+func (c *connector) readXattrs(e *couchbase.Event) {
+	if e.Datatype&couchbase.DatatypeXattr != 0 {
+		xattrs, body, err := couchbase.SplitXattrs(e.Value)
+		if err != nil {
+			atomic.AddInt64(&c.producer.GetMetric().XattrErrors, 1)
+			logging.Error(append(eventFields(e), logging.Err(err)), "cannot read xattrs")
+			return
+		}
+		e.Value, e.Datatype = body, e.Datatype&^couchbase.DatatypeXattr
+		e.Xattrs = make(map[string][]byte, len(c.xattrs.names))
+		for _, name := range c.xattrs.names {
+			if value, ok := xattrs[name]; ok {
+				e.Xattrs[name] = value
+			}
+		}
+		return
+	}
+
+	xattrs, err := c.xattrs.lookup(e)
+	if err != nil {
+		atomic.AddInt64(&c.producer.GetMetric().XattrErrors, 1)
+		logging.Error(append(eventFields(e), logging.Err(err)), "cannot look up xattrs")
+		return
+	}
+	e.Xattrs = xattrs
+}
+
+func (c *connector) xattrHeaders(e *couchbase.Event) []sKafka.Header {
+	var headers []sKafka.Header
+	for _, name := range c.xattrs.headers {
+		if value, ok := e.Xattrs[name]; ok {
+			headers = append(headers, sKafka.Header{Key: HeaderXattrPrefix + name, Value: value})
+		}
+	}
+	return headers
+}